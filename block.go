@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"runtime"
+	"sync"
 
 	"github.com/gcash/bchd/chaincfg/chainhash"
 	"github.com/gcash/bchd/wire"
@@ -30,9 +32,11 @@ func (e OutOfRangeError) Error() string {
 // Block defines a bitcoin block that provides easier and more efficient
 // manipulation of raw blocks.  It also memoizes hashes for the block and its
 // transactions on their first access so subsequent accesses don't have to
-// repeat the relatively expensive hashing operations.
+// repeat the relatively expensive hashing operations.  The memoized values are
+// safe for concurrent access, so a Block may be shared between goroutines.
 type Block struct {
 	msgBlock        *wire.MsgBlock  // Underlying MsgBlock
+	mtx             RWMutex         // Protects the fields below
 	serializedBlock []byte          // Serialized bytes for the block
 	blockHash       *chainhash.Hash // Cached block hash
 	blockHeight     int32           // Height in the main block chain
@@ -51,8 +55,11 @@ func (b *Block) MsgBlock() *wire.MsgBlock {
 // result so subsequent calls are more efficient.
 func (b *Block) Bytes() ([]byte, error) {
 	// Return the cached serialized bytes if it has already been generated.
-	if len(b.serializedBlock) != 0 {
-		return b.serializedBlock, nil
+	b.mtx.RLock()
+	serializedBlock := b.serializedBlock
+	b.mtx.RUnlock()
+	if len(serializedBlock) != 0 {
+		return serializedBlock, nil
 	}
 
 	// Serialize the MsgBlock.
@@ -61,9 +68,16 @@ func (b *Block) Bytes() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	serializedBlock := w.Bytes()
+	serializedBlock = w.Bytes()
 
-	// Cache the serialized bytes and return them.
+	// Cache the serialized bytes and return them.  Another caller may have
+	// cached them in the meantime, in which case those are returned so all
+	// callers share the same bytes.
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if len(b.serializedBlock) != 0 {
+		return b.serializedBlock, nil
+	}
 	b.serializedBlock = serializedBlock
 	return serializedBlock, nil
 }
@@ -73,14 +87,21 @@ func (b *Block) Bytes() ([]byte, error) {
 // result so subsequent calls are more efficient.
 func (b *Block) Hash() *chainhash.Hash {
 	// Return the cached block hash if it has already been generated.
-	if b.blockHash != nil {
-		return b.blockHash
+	b.mtx.RLock()
+	blockHash := b.blockHash
+	b.mtx.RUnlock()
+	if blockHash != nil {
+		return blockHash
 	}
 
 	// Cache the block hash and return it.
 	hash := b.msgBlock.BlockHash()
-	b.blockHash = &hash
-	return &hash
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.blockHash == nil {
+		b.blockHash = &hash
+	}
+	return b.blockHash
 }
 
 // Tx returns a wrapped transaction (bchutil.Tx) for the transaction at the
@@ -98,6 +119,9 @@ func (b *Block) Tx(txNum int) (*Tx, error) {
 		return nil, OutOfRangeError(str)
 	}
 
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
 	// Generate slice to hold all of the wrapped transactions if needed.
 	if len(b.transactions) == 0 {
 		b.transactions = make([]*Tx, numTx)
@@ -123,9 +147,16 @@ func (b *Block) Transactions() []*Tx {
 	// Return transactions if they have ALL already been generated.  This
 	// flag is necessary because the wrapped transactions are lazily
 	// generated in a sparse fashion.
+	b.mtx.RLock()
 	if b.txnsGenerated {
-		return b.transactions
+		transactions := b.transactions
+		b.mtx.RUnlock()
+		return transactions
 	}
+	b.mtx.RUnlock()
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
 
 	// Generate slice to hold all of the wrapped transactions if needed.
 	if len(b.transactions) == 0 {
@@ -146,6 +177,41 @@ func (b *Block) Transactions() []*Tx {
 	return b.transactions
 }
 
+// HashTransactions computes the hashes of all transactions in the Block across
+// numWorkers goroutines and returns them in block order.  The hashes are cached
+// in the wrapped transactions, so later calls to TxHash or Tx.Hash return them
+// without hashing again.  When numWorkers is not positive, one worker per CPU
+// is used.
+//
+// Hashing transactions one at a time on first access is usually sufficient.
+// This is intended for large blocks whose transactions are all about to be
+// hashed, such as when indexing them or computing the merkle root.
+func (b *Block) HashTransactions(numWorkers int) []*chainhash.Hash {
+	transactions := b.Transactions()
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+	if numWorkers > len(transactions) {
+		numWorkers = len(transactions)
+	}
+
+	// Each worker hashes an interleaved share of the transactions and
+	// writes the results to distinct elements of the hashes slice.
+	hashes := make([]*chainhash.Hash, len(transactions))
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		go func(start int) {
+			defer wg.Done()
+			for i := start; i < len(transactions); i += numWorkers {
+				hashes[i] = transactions[i].Hash()
+			}
+		}(w)
+	}
+	wg.Wait()
+	return hashes
+}
+
 // TxHash returns the hash for the requested transaction number in the Block.
 // The supplied index is 0 based.  That is to say, the first transaction in the
 // block is txNum 0.  This is equivalent to calling TxHash on the underlying
@@ -186,12 +252,39 @@ func (b *Block) TxLoc() ([]wire.TxLoc, error) {
 // Height returns the saved height of the block in the block chain.  This value
 // will be BlockHeightUnknown if it hasn't already explicitly been set.
 func (b *Block) Height() int32 {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
 	return b.blockHeight
 }
 
 // SetHeight sets the height of the block in the block chain.
 func (b *Block) SetHeight(height int32) {
+	b.mtx.Lock()
 	b.blockHeight = height
+	b.mtx.Unlock()
+}
+
+// CoinbaseHeight returns the height of the block committed to by the start of
+// its coinbase signature script as required by BIP0034.  Only blocks of version
+// 2 or later commit to their height.  Unlike Height, this does not depend on
+// the height having been set, so a block deserialized from bytes can learn its
+// own height:
+//
+//	height, err := block.CoinbaseHeight()
+//	if err == nil {
+//		block.SetHeight(height)
+//	}
+func (b *Block) CoinbaseHeight() (int32, error) {
+	if b.msgBlock.Header.Version < serializedHeightVersion {
+		str := fmt.Sprintf("block version %d does not commit to its "+
+			"height", b.msgBlock.Header.Version)
+		return 0, blockError(ErrMissingCoinbaseHeight, str)
+	}
+	coinbase, err := b.coinbase()
+	if err != nil {
+		return 0, err
+	}
+	return ExtractCoinbaseHeight(coinbase)
 }
 
 // NewBlock returns a new instance of a bitcoin block given an underlying
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"io"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -550,3 +551,72 @@ var Block100000 = wire.MsgBlock{
 		},
 	},
 }
+
+// TestBlockConcurrency ensures the memoized values of a Block and its
+// transactions may be generated by concurrent readers.  It is most useful when
+// run with the race detector.
+func TestBlockConcurrency(t *testing.T) {
+	b := bchutil.NewBlock(&Block100000)
+	wantHash := Block100000.BlockHash()
+
+	const numReaders = 8
+	var wg sync.WaitGroup
+	wg.Add(numReaders)
+	for r := 0; r < numReaders; r++ {
+		go func(r int) {
+			defer wg.Done()
+			if hash := b.Hash(); !hash.IsEqual(&wantHash) {
+				t.Errorf("Hash: got %v, want %v", hash, wantHash)
+			}
+			if _, err := b.Bytes(); err != nil {
+				t.Errorf("Bytes: %v", err)
+			}
+			b.SetHeight(100000)
+			txNum := r % len(Block100000.Transactions)
+			if _, err := b.TxHash(txNum); err != nil {
+				t.Errorf("TxHash: %v", err)
+			}
+			for i, tx := range b.Transactions() {
+				wantTxHash := Block100000.Transactions[i].TxHash()
+				if !tx.Hash().IsEqual(&wantTxHash) {
+					t.Errorf("Transactions #%d: got hash %v, "+
+						"want %v", i, tx.Hash(), wantTxHash)
+				}
+			}
+			b.HashTransactions(2)
+		}(r)
+	}
+	wg.Wait()
+
+	if b.Height() != 100000 {
+		t.Errorf("Height: got %d, want %d", b.Height(), 100000)
+	}
+}
+
+// TestHashTransactions ensures the parallel transaction hashes match the
+// hashes of the individual transactions for any number of workers.
+func TestHashTransactions(t *testing.T) {
+	for _, numWorkers := range []int{-1, 0, 1, 3, 100} {
+		b := bchutil.NewBlock(&Block100000)
+		hashes := b.HashTransactions(numWorkers)
+		if len(hashes) != len(Block100000.Transactions) {
+			t.Errorf("HashTransactions(%d): got %d hashes, want %d",
+				numWorkers, len(hashes), len(Block100000.Transactions))
+			continue
+		}
+		for i, hash := range hashes {
+			want := Block100000.Transactions[i].TxHash()
+			if !hash.IsEqual(&want) {
+				t.Errorf("HashTransactions(%d) #%d: got %v, want %v",
+					numWorkers, i, hash, want)
+			}
+
+			// The hash must be cached in the wrapped transaction.
+			cached, _ := b.TxHash(i)
+			if cached != hash {
+				t.Errorf("HashTransactions(%d) #%d: hash not cached",
+					numWorkers, i)
+			}
+		}
+	}
+}
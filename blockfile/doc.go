@@ -0,0 +1,45 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package blockfile provides reading and writing of the raw blk*.dat block files
+written by full nodes.
+
+# Overview
+
+A block file is a sequence of records, each made up of a four byte network
+magic, a four byte little-endian length and the serialized block.  A Reader
+walks the records of every block file in a directory in order and yields each
+block as a bchutil.Block along with its position:
+
+	r, err := blockfile.NewReader(blocksDir, &chaincfg.MainNetParams)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for r.Next() {
+		fmt.Println(r.Pos(), r.Block().Hash())
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+Records may start with either the network magic of the chain parameters or,
+for networks where it differs, the magic full nodes write to block files.
+Zero padding between records is skipped and a record cut short by the end of
+its file ends that file, so the block files of a running node can be read.
+
+# Random Access
+
+ReadBlock reads a single block given its position, which is the file number and
+the offset of the serialized block within the file as recorded in a node's
+block index.
+
+# Writing
+
+A Writer appends blocks to new block files in the same format, starting a new
+file once the current one reaches a maximum size, which is useful for
+producing block file fixtures.
+*/
+package blockfile
@@ -0,0 +1,333 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+)
+
+// recordHeaderSize is the size of the network magic and length which precede
+// each block in a block file.
+const recordHeaderSize = 8
+
+var (
+	// ErrNoBlockFiles describes an error where a directory does not
+	// contain any block files.
+	ErrNoBlockFiles = errors.New("no block files found")
+
+	// ErrBadMagic describes an error where a block record does not start
+	// with the network magic expected for the network.
+	ErrBadMagic = errors.New("unexpected network magic")
+
+	// ErrBlockTooBig describes an error where the length of a block record
+	// exceeds the maximum size of a block message.
+	ErrBlockTooBig = errors.New("block record exceeds maximum block size")
+)
+
+// diskMagics maps the network magic of a network to the magic written to
+// block files by full nodes whose on-disk magic predates the network magic
+// they now use on the wire.
+var diskMagics = map[wire.BitcoinNet]wire.BitcoinNet{
+	wire.MainNet:  0xd9b4bef9,
+	wire.TestNet3: 0x0709110b,
+	wire.TestNet4: 0x92a722cd,
+	wire.TestNet:  0xdab5bffa,
+}
+
+// Pos is the position of a block within a set of block files.  Offset is the
+// offset of the serialized block, just past its network magic and length, in
+// the block file numbered File.  This matches the block positions recorded in
+// a node's block index.
+type Pos struct {
+	File   int
+	Offset int64
+}
+
+// String returns the position as the block file name followed by the offset.
+func (p Pos) String() string {
+	return fmt.Sprintf("%s:%d", FileName(p.File), p.Offset)
+}
+
+// FileName returns the name of the block file with the passed number.
+func FileName(num int) string {
+	return fmt.Sprintf("blk%05d.dat", num)
+}
+
+// Reader iterates the blocks stored in the blk*.dat files of a directory in
+// file and offset order.  Each call to Next reads the next block, which is then
+// available from Block along with its position from Pos.
+//
+// Runs of zero bytes between blocks, such as the preallocated space at the end
+// of a file, are skipped.  A block record cut short by the end of its file is
+// treated as the end of that file, so the files of a running node may be read.
+type Reader struct {
+	dir    string
+	magics []wire.BitcoinNet
+	files  []int
+
+	fileIdx int
+	file    *os.File
+	br      *bufio.Reader
+	offset  int64
+
+	block *bchutil.Block
+	pos   Pos
+	err   error
+}
+
+// NewReader returns a Reader over the block files of dir, which is usually the
+// blocks directory of a node's data directory.  Records must start with either
+// the network magic of params or, for networks where it differs, the magic
+// written to block files by full nodes.  Alternate on-disk magics are only
+// known for mainnet, testnet3, testnet4 and regtest; block files of other
+// networks, such as chipnet, are only accepted when written with the network
+// magic of params.
+func NewReader(dir string, params *chaincfg.Params) (*Reader, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "blk") ||
+			!strings.HasSuffix(name, ".dat") {
+
+			continue
+		}
+		num, err := strconv.Atoi(name[3 : len(name)-4])
+		if err != nil || num < 0 || FileName(num) != name {
+			continue
+		}
+		files = append(files, num)
+	}
+	if len(files) == 0 {
+		return nil, ErrNoBlockFiles
+	}
+	sort.Ints(files)
+
+	magics := []wire.BitcoinNet{params.Net}
+	if diskMagic, ok := diskMagics[params.Net]; ok {
+		magics = append(magics, diskMagic)
+	}
+	return &Reader{dir: dir, magics: magics, files: files}, nil
+}
+
+// Files returns the numbers of the block files read by the Reader in ascending
+// order.
+func (r *Reader) Files() []int {
+	return r.files
+}
+
+// Next advances the reader to the next block.  It returns false once every
+// block file has been read or when a block could not be read, in which case
+// Err returns the reason.
+func (r *Reader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	for {
+		if r.file == nil {
+			if r.fileIdx >= len(r.files) {
+				return false
+			}
+			if err := r.openFile(r.files[r.fileIdx]); err != nil {
+				r.err = err
+				return false
+			}
+		}
+
+		ok, err := r.readRecord()
+		if err != nil {
+			r.err = fmt.Errorf("%s: %w", Pos{r.files[r.fileIdx],
+				r.offset}, err)
+			r.closeFile()
+			return false
+		}
+		if ok {
+			return true
+		}
+
+		// The file has no more blocks.
+		r.closeFile()
+		r.fileIdx++
+	}
+}
+
+// Block returns the block read by the last call to Next.
+func (r *Reader) Block() *bchutil.Block {
+	return r.block
+}
+
+// Pos returns the position of the block read by the last call to Next.
+func (r *Reader) Pos() Pos {
+	return r.pos
+}
+
+// Err returns the first error encountered while reading blocks, or nil if all
+// blocks read so far were well formed.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Close closes the block file currently being read.
+func (r *Reader) Close() error {
+	return r.closeFile()
+}
+
+// ReadBlock reads the block at the passed position.  The network magic and
+// length preceding the block are checked, so an error is returned if the
+// position does not point at the start of a block.
+func (r *Reader) ReadBlock(pos Pos) (*bchutil.Block, error) {
+	if pos.Offset < recordHeaderSize {
+		return nil, fmt.Errorf("%s: offset is before the first block", pos)
+	}
+	f, err := os.Open(filepath.Join(r.dir, FileName(pos.File)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var header [recordHeaderSize]byte
+	_, err = f.ReadAt(header[:], pos.Offset-recordHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pos, err)
+	}
+	if !r.validMagic(header[:4]) {
+		return nil, fmt.Errorf("%s: %w", pos, ErrBadMagic)
+	}
+	size := binary.LittleEndian.Uint32(header[4:])
+	if err := checkSize(size); err != nil {
+		return nil, fmt.Errorf("%s: %w", pos, err)
+	}
+	serializedBlock := make([]byte, size)
+	_, err = f.ReadAt(serializedBlock, pos.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pos, err)
+	}
+	block, err := bchutil.NewBlockFromBytes(serializedBlock)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pos, err)
+	}
+	return block, nil
+}
+
+// openFile opens the block file with the passed number for reading.
+func (r *Reader) openFile(num int) error {
+	f, err := os.Open(filepath.Join(r.dir, FileName(num)))
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.br = bufio.NewReaderSize(f, 1<<20)
+	r.offset = 0
+	return nil
+}
+
+// closeFile closes the block file currently being read, if any.
+func (r *Reader) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	r.br = nil
+	return err
+}
+
+// readRecord reads the next block record of the current file.  It returns
+// false without an error when the file has no further complete records.
+func (r *Reader) readRecord() (bool, error) {
+	// Skip zero padding before the record.
+	for {
+		b, err := r.br.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if b != 0 {
+			if err := r.br.UnreadByte(); err != nil {
+				return false, err
+			}
+			break
+		}
+		r.offset++
+	}
+
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.br, header[:]); err != nil {
+		return false, truncated(err)
+	}
+	if !r.validMagic(header[:4]) {
+		return false, ErrBadMagic
+	}
+	size := binary.LittleEndian.Uint32(header[4:])
+	if size == 0 {
+		return false, errors.New("empty block record")
+	}
+	if err := checkSize(size); err != nil {
+		return false, err
+	}
+
+	serializedBlock := make([]byte, size)
+	if _, err := io.ReadFull(r.br, serializedBlock); err != nil {
+		return false, truncated(err)
+	}
+	block, err := bchutil.NewBlockFromBytes(serializedBlock)
+	if err != nil {
+		return false, err
+	}
+
+	r.block = block
+	r.pos = Pos{File: r.files[r.fileIdx], Offset: r.offset + recordHeaderSize}
+	r.offset += recordHeaderSize + int64(size)
+	return true, nil
+}
+
+// checkSize returns an error when the length of a block record is larger than
+// any block message, so a corrupt length does not cause a huge allocation.
+func checkSize(size uint32) error {
+	if size > wire.MaxBlockPayload() {
+		return fmt.Errorf("%w: %d bytes, max %d", ErrBlockTooBig, size,
+			wire.MaxBlockPayload())
+	}
+	return nil
+}
+
+// truncated converts the error from reading a record at the end of a file into
+// nil, since a record cut short by the end of the file is not an error.
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+// validMagic returns whether the passed bytes are one of the network magics
+// accepted by the reader.
+func (r *Reader) validMagic(b []byte) bool {
+	magic := wire.BitcoinNet(binary.LittleEndian.Uint32(b))
+	for _, m := range r.magics {
+		if magic == m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/blockfile"
+)
+
+// newBlock returns a serialized copy of the mainnet genesis block with the
+// passed nonce.
+func newBlock(t *testing.T, nonce uint32) []byte {
+	block := *chaincfg.MainNetParams.GenesisBlock
+	block.Header.Nonce = nonce
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// record returns a block file record holding block preceded by magic.
+func record(magic wire.BitcoinNet, block []byte) []byte {
+	rec := make([]byte, 8, 8+len(block))
+	binary.LittleEndian.PutUint32(rec[:4], uint32(magic))
+	binary.LittleEndian.PutUint32(rec[4:], uint32(len(block)))
+	return append(rec, block...)
+}
+
+// writeFile writes the concatenation of parts to the named file in dir.
+func writeFile(t *testing.T, dir, name string, parts ...[]byte) {
+	err := os.WriteFile(filepath.Join(dir, name), bytes.Join(parts, nil), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: unexpected error: %v", err)
+	}
+}
+
+// TestReader ensures blocks are read from block files in order with their
+// positions while padding and truncated tails are skipped.
+func TestReader(t *testing.T) {
+	dir := t.TempDir()
+	blocks := [][]byte{newBlock(t, 1), newBlock(t, 2), newBlock(t, 3)}
+	diskMagic := wire.BitcoinNet(0xd9b4bef9)
+
+	// The first file holds two blocks, one with each magic, separated and
+	// followed by zero padding.  The second file ends with a truncated
+	// record.
+	writeFile(t, dir, "blk00000.dat", record(wire.MainNet, blocks[0]),
+		make([]byte, 5), record(diskMagic, blocks[1]), make([]byte, 100))
+	writeFile(t, dir, "blk00001.dat", record(wire.MainNet, blocks[2]),
+		record(wire.MainNet, blocks[0])[:50])
+	writeFile(t, dir, "rev00000.dat", []byte{0x01})
+
+	r, err := blockfile.NewReader(dir, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	wantPos := []blockfile.Pos{
+		{File: 0, Offset: 8},
+		{File: 0, Offset: int64(8 + len(blocks[0]) + 5 + 8)},
+		{File: 1, Offset: 8},
+	}
+	var i int
+	for ; r.Next(); i++ {
+		if i >= len(blocks) {
+			t.Fatalf("Next: read more than %d blocks", len(blocks))
+		}
+		if r.Pos() != wantPos[i] {
+			t.Errorf("Pos #%d: got %v, want %v", i, r.Pos(), wantPos[i])
+		}
+		got, err := r.Block().Bytes()
+		if err != nil || !bytes.Equal(got, blocks[i]) {
+			t.Errorf("Block #%d: mismatched block", i)
+		}
+
+		// Random access by position returns the same block.
+		block, err := r.ReadBlock(r.Pos())
+		if err != nil {
+			t.Errorf("ReadBlock(%v): unexpected error: %v", r.Pos(), err)
+			continue
+		}
+		if !block.Hash().IsEqual(r.Block().Hash()) {
+			t.Errorf("ReadBlock(%v): got block %v, want %v", r.Pos(),
+				block.Hash(), r.Block().Hash())
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Err: unexpected error: %v", err)
+	}
+	if i != len(blocks) {
+		t.Errorf("Next: read %d blocks, want %d", i, len(blocks))
+	}
+
+	if _, err := r.ReadBlock(blockfile.Pos{File: 0, Offset: 9}); err == nil {
+		t.Errorf("ReadBlock: expected error for misaligned position")
+	}
+	if _, err := r.ReadBlock(blockfile.Pos{File: 2, Offset: 8}); err == nil {
+		t.Errorf("ReadBlock: expected error for missing file")
+	}
+}
+
+// TestReaderErrors ensures malformed block files are reported.
+func TestReaderErrors(t *testing.T) {
+	_, err := blockfile.NewReader(t.TempDir(), &chaincfg.MainNetParams)
+	if err != blockfile.ErrNoBlockFiles {
+		t.Errorf("NewReader: unexpected error - got %v, want %v", err,
+			blockfile.ErrNoBlockFiles)
+	}
+
+	// Blocks of another network are rejected.
+	dir := t.TempDir()
+	writeFile(t, dir, "blk00000.dat", record(wire.MainNet, newBlock(t, 1)))
+	r, err := blockfile.NewReader(dir, &chaincfg.TestNet4Params)
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	defer r.Close()
+	if r.Next() {
+		t.Errorf("Next: read block of another network")
+	}
+	if err := r.Err(); !errors.Is(err, blockfile.ErrBadMagic) {
+		t.Errorf("Err: unexpected error - got %v, want %v", err,
+			blockfile.ErrBadMagic)
+	}
+	_, err = r.ReadBlock(blockfile.Pos{File: 0, Offset: 8})
+	if !errors.Is(err, blockfile.ErrBadMagic) {
+		t.Errorf("ReadBlock: unexpected error - got %v, want %v", err,
+			blockfile.ErrBadMagic)
+	}
+
+	// A record length above the maximum block size is rejected before the
+	// block is read.
+	dir = t.TempDir()
+	oversized := record(wire.MainNet, newBlock(t, 1))
+	binary.LittleEndian.PutUint32(oversized[4:], wire.MaxBlockPayload()+1)
+	writeFile(t, dir, "blk00000.dat", oversized)
+	r, err = blockfile.NewReader(dir, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	defer r.Close()
+	if r.Next() {
+		t.Errorf("Next: read block with oversized length")
+	}
+	if err := r.Err(); !errors.Is(err, blockfile.ErrBlockTooBig) {
+		t.Errorf("Err: unexpected error - got %v, want %v", err,
+			blockfile.ErrBlockTooBig)
+	}
+	_, err = r.ReadBlock(blockfile.Pos{File: 0, Offset: 8})
+	if !errors.Is(err, blockfile.ErrBlockTooBig) {
+		t.Errorf("ReadBlock: unexpected error - got %v, want %v", err,
+			blockfile.ErrBlockTooBig)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchutil"
+)
+
+// DefaultMaxFileSize is the size at which a Writer moves on to the next block
+// file when no other size is given.  It matches the block file size of full
+// nodes.
+const DefaultMaxFileSize = 128 << 20
+
+// Writer appends blocks to blk*.dat files in a directory in the format read by
+// Reader.  A new file is started once adding a block would take the current
+// file past the maximum file size.  Records are written with the magic full
+// nodes write to block files for the network.
+type Writer struct {
+	dir         string
+	magic       uint32
+	maxFileSize int64
+
+	fileNum int
+	file    *os.File
+	bw      *bufio.Writer
+	offset  int64
+}
+
+// NewWriter returns a Writer which writes block files to dir.  Writing starts
+// with the file following the last block file already in dir, so existing
+// files are never modified.  A maxFileSize of zero selects
+// DefaultMaxFileSize.
+func NewWriter(dir string, params *chaincfg.Params, maxFileSize int64) (*Writer, error) {
+	if maxFileSize == 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+	magic := params.Net
+	if diskMagic, ok := diskMagics[params.Net]; ok {
+		magic = diskMagic
+	}
+
+	var fileNum int
+	r, err := NewReader(dir, params)
+	switch {
+	case err == ErrNoBlockFiles:
+	case err != nil:
+		return nil, err
+	default:
+		files := r.Files()
+		fileNum = files[len(files)-1] + 1
+	}
+	return &Writer{
+		dir:         dir,
+		magic:       uint32(magic),
+		maxFileSize: maxFileSize,
+		fileNum:     fileNum,
+	}, nil
+}
+
+// WriteBlock appends a block to the block files and returns its position.
+func (w *Writer) WriteBlock(block *bchutil.Block) (Pos, error) {
+	serializedBlock, err := block.Bytes()
+	if err != nil {
+		return Pos{}, err
+	}
+	size := int64(recordHeaderSize + len(serializedBlock))
+	if w.file != nil && w.offset > 0 && w.offset+size > w.maxFileSize {
+		if err := w.closeFile(); err != nil {
+			return Pos{}, err
+		}
+		w.fileNum++
+	}
+	if w.file == nil {
+		f, err := os.OpenFile(filepath.Join(w.dir, FileName(w.fileNum)),
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return Pos{}, err
+		}
+		w.file = f
+		w.bw = bufio.NewWriterSize(f, 1<<20)
+		w.offset = 0
+	}
+
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[:4], w.magic)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(serializedBlock)))
+	if _, err := w.bw.Write(header[:]); err != nil {
+		return Pos{}, err
+	}
+	if _, err := w.bw.Write(serializedBlock); err != nil {
+		return Pos{}, err
+	}
+	pos := Pos{File: w.fileNum, Offset: w.offset + recordHeaderSize}
+	w.offset += size
+	return pos, nil
+}
+
+// Close flushes and closes the block file currently being written.
+func (w *Writer) Close() error {
+	return w.closeFile()
+}
+
+// closeFile flushes and closes the block file currently being written, if
+// any.
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.bw.Flush()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file = nil
+	w.bw = nil
+	return err
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blockfile"
+)
+
+// TestWriter ensures written blocks are read back at the returned positions
+// and files are split at the maximum file size.
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	params := &chaincfg.MainNetParams
+	var blocks [][]byte
+	for nonce := uint32(0); nonce < 5; nonce++ {
+		blocks = append(blocks, newBlock(t, nonce))
+	}
+
+	// Two records fit in each file.
+	maxFileSize := int64(2 * (8 + len(blocks[0])))
+	var positions []blockfile.Pos
+	for _, batch := range [][][]byte{blocks[:3], blocks[3:]} {
+		w, err := blockfile.NewWriter(dir, params, maxFileSize)
+		if err != nil {
+			t.Fatalf("NewWriter: unexpected error: %v", err)
+		}
+		for _, b := range batch {
+			block, err := bchutil.NewBlockFromBytes(b)
+			if err != nil {
+				t.Fatalf("NewBlockFromBytes: unexpected error: %v", err)
+			}
+			pos, err := w.WriteBlock(block)
+			if err != nil {
+				t.Fatalf("WriteBlock: unexpected error: %v", err)
+			}
+			positions = append(positions, pos)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close: unexpected error: %v", err)
+		}
+	}
+
+	// The second writer starts a new file after the two written by the
+	// first.
+	wantPos := []blockfile.Pos{
+		{File: 0, Offset: 8},
+		{File: 0, Offset: int64(16 + len(blocks[0]))},
+		{File: 1, Offset: 8},
+		{File: 2, Offset: 8},
+		{File: 2, Offset: int64(16 + len(blocks[0]))},
+	}
+	r, err := blockfile.NewReader(dir, params)
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	defer r.Close()
+	var i int
+	for ; r.Next(); i++ {
+		if positions[i] != wantPos[i] || r.Pos() != wantPos[i] {
+			t.Errorf("Pos #%d: got %v (read %v), want %v", i,
+				positions[i], r.Pos(), wantPos[i])
+		}
+		got, err := r.Block().Bytes()
+		if err != nil || !bytes.Equal(got, blocks[i]) {
+			t.Errorf("Block #%d: got %x (%v), want %x", i, got, err,
+				blocks[i])
+		}
+	}
+	if err := r.Err(); err != nil || i != len(blocks) {
+		t.Fatalf("Next: read %d blocks (%v), want %d", i, err,
+			len(blocks))
+	}
+
+	// Records are written with the magic full nodes use on disk.
+	f, err := os.ReadFile(filepath.Join(dir, blockfile.FileName(0)))
+	if err != nil {
+		t.Fatalf("ReadFile: unexpected error: %v", err)
+	}
+	if magic := binary.LittleEndian.Uint32(f); magic != 0xd9b4bef9 {
+		t.Errorf("magic: got %08x, want d9b4bef9", magic)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+)
+
+// BlockReader walks the transactions of a serialized block without
+// deserializing the block.  Each call to Next advances to the next transaction
+// whose location, raw bytes and hash are then available without decoding it.
+// Transactions are only decoded when Tx is called, so callers which are only
+// interested in some transactions of a large block avoid the allocations of
+// decoding the rest.
+//
+// The raw transaction bytes returned by RawTx are slices of the serialized
+// block passed to NewBlockReader and must not be modified.
+type BlockReader struct {
+	serializedBlock []byte           // Serialized bytes for the block
+	header          wire.BlockHeader // Decoded block header
+	numTx           uint64           // Transaction count from the block
+	offset          int              // Offset of the next transaction
+	index           int              // Index of the current transaction
+	loc             wire.TxLoc       // Location of the current transaction
+	txHash          *chainhash.Hash  // Cached hash of the current transaction
+	err             error            // First error encountered by Next
+}
+
+// NewBlockReader returns a BlockReader over the passed serialized block.  The
+// block header and transaction count are decoded immediately and an error is
+// returned if they are malformed.  The reader is positioned before the first
+// transaction, so Next must be called before accessing it.
+func NewBlockReader(serializedBlock []byte) (*BlockReader, error) {
+	r := &BlockReader{
+		serializedBlock: serializedBlock,
+		index:           -1,
+	}
+	err := r.header.Deserialize(bytes.NewReader(serializedBlock))
+	if err != nil {
+		return nil, err
+	}
+	r.offset = wire.MaxBlockHeaderPayload
+	r.numTx, err = r.readVarInt()
+	if err != nil {
+		return nil, err
+	}
+
+	// Prevent a bogus transaction count from claiming more transactions
+	// than could possibly fit in the remaining bytes.
+	remaining := uint64(len(serializedBlock) - r.offset)
+	if r.numTx > remaining/minTxPayload {
+		str := fmt.Sprintf("too many transactions for block size - "+
+			"count %d, remaining bytes %d", r.numTx, remaining)
+		return nil, OutOfRangeError(str)
+	}
+	return r, nil
+}
+
+// minTxPayload is the minimum size of a serialized transaction, which is one
+// with no inputs and no outputs: 4 bytes version, one byte for each of the
+// input and output counts and 4 bytes lock time.
+const minTxPayload = 10
+
+// Header returns the header of the block.
+func (r *BlockReader) Header() *wire.BlockHeader {
+	return &r.header
+}
+
+// NumTx returns the number of transactions in the block.
+func (r *BlockReader) NumTx() int {
+	return int(r.numTx)
+}
+
+// Next advances the reader to the next transaction in the block.  It returns
+// false once all transactions have been read or when the transaction could not
+// be located, in which case Err returns the reason.
+func (r *BlockReader) Next() bool {
+	if r.err != nil || uint64(r.index+1) >= r.numTx {
+		return false
+	}
+
+	start := r.offset
+	if err := r.skipTx(); err != nil {
+		r.offset = start
+		r.err = fmt.Errorf("transaction %d: %v", r.index+1, err)
+		return false
+	}
+
+	r.index++
+	r.loc = wire.TxLoc{TxStart: start, TxLen: r.offset - start}
+	r.txHash = nil
+	return true
+}
+
+// Err returns the first error encountered while walking the block, or nil if
+// every transaction read so far was well formed.
+func (r *BlockReader) Err() error {
+	return r.err
+}
+
+// Index returns the index of the current transaction within the block, or -1
+// before the first call to Next.
+func (r *BlockReader) Index() int {
+	return r.index
+}
+
+// TxLoc returns the offset and length of the current transaction within the
+// serialized block.
+func (r *BlockReader) TxLoc() wire.TxLoc {
+	return r.loc
+}
+
+// RawTx returns the serialized bytes of the current transaction.  The returned
+// slice shares its memory with the serialized block and must not be modified.
+func (r *BlockReader) RawTx() []byte {
+	end := r.loc.TxStart + r.loc.TxLen
+	return r.serializedBlock[r.loc.TxStart:end:end]
+}
+
+// TxHash returns the hash of the current transaction.  It is computed from the
+// raw transaction bytes on first access without decoding the transaction.
+func (r *BlockReader) TxHash() *chainhash.Hash {
+	if r.txHash == nil {
+		hash := chainhash.DoubleHashH(r.RawTx())
+		r.txHash = &hash
+	}
+	return r.txHash
+}
+
+// Tx decodes and returns the current transaction wrapped as a Tx with its index
+// in the block set.  A hash already computed by TxHash is carried over.
+func (r *BlockReader) Tx() (*Tx, error) {
+	var msgTx wire.MsgTx
+	err := msgTx.Deserialize(bytes.NewReader(r.RawTx()))
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{
+		msgTx:   &msgTx,
+		txHash:  r.txHash,
+		txIndex: r.index,
+	}, nil
+}
+
+// skipTx advances the offset past the serialized transaction starting at it.
+func (r *BlockReader) skipTx() error {
+	// Version.
+	if err := r.skip(4); err != nil {
+		return err
+	}
+
+	numTxIn, err := r.readVarInt()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < numTxIn; i++ {
+		// Previous outpoint.
+		if err := r.skip(chainhash.HashSize + 4); err != nil {
+			return err
+		}
+		if err := r.skipVarBytes(); err != nil {
+			return err
+		}
+		// Sequence.
+		if err := r.skip(4); err != nil {
+			return err
+		}
+	}
+
+	numTxOut, err := r.readVarInt()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < numTxOut; i++ {
+		// Value.
+		if err := r.skip(8); err != nil {
+			return err
+		}
+		// The public key script, including any token data prefix.
+		if err := r.skipVarBytes(); err != nil {
+			return err
+		}
+	}
+
+	// Lock time.
+	return r.skip(4)
+}
+
+// skip advances the offset by n bytes.  Like io.ReadFull, io.EOF is returned
+// when no bytes remain and io.ErrUnexpectedEOF when only some of them do.
+func (r *BlockReader) skip(n uint64) error {
+	remaining := uint64(len(r.serializedBlock) - r.offset)
+	if n > remaining {
+		if remaining == 0 {
+			return io.EOF
+		}
+		return io.ErrUnexpectedEOF
+	}
+	r.offset += int(n)
+	return nil
+}
+
+// skipVarBytes advances the offset past a variable length byte array.
+func (r *BlockReader) skipVarBytes() error {
+	n, err := r.readVarInt()
+	if err != nil {
+		return err
+	}
+	return r.skip(n)
+}
+
+// readVarInt reads a variable length integer at the offset and advances past
+// it.  Like wire.ReadVarInt, non-canonical encodings are rejected.
+func (r *BlockReader) readVarInt() (uint64, error) {
+	buf := r.serializedBlock[r.offset:]
+	if len(buf) < 1 {
+		return 0, io.EOF
+	}
+
+	var size int
+	var rv, min uint64
+	switch discriminant := buf[0]; discriminant {
+	case 0xff:
+		size, min = 9, 0x100000000
+	case 0xfe:
+		size, min = 5, 0x10000
+	case 0xfd:
+		size, min = 3, 0xfd
+	default:
+		r.offset++
+		return uint64(discriminant), nil
+	}
+	if len(buf) < size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	switch size {
+	case 9:
+		rv = binary.LittleEndian.Uint64(buf[1:])
+	case 5:
+		rv = uint64(binary.LittleEndian.Uint32(buf[1:]))
+	case 3:
+		rv = uint64(binary.LittleEndian.Uint16(buf[1:]))
+	}
+	if rv < min {
+		return 0, fmt.Errorf("non-canonical varint %x - discriminant "+
+			"%x must encode a value greater than %x", rv, buf[0], min)
+	}
+	r.offset += size
+	return rv, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/gcash/bchutil"
+)
+
+// TestBlockReader ensures the BlockReader yields the same transactions,
+// locations and hashes as a fully deserialized block.
+func TestBlockReader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Block100000.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	block100000Bytes := buf.Bytes()
+
+	b := bchutil.NewBlock(&Block100000)
+	wantTxLocs, err := b.TxLoc()
+	if err != nil {
+		t.Fatalf("TxLoc: %v", err)
+	}
+
+	r, err := bchutil.NewBlockReader(block100000Bytes)
+	if err != nil {
+		t.Fatalf("NewBlockReader: %v", err)
+	}
+	if !reflect.DeepEqual(r.Header(), &Block100000.Header) {
+		t.Errorf("Header: mismatched header - got %v, want %v",
+			r.Header(), &Block100000.Header)
+	}
+	if r.NumTx() != len(Block100000.Transactions) {
+		t.Errorf("NumTx: got %d, want %d", r.NumTx(),
+			len(Block100000.Transactions))
+	}
+	if r.Index() != -1 {
+		t.Errorf("Index: got %d before Next, want -1", r.Index())
+	}
+
+	i := 0
+	for ; r.Next(); i++ {
+		if r.Index() != i {
+			t.Errorf("Index: got %d, want %d", r.Index(), i)
+		}
+		if r.TxLoc() != wantTxLocs[i] {
+			t.Errorf("TxLoc #%d: got %v, want %v", i, r.TxLoc(),
+				wantTxLocs[i])
+		}
+		wantTx, _ := b.Tx(i)
+		if !r.TxHash().IsEqual(wantTx.Hash()) {
+			t.Errorf("TxHash #%d: got %v, want %v", i, r.TxHash(),
+				wantTx.Hash())
+		}
+		var raw bytes.Buffer
+		wantTx.MsgTx().Serialize(&raw)
+		if !bytes.Equal(r.RawTx(), raw.Bytes()) {
+			t.Errorf("RawTx #%d: mismatched bytes", i)
+		}
+
+		tx, err := r.Tx()
+		if err != nil {
+			t.Errorf("Tx #%d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tx.MsgTx(), wantTx.MsgTx()) {
+			t.Errorf("Tx #%d: mismatched transaction", i)
+		}
+		if tx.Index() != i || !tx.Hash().IsEqual(wantTx.Hash()) {
+			t.Errorf("Tx #%d: got index %d hash %v, want index %d "+
+				"hash %v", i, tx.Index(), tx.Hash(), i,
+				wantTx.Hash())
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Err: unexpected error: %v", err)
+	}
+	if i != len(Block100000.Transactions) {
+		t.Errorf("Next: read %d transactions, want %d", i,
+			len(Block100000.Transactions))
+	}
+}
+
+// TestBlockReaderErrors ensures malformed blocks are reported.
+func TestBlockReaderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Block100000.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	block100000Bytes := buf.Bytes()
+
+	// A block without a transaction count.
+	_, err := bchutil.NewBlockReader(block100000Bytes[:80])
+	if err != io.EOF {
+		t.Errorf("NewBlockReader: did not get expected error - got %v, "+
+			"want %v", err, io.EOF)
+	}
+
+	// A transaction count which cannot fit in the block.
+	tooMany := append(block100000Bytes[:80:80], 0xfd, 0xff, 0xff)
+	_, err = bchutil.NewBlockReader(tooMany)
+	if _, ok := err.(bchutil.OutOfRangeError); !ok {
+		t.Errorf("NewBlockReader: wrong error - got: %v <%T>, want: <%T>",
+			err, err, bchutil.OutOfRangeError(""))
+	}
+
+	// A block truncated within its last transaction yields the earlier
+	// transactions and then stops with an error.
+	truncated := block100000Bytes[:len(block100000Bytes)-10]
+	r, err := bchutil.NewBlockReader(truncated)
+	if err != nil {
+		t.Fatalf("NewBlockReader: %v", err)
+	}
+	n := 0
+	for r.Next() {
+		n++
+	}
+	if n != len(Block100000.Transactions)-1 {
+		t.Errorf("Next: read %d transactions, want %d", n,
+			len(Block100000.Transactions)-1)
+	}
+	if r.Err() == nil {
+		t.Errorf("Err: expected error for truncated block")
+	}
+	if r.Next() {
+		t.Errorf("Next: advanced after error")
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil
+
+import (
+	"fmt"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/merkle"
+)
+
+const (
+	// DefaultMaxBlockSize is the maximum serialized block size used by
+	// CheckSanity when no other limit is configured.  It matches the
+	// default excessive block size of bitcoin cash nodes.
+	DefaultMaxBlockSize = 32000000
+
+	// MaxTxSize is the maximum serialized size of a transaction allowed by
+	// the consensus rules.
+	MaxTxSize = 1000000
+
+	// MinTxSize is the minimum serialized size of a transaction allowed by
+	// the consensus rules.
+	MinTxSize = 65
+)
+
+// BlockErrorCode identifies a kind of block sanity violation.
+type BlockErrorCode int
+
+// These constants are used to identify a specific BlockError.
+const (
+	// ErrNoTransactions indicates the block does not have at least one
+	// transaction.  A valid block must have at least the coinbase
+	// transaction.
+	ErrNoTransactions BlockErrorCode = iota
+
+	// ErrBlockTooBig indicates the serialized block size exceeds the
+	// maximum allowed size.
+	ErrBlockTooBig
+
+	// ErrFirstTxNotCoinbase indicates the first transaction in a block is
+	// not a coinbase transaction.
+	ErrFirstTxNotCoinbase
+
+	// ErrMultipleCoinbases indicates a block contains more than one
+	// coinbase transaction.
+	ErrMultipleCoinbases
+
+	// ErrTxTooBig indicates a transaction exceeds MaxTxSize.
+	ErrTxTooBig
+
+	// ErrTxTooSmall indicates a transaction is smaller than MinTxSize.
+	ErrTxTooSmall
+
+	// ErrBadMerkleRoot indicates the calculated merkle root does not match
+	// the expected value.
+	ErrBadMerkleRoot
+
+	// ErrDuplicateTx indicates a block contains an identical transaction
+	// more than once, including the duplicated subtrees of CVE-2012-2459
+	// which leave the merkle root unchanged.
+	ErrDuplicateTx
+
+	// ErrInvalidTxOrder indicates the transactions following the coinbase
+	// are not in canonical (CTOR) order, which is ascending by txid.
+	ErrInvalidTxOrder
+
+	// ErrMissingCoinbaseHeight indicates the coinbase signature script of
+	// a block does not start with the serialized block height required by
+	// BIP0034.
+	ErrMissingCoinbaseHeight
+)
+
+// Map of BlockErrorCode values back to their constant names for pretty
+// printing.
+var blockErrorCodeStrings = map[BlockErrorCode]string{
+	ErrNoTransactions:        "ErrNoTransactions",
+	ErrBlockTooBig:           "ErrBlockTooBig",
+	ErrFirstTxNotCoinbase:    "ErrFirstTxNotCoinbase",
+	ErrMultipleCoinbases:     "ErrMultipleCoinbases",
+	ErrTxTooBig:              "ErrTxTooBig",
+	ErrTxTooSmall:            "ErrTxTooSmall",
+	ErrBadMerkleRoot:         "ErrBadMerkleRoot",
+	ErrDuplicateTx:           "ErrDuplicateTx",
+	ErrInvalidTxOrder:        "ErrInvalidTxOrder",
+	ErrMissingCoinbaseHeight: "ErrMissingCoinbaseHeight",
+}
+
+// String returns the BlockErrorCode as a human-readable name.
+func (e BlockErrorCode) String() string {
+	if s := blockErrorCodeStrings[e]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown BlockErrorCode (%d)", int(e))
+}
+
+// BlockError identifies a block sanity violation.  The caller can use type
+// assertions and the ErrorCode field to determine the specific rule that was
+// violated.
+type BlockError struct {
+	ErrorCode   BlockErrorCode // Describes the kind of error
+	Description string         // Human readable description of the issue
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e BlockError) Error() string {
+	return e.Description
+}
+
+// blockError creates a BlockError given a set of arguments.
+func blockError(c BlockErrorCode, desc string) BlockError {
+	return BlockError{ErrorCode: c, Description: desc}
+}
+
+// SanityConfig modifies the rules checked by Block.CheckSanity.
+type SanityConfig struct {
+	// MaxBlockSize is the maximum serialized block size.  When zero,
+	// DefaultMaxBlockSize is used.
+	MaxBlockSize int
+
+	// PreMagneticAnomaly disables the canonical transaction order and
+	// minimum transaction size rules, which only apply to blocks mined
+	// after the November 2018 upgrade.
+	PreMagneticAnomaly bool
+}
+
+// MerkleRoot returns the merkle root of the transactions in the Block.  The
+// result is not cached, however the transaction hashes it is computed from
+// are.
+func (b *Block) MerkleRoot() *chainhash.Hash {
+	root, _ := merkle.CalcRoot(b.txHashes())
+	return &root
+}
+
+// VerifyMerkleRoot returns a BlockError if the merkle root in the block header
+// does not commit to the transactions in the Block.  ErrBadMerkleRoot is
+// reported when the roots differ and ErrDuplicateTx when they match only
+// because the transaction list was mutated by duplicating a subtree as
+// described in CVE-2012-2459.
+func (b *Block) VerifyMerkleRoot() error {
+	root, mutated := merkle.CalcRoot(b.txHashes())
+	header := &b.msgBlock.Header
+	if !header.MerkleRoot.IsEqual(&root) {
+		str := fmt.Sprintf("block merkle root is invalid - block "+
+			"header indicates %v, but calculated value is %v",
+			header.MerkleRoot, root)
+		return blockError(ErrBadMerkleRoot, str)
+	}
+	if mutated {
+		return blockError(ErrDuplicateTx, "block transactions "+
+			"contain a duplicated merkle subtree")
+	}
+	return nil
+}
+
+// CheckSanity performs context free checks on the structure of the Block and
+// returns a BlockError describing the first violation found.  It checks that
+// the block has a single coinbase in the first position, that the block and
+// transaction sizes are within limits, that the merkle root commits to the
+// transactions, that no transaction is duplicated and that the transactions
+// are in canonical order.
+//
+// A nil config checks the current consensus rules with DefaultMaxBlockSize.
+// The proof of work and header fields other than the merkle root are not
+// checked, nor are the transaction contents beyond their size.
+func (b *Block) CheckSanity(config *SanityConfig) error {
+	if config == nil {
+		config = &SanityConfig{}
+	}
+	maxBlockSize := config.MaxBlockSize
+	if maxBlockSize == 0 {
+		maxBlockSize = DefaultMaxBlockSize
+	}
+
+	msgBlock := b.msgBlock
+	numTx := len(msgBlock.Transactions)
+	if numTx == 0 {
+		return blockError(ErrNoTransactions, "block does not contain "+
+			"any transactions")
+	}
+
+	serializedSize := msgBlock.SerializeSize()
+	if serializedSize > maxBlockSize {
+		str := fmt.Sprintf("serialized block is too big - got %d, "+
+			"max %d", serializedSize, maxBlockSize)
+		return blockError(ErrBlockTooBig, str)
+	}
+
+	if !isCoinBaseTx(msgBlock.Transactions[0]) {
+		return blockError(ErrFirstTxNotCoinbase, "first transaction in "+
+			"block is not a coinbase")
+	}
+	for i, tx := range msgBlock.Transactions[1:] {
+		if isCoinBaseTx(tx) {
+			str := fmt.Sprintf("block contains second coinbase at "+
+				"index %d", i+1)
+			return blockError(ErrMultipleCoinbases, str)
+		}
+	}
+
+	for i, tx := range msgBlock.Transactions {
+		size := tx.SerializeSize()
+		if size > MaxTxSize {
+			str := fmt.Sprintf("transaction %d size of %d bytes is "+
+				"larger than max allowed size of %d", i, size,
+				MaxTxSize)
+			return blockError(ErrTxTooBig, str)
+		}
+		if !config.PreMagneticAnomaly && size < MinTxSize {
+			str := fmt.Sprintf("transaction %d size of %d bytes is "+
+				"smaller than min allowed size of %d", i, size,
+				MinTxSize)
+			return blockError(ErrTxTooSmall, str)
+		}
+	}
+
+	if err := b.VerifyMerkleRoot(); err != nil {
+		return err
+	}
+
+	transactions := b.Transactions()
+	existingTxHashes := make(map[chainhash.Hash]struct{}, numTx)
+	for _, tx := range transactions {
+		hash := tx.Hash()
+		if _, exists := existingTxHashes[*hash]; exists {
+			str := fmt.Sprintf("block contains duplicate "+
+				"transaction %v", hash)
+			return blockError(ErrDuplicateTx, str)
+		}
+		existingTxHashes[*hash] = struct{}{}
+	}
+
+	// The coinbase is exempt from the canonical order.
+	if !config.PreMagneticAnomaly {
+		for i := 2; i < numTx; i++ {
+			if transactions[i-1].Hash().Compare(transactions[i].Hash()) >= 0 {
+				str := fmt.Sprintf("transaction %d (%v) is not "+
+					"ordered after transaction %d (%v)", i,
+					transactions[i].Hash(), i-1,
+					transactions[i-1].Hash())
+				return blockError(ErrInvalidTxOrder, str)
+			}
+		}
+	}
+
+	return nil
+}
+
+// txHashes returns the hashes of the transactions in the Block in order.
+func (b *Block) txHashes() []chainhash.Hash {
+	transactions := b.Transactions()
+	hashes := make([]chainhash.Hash, len(transactions))
+	for i, tx := range transactions {
+		hashes[i] = *tx.Hash()
+	}
+	return hashes
+}
+
+// isCoinBaseTx returns whether the transaction is a coinbase, which is a
+// transaction with a single input spending the null outpoint.
+func isCoinBaseTx(msgTx *wire.MsgTx) bool {
+	if len(msgTx.TxIn) != 1 {
+		return false
+	}
+	prevOut := &msgTx.TxIn[0].PreviousOutPoint
+	return prevOut.Index == wire.MaxPrevOutIndex &&
+		prevOut.Hash == (chainhash.Hash{})
+}
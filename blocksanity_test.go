@@ -0,0 +1,213 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+)
+
+// newSanityTestBlock returns a block with a coinbase followed by numTx
+// canonically ordered transactions and a valid merkle root.
+func newSanityTestBlock(numTx int) *wire.MsgBlock {
+	pkScript := bytes.Repeat([]byte{0x51}, 25)
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), []byte{0x01, 0x02, 0x03, 0x04}))
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, pkScript, wire.TokenData{}))
+
+	var txns []*wire.MsgTx
+	for i := 0; i < numTx; i++ {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(
+			&chainhash.Hash{byte(i + 1)}, 0), []byte{0x51}))
+		tx.AddTxOut(wire.NewTxOut(1000, pkScript, wire.TokenData{}))
+		txns = append(txns, tx)
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		hi, hj := txns[i].TxHash(), txns[j].TxHash()
+		return hi.Compare(&hj) < 0
+	})
+
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{Version: 1})
+	msgBlock.AddTransaction(coinbase)
+	for _, tx := range txns {
+		msgBlock.AddTransaction(tx)
+	}
+	msgBlock.Header.MerkleRoot = *bchutil.NewBlock(msgBlock).MerkleRoot()
+	return msgBlock
+}
+
+// TestMerkleRoot ensures the merkle root of a known block is computed
+// correctly.
+func TestMerkleRoot(t *testing.T) {
+	b := bchutil.NewBlock(&Block100000)
+	if got := b.MerkleRoot(); !got.IsEqual(&Block100000.Header.MerkleRoot) {
+		t.Errorf("MerkleRoot: got %v, want %v", got,
+			Block100000.Header.MerkleRoot)
+	}
+	if err := b.VerifyMerkleRoot(); err != nil {
+		t.Errorf("VerifyMerkleRoot: unexpected error: %v", err)
+	}
+}
+
+// TestCheckSanity ensures CheckSanity reports the expected violations.
+func TestCheckSanity(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(msgBlock *wire.MsgBlock)
+		config *bchutil.SanityConfig
+		code   bchutil.BlockErrorCode
+		valid  bool
+	}{
+		{
+			name:   "valid block",
+			modify: func(msgBlock *wire.MsgBlock) {},
+			valid:  true,
+		},
+		{
+			name: "no transactions",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Transactions = nil
+			},
+			code: bchutil.ErrNoTransactions,
+		},
+		{
+			name:   "too big",
+			modify: func(msgBlock *wire.MsgBlock) {},
+			config: &bchutil.SanityConfig{MaxBlockSize: 200},
+			code:   bchutil.ErrBlockTooBig,
+		},
+		{
+			name: "first transaction not coinbase",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Transactions = msgBlock.Transactions[1:]
+			},
+			code: bchutil.ErrFirstTxNotCoinbase,
+		},
+		{
+			name: "second coinbase",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Transactions[2] = msgBlock.Transactions[0].Copy()
+				msgBlock.Transactions[2].TxIn[0].SignatureScript = []byte{0x05}
+			},
+			code: bchutil.ErrMultipleCoinbases,
+		},
+		{
+			name: "transaction too small",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Transactions[1].TxOut[0].PkScript = nil
+			},
+			code: bchutil.ErrTxTooSmall,
+		},
+		{
+			name: "small transaction before magnetic anomaly",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Transactions[1].TxOut[0].PkScript = nil
+				msgBlock.Header.MerkleRoot = *bchutil.NewBlock(
+					msgBlock).MerkleRoot()
+			},
+			config: &bchutil.SanityConfig{PreMagneticAnomaly: true},
+			valid:  true,
+		},
+		{
+			name: "bad merkle root",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Header.MerkleRoot = chainhash.Hash{0x01}
+			},
+			code: bchutil.ErrBadMerkleRoot,
+		},
+		{
+			// Duplicating the last transaction of a block with an
+			// odd number of transactions leaves the merkle root
+			// unchanged.
+			name: "mutated merkle tree",
+			modify: func(msgBlock *wire.MsgBlock) {
+				last := msgBlock.Transactions[len(msgBlock.Transactions)-1]
+				msgBlock.AddTransaction(last)
+			},
+			code: bchutil.ErrDuplicateTx,
+		},
+		{
+			name: "duplicate transaction",
+			modify: func(msgBlock *wire.MsgBlock) {
+				msgBlock.Transactions[2] = msgBlock.Transactions[1]
+				msgBlock.Header.MerkleRoot = *bchutil.NewBlock(
+					msgBlock).MerkleRoot()
+			},
+			code: bchutil.ErrDuplicateTx,
+		},
+		{
+			name: "not canonically ordered",
+			modify: func(msgBlock *wire.MsgBlock) {
+				txns := msgBlock.Transactions
+				txns[1], txns[2] = txns[2], txns[1]
+				msgBlock.Header.MerkleRoot = *bchutil.NewBlock(
+					msgBlock).MerkleRoot()
+			},
+			code: bchutil.ErrInvalidTxOrder,
+		},
+	}
+
+	for _, test := range tests {
+		msgBlock := newSanityTestBlock(4)
+		test.modify(msgBlock)
+
+		err := bchutil.NewBlock(msgBlock).CheckSanity(test.config)
+		if test.valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		blockErr, ok := err.(bchutil.BlockError)
+		if !ok {
+			t.Errorf("%s: unexpected error type - got %T (%v), want "+
+				"%T", test.name, err, err, bchutil.BlockError{})
+			continue
+		}
+		if blockErr.ErrorCode != test.code {
+			t.Errorf("%s: unexpected error code - got %v, want %v",
+				test.name, blockErr.ErrorCode, test.code)
+		}
+	}
+
+	// Blocks mined before the canonical order was adopted are accepted
+	// when the order check is disabled.
+	b := bchutil.NewBlock(&Block100000)
+	config := &bchutil.SanityConfig{PreMagneticAnomaly: true}
+	if err := b.CheckSanity(config); err != nil {
+		t.Errorf("CheckSanity(Block100000): unexpected error: %v", err)
+	}
+}
+
+// TestBlockErrorCodeStringer tests the stringized output for the
+// BlockErrorCode type.
+func TestBlockErrorCodeStringer(t *testing.T) {
+	tests := []struct {
+		in   bchutil.BlockErrorCode
+		want string
+	}{
+		{bchutil.ErrNoTransactions, "ErrNoTransactions"},
+		{bchutil.ErrDuplicateTx, "ErrDuplicateTx"},
+		{bchutil.ErrInvalidTxOrder, "ErrInvalidTxOrder"},
+		{bchutil.ErrMissingCoinbaseHeight, "ErrMissingCoinbaseHeight"},
+		{0xffff, "Unknown BlockErrorCode (65535)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package blockstats computes per block statistics in the shape of the result of
+the getblockstats RPC of full nodes.
+
+# Overview
+
+Calc computes the statistics of a bchutil.Block from the outputs spent by its
+inputs, which the caller supplies, so statistics can be computed offline, such
+as from block files.  The result covers fee totals and averages, the minimum,
+maximum, median and size weighted percentile fee rates, input and output
+counts, transaction sizes, OP_RETURN and token output counts and the change in
+the size of the UTXO set:
+
+	stats, err := blockstats.Calc(block, prevOuts, &chaincfg.MainNetParams)
+	if err != nil {
+		return err
+	}
+	fmt.Println(stats.TotalFee, stats.FeeRatePercentiles)
+
+BlockStats marshals to JSON with the field names used by getblockstats, with
+the token and OP_RETURN output counts as additional fields.  Fee rates are in
+satoshi per byte.
+*/
+package blockstats
@@ -0,0 +1,223 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockstats
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+)
+
+// perUTXOOverhead is the size counted for each unspent output in addition to
+// its serialized size: the outpoint, the height and coinbase flag.
+const perUTXOOverhead = 36 + 4 + 1
+
+// opReturn is the opcode starting null data outputs.
+const opReturn = 0x6a
+
+// BlockStats holds per block statistics in the shape of the result of the
+// getblockstats RPC.  Fee rates are in satoshi per byte.  Fee, fee rate and
+// transaction size statistics exclude the coinbase transaction and are zero
+// for a block without other transactions.
+type BlockStats struct {
+	AvgFee             bchutil.Amount    `json:"avgfee"`
+	AvgFeeRate         bchutil.Amount    `json:"avgfeerate"`
+	AvgTxSize          int64             `json:"avgtxsize"`
+	BlockHash          string            `json:"blockhash"`
+	FeeRatePercentiles [5]bchutil.Amount `json:"feerate_percentiles"`
+	Height             int32             `json:"height"`
+	Ins                int64             `json:"ins"`
+	MaxFee             bchutil.Amount    `json:"maxfee"`
+	MaxFeeRate         bchutil.Amount    `json:"maxfeerate"`
+	MaxTxSize          int64             `json:"maxtxsize"`
+	MedianFee          bchutil.Amount    `json:"medianfee"`
+	MedianTxSize       int64             `json:"mediantxsize"`
+	MinFee             bchutil.Amount    `json:"minfee"`
+	MinFeeRate         bchutil.Amount    `json:"minfeerate"`
+	MinTxSize          int64             `json:"mintxsize"`
+	OpReturnOutputs    int64             `json:"op_return_outputs"`
+	Outs               int64             `json:"outs"`
+	Subsidy            bchutil.Amount    `json:"subsidy"`
+	Time               int64             `json:"time"`
+	TokenOutputs       int64             `json:"token_outputs"`
+	TotalOut           bchutil.Amount    `json:"total_out"`
+	TotalSize          int64             `json:"total_size"`
+	TotalFee           bchutil.Amount    `json:"totalfee"`
+	Txs                int64             `json:"txs"`
+	UTXOIncrease       int64             `json:"utxo_increase"`
+	UTXOSizeInc        int64             `json:"utxo_size_inc"`
+	UTXOIncreaseActual int64             `json:"utxo_increase_actual"`
+	UTXOSizeIncActual  int64             `json:"utxo_size_inc_actual"`
+}
+
+// Calc computes the statistics of the passed block.  prevOuts must hold the
+// output spent by every input of the block except those spending outputs
+// created earlier in the same block, which are found in the block itself.  The
+// height of the block is taken from Height when it has been set and from the
+// coinbase otherwise.
+func Calc(block *bchutil.Block, prevOuts map[wire.OutPoint]*wire.TxOut,
+	params *chaincfg.Params) (*BlockStats, error) {
+
+	height := block.Height()
+	if height == bchutil.BlockHeightUnknown {
+		var err error
+		height, err = block.CoinbaseHeight()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	msgBlock := block.MsgBlock()
+	stats := &BlockStats{
+		BlockHash: block.Hash().String(),
+		Height:    height,
+		Subsidy:   bchutil.CalcBlockSubsidy(height, params),
+		Time:      msgBlock.Header.Timestamp.Unix(),
+		Txs:       int64(len(msgBlock.Transactions)),
+	}
+
+	// Outputs created in the block may be spent by later transactions.
+	blockOuts := make(map[wire.OutPoint]*wire.TxOut)
+
+	var fees, sizes []int64
+	var feeRates []feeRate
+	for i, tx := range block.Transactions() {
+		msgTx := tx.MsgTx()
+		var txOut bchutil.Amount
+		for idx, out := range msgTx.TxOut {
+			blockOuts[wire.OutPoint{Hash: *tx.Hash(), Index: uint32(idx)}] = out
+			txOut += bchutil.Amount(out.Value)
+
+			outSize := int64(out.SerializeSize() + perUTXOOverhead)
+			stats.Outs++
+			stats.UTXOSizeInc += outSize
+			if !out.TokenData.IsEmpty() {
+				stats.TokenOutputs++
+			}
+			if len(out.PkScript) > 0 && out.PkScript[0] == opReturn {
+				stats.OpReturnOutputs++
+			}
+			if txscript.IsUnspendable(out.PkScript) {
+				continue
+			}
+			stats.UTXOIncreaseActual++
+			stats.UTXOSizeIncActual += outSize
+		}
+		if i == 0 {
+			continue
+		}
+
+		var txIn bchutil.Amount
+		for idx, in := range msgTx.TxIn {
+			prevOut, ok := blockOuts[in.PreviousOutPoint]
+			if !ok {
+				prevOut, ok = prevOuts[in.PreviousOutPoint]
+			}
+			if !ok || prevOut == nil {
+				return nil, fmt.Errorf("transaction %d input %d: "+
+					"missing previous output %v", i, idx,
+					in.PreviousOutPoint)
+			}
+			txIn += bchutil.Amount(prevOut.Value)
+
+			inSize := int64(prevOut.SerializeSize() + perUTXOOverhead)
+			stats.Ins++
+			stats.UTXOSizeInc -= inSize
+			stats.UTXOSizeIncActual -= inSize
+			stats.UTXOIncreaseActual--
+		}
+
+		fee := txIn - txOut
+		if fee < 0 {
+			return nil, fmt.Errorf("transaction %d: outputs of %v "+
+				"exceed inputs of %v", i, txOut, txIn)
+		}
+		size := int64(msgTx.SerializeSize())
+		rate := fee / bchutil.Amount(size)
+
+		stats.TotalOut += txOut
+		stats.TotalSize += size
+		stats.TotalFee += fee
+		fees = append(fees, int64(fee))
+		sizes = append(sizes, size)
+		feeRates = append(feeRates, feeRate{rate: rate, size: size})
+	}
+	stats.UTXOIncrease = stats.Outs - stats.Ins
+
+	if n := len(fees); n > 0 {
+		stats.AvgFee = stats.TotalFee / bchutil.Amount(n)
+		stats.AvgFeeRate = stats.TotalFee / bchutil.Amount(stats.TotalSize)
+		stats.AvgTxSize = stats.TotalSize / int64(n)
+		stats.MedianFee = bchutil.Amount(median(fees))
+		stats.MedianTxSize = median(sizes)
+		stats.MinFee = bchutil.Amount(fees[0])
+		stats.MaxFee = bchutil.Amount(fees[n-1])
+		stats.MinTxSize, stats.MaxTxSize = sizes[0], sizes[n-1]
+
+		stats.FeeRatePercentiles = percentilesBySize(feeRates,
+			stats.TotalSize)
+		stats.MinFeeRate = feeRates[0].rate
+		stats.MaxFeeRate = feeRates[n-1].rate
+	}
+	return stats, nil
+}
+
+// feeRate is the fee rate of a transaction along with its size.
+type feeRate struct {
+	rate bchutil.Amount
+	size int64
+}
+
+// median returns the median of the passed values, averaging the middle two
+// values when there is an even number of them.  The values are sorted in
+// place, which callers rely on to read the minimum and maximum.
+func median(values []int64) int64 {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	n := len(values)
+	if n%2 == 0 {
+		return (values[n/2-1] + values[n/2]) / 2
+	}
+	return values[n/2]
+}
+
+// percentilesBySize returns the 10th, 25th, 50th, 75th and 90th percentile fee
+// rates with each transaction weighted by its size, so a percentile is the fee
+// rate paid by the byte at that position when the bytes of the block are
+// ordered by fee rate.  The fee rates are sorted in place, which callers rely
+// on to read the minimum and maximum.
+func percentilesBySize(rates []feeRate, totalSize int64) [5]bchutil.Amount {
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].rate != rates[j].rate {
+			return rates[i].rate < rates[j].rate
+		}
+		return rates[i].size < rates[j].size
+	})
+
+	thresholds := [5]float64{
+		float64(totalSize) / 10,
+		float64(totalSize) / 4,
+		float64(totalSize) / 2,
+		float64(totalSize) * 3 / 4,
+		float64(totalSize) * 9 / 10,
+	}
+	var result [5]bchutil.Amount
+	next := 0
+	var cumulative int64
+	for _, r := range rates {
+		cumulative += r.size
+		for next < len(thresholds) && float64(cumulative) >= thresholds[next] {
+			result[next] = r.rate
+			next++
+		}
+	}
+	for ; next < len(thresholds); next++ {
+		result[next] = rates[len(rates)-1].rate
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockstats_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blockstats"
+)
+
+// p2pkh returns a pay-to-pubkey-hash script for a hash of repeated b bytes.
+func p2pkh(b byte) []byte {
+	script := append([]byte{0x76, 0xa9, 0x14}, bytes.Repeat([]byte{b}, 20)...)
+	return append(script, 0x88, 0xac)
+}
+
+// newTx returns a transaction spending the passed outpoints to the passed
+// outputs.
+func newTx(prevOuts []wire.OutPoint, txOuts ...*wire.TxOut) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	for i := range prevOuts {
+		tx.AddTxIn(wire.NewTxIn(&prevOuts[i], make([]byte, 100)))
+	}
+	tx.TxOut = txOuts
+	return tx
+}
+
+// TestCalc ensures the statistics of a block are computed as expected.
+func TestCalc(t *testing.T) {
+	external := []wire.OutPoint{
+		{Hash: chainhash.Hash{0x01}, Index: 0},
+		{Hash: chainhash.Hash{0x02}, Index: 1},
+	}
+	prevOuts := map[wire.OutPoint]*wire.TxOut{
+		external[0]: wire.NewTxOut(100000, p2pkh(0x01), wire.TokenData{}),
+		external[1]: wire.NewTxOut(50000, p2pkh(0x02), wire.TokenData{}),
+	}
+
+	// The coinbase commits to height 700000.
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), []byte{0x03, 0x60, 0xae, 0x0a, 0x00}))
+	coinbase.AddTxOut(wire.NewTxOut(625000000+11000, p2pkh(0x03),
+		wire.TokenData{}))
+
+	// The first transaction pays to an output spent by the second, which
+	// creates a token output.
+	tx1 := newTx(external[:1],
+		wire.NewTxOut(90000, p2pkh(0x04), wire.TokenData{}),
+		wire.NewTxOut(0, []byte{0x6a, 0x01, 0x01}, wire.TokenData{}))
+	tokenData := wire.TokenData{CategoryID: [32]byte{0x05}, Amount: 100,
+		BitField: 0x10}
+	tx2 := newTx([]wire.OutPoint{{Hash: tx1.TxHash(), Index: 0},
+		external[1]}, wire.NewTxOut(139000, p2pkh(0x05), tokenData))
+
+	block := bchutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   4,
+			Timestamp: time.Unix(1600000000, 0),
+		},
+		Transactions: []*wire.MsgTx{coinbase, tx1, tx2},
+	})
+	stats, err := blockstats.Calc(block, prevOuts, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Calc: unexpected error: %v", err)
+	}
+
+	size1 := int64(tx1.SerializeSize())
+	size2 := int64(tx2.SerializeSize())
+	fee1, fee2 := bchutil.Amount(10000), bchutil.Amount(1000)
+	rate1 := fee1 / bchutil.Amount(size1)
+	rate2 := fee2 / bchutil.Amount(size2)
+	utxoSize := func(outs ...*wire.TxOut) int64 {
+		var size int64
+		for _, out := range outs {
+			size += int64(out.SerializeSize() + 41)
+		}
+		return size
+	}
+	opReturnSize := utxoSize(tx1.TxOut[1])
+	sizeInc := utxoSize(coinbase.TxOut[0], tx1.TxOut[0], tx1.TxOut[1],
+		tx2.TxOut[0]) - utxoSize(tx1.TxOut[0], prevOuts[external[0]],
+		prevOuts[external[1]])
+
+	want := blockstats.BlockStats{
+		AvgFee:             (fee1 + fee2) / 2,
+		AvgFeeRate:         (fee1 + fee2) / bchutil.Amount(size1+size2),
+		AvgTxSize:          (size1 + size2) / 2,
+		BlockHash:          block.Hash().String(),
+		FeeRatePercentiles: [5]bchutil.Amount{rate2, rate2, rate2, rate1, rate1},
+		Height:             700000,
+		Ins:                3,
+		MaxFee:             fee1,
+		MaxFeeRate:         rate1,
+		MaxTxSize:          size2,
+		MedianFee:          (fee1 + fee2) / 2,
+		MedianTxSize:       (size1 + size2) / 2,
+		MinFee:             fee2,
+		MinFeeRate:         rate2,
+		MinTxSize:          size1,
+		OpReturnOutputs:    1,
+		Outs:               4,
+		Subsidy:            625000000,
+		Time:               1600000000,
+		TokenOutputs:       1,
+		TotalOut:           90000 + 139000,
+		TotalSize:          size1 + size2,
+		TotalFee:           fee1 + fee2,
+		Txs:                3,
+		UTXOIncrease:       1,
+		UTXOSizeInc:        sizeInc,
+		UTXOIncreaseActual: 0,
+		UTXOSizeIncActual:  sizeInc - opReturnSize,
+	}
+	if *stats != want {
+		t.Errorf("Calc: unexpected stats\n got: %+v\nwant: %+v", *stats,
+			want)
+	}
+
+	// A missing previous output is an error.
+	delete(prevOuts, external[1])
+	if _, err := blockstats.Calc(block, prevOuts, &chaincfg.MainNetParams); err == nil {
+		t.Errorf("Calc: expected error for missing previous output")
+	}
+}
+
+// TestCalcCoinbaseOnly ensures a block with only a coinbase reports zero fee
+// and size statistics.
+func TestCalcCoinbaseOnly(t *testing.T) {
+	block := bchutil.NewBlock(&coinbaseOnlyBlock)
+	block.SetHeight(100000)
+	stats, err := blockstats.Calc(block, nil, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Calc: unexpected error: %v", err)
+	}
+	if stats.Txs != 1 || stats.Outs != 1 || stats.Ins != 0 ||
+		stats.TotalFee != 0 || stats.MinTxSize != 0 ||
+		stats.Subsidy != 5000000000 || stats.UTXOIncrease != 1 {
+
+		t.Errorf("Calc: unexpected stats %+v", *stats)
+	}
+}
+
+// coinbaseOnlyBlock is a version 1 block holding only a coinbase.
+var coinbaseOnlyBlock = wire.MsgBlock{
+	Header: wire.BlockHeader{Version: 1},
+	Transactions: []*wire.MsgTx{{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Index: 0xffffffff},
+			SignatureScript:  []byte{0x04, 0x4c, 0x86, 0x04, 0x1b, 0x02, 0x06, 0x02},
+			Sequence:         0xffffffff,
+		}},
+		TxOut: []*wire.TxOut{{
+			Value:    5000000000,
+			PkScript: p2pkh(0x06),
+		}},
+	}},
+}
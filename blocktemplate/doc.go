@@ -0,0 +1,31 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package blocktemplate assembles and mines blocks without a full node, which is
+mostly useful for building valid blocks on local regression test networks.
+
+# Overview
+
+A Builder creates the coinbase of a block, which carries the block height as
+required by BIP0034, an extra nonce and an optional tag in its signature
+script, and pays the block subsidy and fees to an address.  The transactions
+of the block are ordered following the canonical transaction ordering rule,
+the merkle root and target difficulty bits are filled in and the nonce is
+ground until the header meets its target:
+
+	builder := &blocktemplate.Builder{
+		Params: &chaincfg.RegressionNetParams,
+		PayTo:  addr,
+	}
+	block, err := builder.Build(prevHash, height, time.Now(), txs, fees)
+	if err != nil {
+		return err
+	}
+
+Templates, returned by NewTemplate, expose the unsolved block so that the
+extra nonce and the nonce can be varied by other mining code.  The offset of
+the extra nonce in the serialized coinbase is reported by ExtraNonceOffset.
+*/
+package blocktemplate
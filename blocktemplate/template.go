@@ -0,0 +1,331 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blocktemplate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/header"
+	"github.com/gcash/bchutil/merkle"
+)
+
+const (
+	// ExtraNonceSize is the size in bytes of the extra nonce pushed in the
+	// signature script of coinbase transactions built by this package.
+	ExtraNonceSize = 8
+
+	// DefaultBlockVersion is the block version used when a Builder does
+	// not set one.
+	DefaultBlockVersion = 4
+
+	// maxCoinbaseScriptLen is the maximum length a coinbase signature
+	// script can be.
+	maxCoinbaseScriptLen = 100
+)
+
+var (
+	// ErrCoinbaseScriptTooLong describes an error where the coinbase tag
+	// makes the coinbase signature script exceed the maximum length.
+	ErrCoinbaseScriptTooLong = errors.New("coinbase signature script " +
+		"is too long")
+
+	// ErrUnexpectedCoinbase describes an error where a coinbase
+	// transaction is passed as one of the transactions of a template.
+	ErrUnexpectedCoinbase = errors.New("unexpected coinbase transaction")
+
+	// ErrDuplicateTx describes an error where the same transaction is
+	// passed more than once as one of the transactions of a template.
+	ErrDuplicateTx = errors.New("duplicate transaction")
+
+	// ErrBadBits describes an error where the target difficulty bits are
+	// not positive or above the proof of work limit of the network.
+	ErrBadBits = errors.New("target difficulty bits out of range")
+)
+
+// Builder assembles blocks on top of a parent block.  The coinbase of each
+// block pays the subsidy and the fees of the block to PayTo and carries the
+// block height, as required by BIP0034, an extra nonce and Tag in its
+// signature script.  Transactions are ordered by transaction id following
+// the canonical transaction ordering rule.
+//
+// The zero values of Version and Bits are suited to local regression test
+// networks, where the proof of work limit is easily met.
+type Builder struct {
+	// Params are the parameters of the network blocks are built for.
+	Params *chaincfg.Params
+
+	// PayTo receives the coinbase output.  When nil the coinbase pays to
+	// an anyone-can-spend OP_TRUE script.
+	PayTo bchutil.Address
+
+	// ExtraNonce is the initial extra nonce of the coinbase.
+	ExtraNonce uint64
+
+	// Tag is appended to the coinbase signature script.
+	Tag []byte
+
+	// Version is the block version.  When zero, DefaultBlockVersion is
+	// used.
+	Version int32
+
+	// Bits is the compact target difficulty of built blocks.  When zero,
+	// the proof of work limit of Params is used.
+	Bits uint32
+}
+
+// Template is a block which is ready to be mined.  The header commits to
+// the coinbase and the transactions, but the nonce has not been solved.
+type Template struct {
+	// Header is the header of the block.
+	Header wire.BlockHeader
+
+	// Height is the height of the block.
+	Height int32
+
+	// Coinbase is the coinbase transaction of the block.
+	Coinbase *wire.MsgTx
+
+	// Transactions are the transactions following the coinbase, in
+	// canonical order.
+	Transactions []*bchutil.Tx
+
+	// extraNonceScriptOffset is the offset of the extra nonce in the
+	// coinbase signature script.
+	extraNonceScriptOffset int
+}
+
+// NewTemplate returns a template for the block at height on top of the block
+// with hash prevHash.  The coinbase pays the subsidy for height plus fees,
+// which is the total fee paid by txs.
+func (b *Builder) NewTemplate(prevHash *chainhash.Hash, height int32,
+	timestamp time.Time, txs []*bchutil.Tx, fees bchutil.Amount) (*Template, error) {
+
+	version := b.Version
+	if version == 0 {
+		version = DefaultBlockVersion
+	}
+	bits := b.Bits
+	if bits == 0 {
+		bits = b.Params.PowLimitBits
+	}
+	target := header.CompactToBig(bits)
+	if target.Sign() <= 0 || target.Cmp(b.Params.PowLimit) > 0 {
+		return nil, fmt.Errorf("%v: %08x", ErrBadBits, bits)
+	}
+
+	sorted, err := sortTransactions(txs)
+	if err != nil {
+		return nil, err
+	}
+	coinbase, offset, err := b.coinbase(height, fees)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{
+		Header: wire.BlockHeader{
+			Version:   version,
+			PrevBlock: *prevHash,
+			Timestamp: time.Unix(timestamp.Unix(), 0),
+			Bits:      bits,
+		},
+		Height:                 height,
+		Coinbase:               coinbase,
+		Transactions:           sorted,
+		extraNonceScriptOffset: offset,
+	}
+	t.updateMerkleRoot()
+	return t, nil
+}
+
+// Build returns a solved block at height on top of the block with hash
+// prevHash.  It is a shorthand for NewTemplate followed by Solve.  Should
+// the nonce space be exhausted, the extra nonce is incremented and mining
+// continues.
+func (b *Builder) Build(prevHash *chainhash.Hash, height int32,
+	timestamp time.Time, txs []*bchutil.Tx, fees bchutil.Amount) (*bchutil.Block, error) {
+
+	t, err := b.NewTemplate(prevHash, height, timestamp, txs, fees)
+	if err != nil {
+		return nil, err
+	}
+	extraNonce := b.ExtraNonce
+	for !Solve(&t.Header) {
+		extraNonce++
+		t.SetExtraNonce(extraNonce)
+	}
+	return t.Block(), nil
+}
+
+// coinbase returns the coinbase transaction for the block at height and the
+// offset of the extra nonce in its signature script.
+func (b *Builder) coinbase(height int32, fees bchutil.Amount) (*wire.MsgTx, int, error) {
+	heightScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).Script()
+	if err != nil {
+		return nil, 0, err
+	}
+	var extraNonce [ExtraNonceSize]byte
+	binary.LittleEndian.PutUint64(extraNonce[:], b.ExtraNonce)
+	builder := txscript.NewScriptBuilder().AddOps(heightScript).
+		AddData(extraNonce[:])
+	if len(b.Tag) > 0 {
+		builder.AddData(b.Tag)
+	}
+	sigScript, err := builder.Script()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(sigScript) > maxCoinbaseScriptLen {
+		return nil, 0, fmt.Errorf("%v: %d bytes, max %d",
+			ErrCoinbaseScriptTooLong, len(sigScript),
+			maxCoinbaseScriptLen)
+	}
+
+	var pkScript []byte
+	if b.PayTo != nil {
+		pkScript, err = txscript.PayToAddrScript(b.PayTo)
+	} else {
+		pkScript, err = txscript.NewScriptBuilder().
+			AddOp(txscript.OP_TRUE).Script()
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	subsidy := bchutil.CalcBlockSubsidy(height, b.Params)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: sigScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(wire.NewTxOut(int64(subsidy+fees), pkScript,
+		wire.TokenData{}))
+
+	// Pad the signature script so the coinbase meets the minimum
+	// transaction size.
+	if size := tx.SerializeSize(); size < bchutil.MinTxSize {
+		tx.TxIn[0].SignatureScript = append(sigScript,
+			make([]byte, bchutil.MinTxSize-size)...)
+	}
+
+	// The extra nonce data push follows the height push, so the extra
+	// nonce itself starts one byte after it.
+	return tx, len(heightScript) + 1, nil
+}
+
+// sortTransactions returns a copy of txs sorted by transaction id.
+func sortTransactions(txs []*bchutil.Tx) ([]*bchutil.Tx, error) {
+	sorted := make([]*bchutil.Tx, len(txs))
+	copy(sorted, txs)
+	for _, tx := range sorted {
+		if isCoinbase(tx.MsgTx()) {
+			return nil, fmt.Errorf("%v: %v", ErrUnexpectedCoinbase,
+				tx.Hash())
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Hash().Compare(sorted[j].Hash()) < 0
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].Hash().IsEqual(sorted[i].Hash()) {
+			return nil, fmt.Errorf("%v: %v", ErrDuplicateTx,
+				sorted[i].Hash())
+		}
+	}
+	return sorted, nil
+}
+
+// isCoinbase returns whether tx is a coinbase transaction.
+func isCoinbase(tx *wire.MsgTx) bool {
+	if len(tx.TxIn) != 1 {
+		return false
+	}
+	prevOut := &tx.TxIn[0].PreviousOutPoint
+	return prevOut.Index == wire.MaxPrevOutIndex &&
+		prevOut.Hash == chainhash.Hash{}
+}
+
+// ExtraNonceOffset returns the offset of the extra nonce in the serialized
+// coinbase transaction.
+func (t *Template) ExtraNonceOffset() int {
+	sigScriptLen := len(t.Coinbase.TxIn[0].SignatureScript)
+	return 4 + wire.VarIntSerializeSize(1) + chainhash.HashSize + 4 +
+		wire.VarIntSerializeSize(uint64(sigScriptLen)) +
+		t.extraNonceScriptOffset
+}
+
+// ExtraNonce returns the extra nonce of the coinbase.
+func (t *Template) ExtraNonce() uint64 {
+	sigScript := t.Coinbase.TxIn[0].SignatureScript
+	return binary.LittleEndian.Uint64(sigScript[t.extraNonceScriptOffset:])
+}
+
+// SetExtraNonce sets the extra nonce of the coinbase and updates the merkle
+// root of the header.
+func (t *Template) SetExtraNonce(extraNonce uint64) {
+	sigScript := t.Coinbase.TxIn[0].SignatureScript
+	binary.LittleEndian.PutUint64(sigScript[t.extraNonceScriptOffset:],
+		extraNonce)
+	t.updateMerkleRoot()
+}
+
+// TxHashes returns the hashes of the transactions of the block, starting
+// with the coinbase.
+func (t *Template) TxHashes() []chainhash.Hash {
+	hashes := make([]chainhash.Hash, 0, len(t.Transactions)+1)
+	hashes = append(hashes, t.Coinbase.TxHash())
+	for _, tx := range t.Transactions {
+		hashes = append(hashes, *tx.Hash())
+	}
+	return hashes
+}
+
+// updateMerkleRoot sets the merkle root of the header from the transactions.
+func (t *Template) updateMerkleRoot() {
+	t.Header.MerkleRoot, _ = merkle.CalcRoot(t.TxHashes())
+}
+
+// Block returns the block of the template.  The block does not share the
+// coinbase with the template, so the template may be reused.
+func (t *Template) Block() *bchutil.Block {
+	msgBlock := wire.NewMsgBlock(&t.Header)
+	msgBlock.AddTransaction(t.Coinbase.Copy())
+	for _, tx := range t.Transactions {
+		msgBlock.AddTransaction(tx.MsgTx())
+	}
+	block := bchutil.NewBlock(msgBlock)
+	block.SetHeight(t.Height)
+	return block
+}
+
+// Solve grinds the nonce of the header, starting from zero, until the hash
+// of the header meets the target given by its bits.  It returns false when
+// no nonce does, in which case the nonce is left at its maximum value.
+func Solve(hdr *wire.BlockHeader) bool {
+	target := header.CompactToBig(hdr.Bits)
+	for nonce := uint32(0); ; nonce++ {
+		hdr.Nonce = nonce
+		hash := hdr.BlockHash()
+		if header.HashToBig(&hash).Cmp(target) <= 0 {
+			return true
+		}
+		if nonce == math.MaxUint32 {
+			return false
+		}
+	}
+}
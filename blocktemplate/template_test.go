@@ -0,0 +1,229 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blocktemplate_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blocktemplate"
+	"github.com/gcash/bchutil/header"
+)
+
+// newSpend returns a transaction spending output index of prevHash.
+func newSpend(prevHash chainhash.Hash, index uint32) *bchutil.Tx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, index),
+		bytes.Repeat([]byte{0x51}, 8)))
+	tx.AddTxOut(wire.NewTxOut(1000, bytes.Repeat([]byte{0x51}, 25),
+		wire.TokenData{}))
+	return bchutil.NewTx(tx)
+}
+
+// newBuilder returns a regtest Builder paying to a pay-to-pubkey-hash
+// address.
+func newBuilder(t *testing.T) *blocktemplate.Builder {
+	params := &chaincfg.RegressionNetParams
+	addr, err := bchutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	return &blocktemplate.Builder{
+		Params:     params,
+		PayTo:      addr,
+		ExtraNonce: 0x0102030405060708,
+		Tag:        []byte("/bchutil/"),
+	}
+}
+
+// TestBuild ensures built blocks are sane, carry their height and pay the
+// coinbase address.
+func TestBuild(t *testing.T) {
+	builder := newBuilder(t)
+	params := builder.Params
+	var txs []*bchutil.Tx
+	for i := uint32(0); i < 10; i++ {
+		txs = append(txs, newSpend(*params.GenesisHash, i))
+	}
+	timestamp := params.GenesisBlock.Header.Timestamp.Add(time.Minute)
+
+	block, err := builder.Build(params.GenesisHash, 1, timestamp, txs, 5000)
+	if err != nil {
+		t.Fatalf("Build: unexpected error: %v", err)
+	}
+	if err := block.CheckSanity(nil); err != nil {
+		t.Errorf("CheckSanity: unexpected error: %v", err)
+	}
+	hdr := &block.MsgBlock().Header
+	if err := header.CheckProofOfWork(hdr, params.PowLimit); err != nil {
+		t.Errorf("CheckProofOfWork: unexpected error: %v", err)
+	}
+	if hdr.Bits != params.PowLimitBits {
+		t.Errorf("Bits: got %08x, want %08x", hdr.Bits,
+			params.PowLimitBits)
+	}
+	if hdr.Version != blocktemplate.DefaultBlockVersion {
+		t.Errorf("Version: got %d, want %d", hdr.Version,
+			blocktemplate.DefaultBlockVersion)
+	}
+	if !hdr.Timestamp.Equal(timestamp) {
+		t.Errorf("Timestamp: got %v, want %v", hdr.Timestamp, timestamp)
+	}
+	if len(block.Transactions()) != len(txs)+1 {
+		t.Fatalf("Transactions: got %d, want %d",
+			len(block.Transactions()), len(txs)+1)
+	}
+
+	height, err := block.CoinbaseHeight()
+	if err != nil || height != 1 || block.Height() != 1 {
+		t.Errorf("CoinbaseHeight: got %d (%v), want 1", height, err)
+	}
+	tag, err := block.CoinbaseTag()
+	if err != nil || tag != "/bchutil/" {
+		t.Errorf("CoinbaseTag: got %q (%v), want %q", tag, err,
+			"/bchutil/")
+	}
+	payouts, err := block.CoinbasePayouts(params)
+	if err != nil {
+		t.Fatalf("CoinbasePayouts: unexpected error: %v", err)
+	}
+	want := bchutil.CalcBlockSubsidy(1, params) + 5000
+	if len(payouts) != 1 || payouts[0].Amount != want ||
+		payouts[0].Address == nil ||
+		payouts[0].Address.String() != builder.PayTo.String() {
+		t.Errorf("CoinbasePayouts: got %+v, want %v to %v", payouts,
+			want, builder.PayTo)
+	}
+}
+
+// TestBuildChain ensures a chain of built blocks connects in a header chain.
+func TestBuildChain(t *testing.T) {
+	builder := newBuilder(t)
+	builder.PayTo = nil
+	params := builder.Params
+	chain := header.NewChain(params)
+
+	prevHash := *params.GenesisHash
+	timestamp := params.GenesisBlock.Header.Timestamp
+	for height := int32(1); height <= 20; height++ {
+		timestamp = timestamp.Add(10 * time.Minute)
+		block, err := builder.Build(&prevHash, height, timestamp, nil, 0)
+		if err != nil {
+			t.Fatalf("Build %d: unexpected error: %v", height, err)
+		}
+		if err := block.CheckSanity(nil); err != nil {
+			t.Fatalf("CheckSanity %d: unexpected error: %v", height,
+				err)
+		}
+		if _, err := chain.Add(&block.MsgBlock().Header); err != nil {
+			t.Fatalf("Add %d: unexpected error: %v", height, err)
+		}
+		prevHash = *block.Hash()
+	}
+	if tip := chain.Tip(); tip.Height != 20 || tip.Hash != prevHash {
+		t.Errorf("Tip: got %v at %d, want %v at 20", tip.Hash,
+			tip.Height, prevHash)
+	}
+}
+
+// TestTemplateExtraNonce ensures the extra nonce can be located in the
+// serialized coinbase and updated.
+func TestTemplateExtraNonce(t *testing.T) {
+	builder := newBuilder(t)
+	params := builder.Params
+	txs := []*bchutil.Tx{newSpend(*params.GenesisHash, 0)}
+	tmpl, err := builder.NewTemplate(params.GenesisHash, 1000,
+		time.Unix(1700000000, 0), txs, 0)
+	if err != nil {
+		t.Fatalf("NewTemplate: unexpected error: %v", err)
+	}
+	if tmpl.Height != 1000 {
+		t.Errorf("Height: got %d, want 1000", tmpl.Height)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Coinbase.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	offset := tmpl.ExtraNonceOffset()
+	got := binary.LittleEndian.Uint64(buf.Bytes()[offset:])
+	if got != builder.ExtraNonce || tmpl.ExtraNonce() != builder.ExtraNonce {
+		t.Errorf("extra nonce: got %x, want %x", got,
+			builder.ExtraNonce)
+	}
+
+	oldRoot := tmpl.Header.MerkleRoot
+	block := tmpl.Block()
+	tmpl.SetExtraNonce(42)
+	if tmpl.ExtraNonce() != 42 {
+		t.Errorf("ExtraNonce: got %d, want 42", tmpl.ExtraNonce())
+	}
+	if tmpl.Header.MerkleRoot == oldRoot {
+		t.Errorf("SetExtraNonce: merkle root was not updated")
+	}
+	if err := block.VerifyMerkleRoot(); err != nil {
+		t.Errorf("VerifyMerkleRoot: block changed with template: %v",
+			err)
+	}
+	if err := tmpl.Block().VerifyMerkleRoot(); err != nil {
+		t.Errorf("VerifyMerkleRoot: unexpected error: %v", err)
+	}
+}
+
+// TestNewTemplateErrors ensures invalid templates are rejected.
+func TestNewTemplateErrors(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	spend := newSpend(*params.GenesisHash, 0)
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), []byte{0x51, 0x51}))
+	coinbase.AddTxOut(wire.NewTxOut(0, []byte{0x51}, wire.TokenData{}))
+
+	tests := []struct {
+		name    string
+		builder blocktemplate.Builder
+		txs     []*bchutil.Tx
+		err     error
+	}{
+		{
+			name:    "long tag",
+			builder: blocktemplate.Builder{Tag: []byte(strings.Repeat("x", 90))},
+			err:     blocktemplate.ErrCoinbaseScriptTooLong,
+		},
+		{
+			name: "coinbase",
+			txs:  []*bchutil.Tx{spend, bchutil.NewTx(coinbase)},
+			err:  blocktemplate.ErrUnexpectedCoinbase,
+		},
+		{
+			name: "duplicate",
+			txs:  []*bchutil.Tx{spend, spend},
+			err:  blocktemplate.ErrDuplicateTx,
+		},
+		{
+			name:    "bits above limit",
+			builder: blocktemplate.Builder{Bits: 0x21010000},
+			err:     blocktemplate.ErrBadBits,
+		},
+	}
+
+	for _, test := range tests {
+		test.builder.Params = params
+		_, err := test.builder.NewTemplate(params.GenesisHash, 1,
+			time.Now(), test.txs, 0)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
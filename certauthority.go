@@ -0,0 +1,395 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+var (
+	// ErrNotCA describes an error where a certificate loaded as a
+	// certificate authority may not sign certificates.
+	ErrNotCA = errors.New("certificate is not a certificate authority")
+
+	// ErrNotIssued describes an error where a certificate was not issued
+	// by the certificate authority asked to revoke it.
+	ErrNotIssued = errors.New("certificate not issued by this authority")
+
+	// ErrBadPEM describes an error where PEM data does not hold the
+	// expected block.
+	ErrBadPEM = errors.New("invalid PEM data")
+)
+
+// CertAuthority is a small local certificate authority for securing the RPC and
+// gRPC connections of a cluster with mutual TLS.  It issues server
+// certificates, client certificates and intermediate authorities, and keeps
+// the list of the certificates it has revoked for its certificate revocation
+// lists.  A CertAuthority is safe for concurrent access.
+//
+// Keys are 256-bit ECDSA keys, as with NewTLSCertPair.  Revocations are held
+// in memory, so an authority loaded with LoadCertAuthority starts with an
+// empty revocation list.
+type CertAuthority struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	parents []*x509.Certificate
+
+	mtx       Mutex
+	revoked   []x509.RevocationListEntry
+	crlNumber int64
+}
+
+// NewCertAuthority returns a new self-signed root certificate authority for
+// organization, valid until validUntil.
+func NewCertAuthority(organization string, validUntil time.Time) (*CertAuthority, error) {
+	template, err := certTemplate(organization, organization+" Root CA",
+		validUntil)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign |
+		x509.KeyUsageDigitalSignature
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := createCert(template, template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, err
+	}
+	return &CertAuthority{cert: cert, key: priv}, nil
+}
+
+// LoadCertAuthority returns the certificate authority with the passed
+// PEM-encoded certificate and private key, as returned by CertPEM and KeyPEM.
+// The certificate may be followed by the certificates of the authorities above
+// it, as returned by ChainPEM.
+func LoadCertAuthority(certPEM, keyPEM []byte) (*CertAuthority, error) {
+	certs, err := parseCertsPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	cert := certs[0]
+	if !cert.IsCA || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, ErrNotCA
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, fmt.Errorf("%v: no EC private key", ErrBadPEM)
+	}
+	priv, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !pub.Equal(&priv.PublicKey) {
+		return nil, errors.New("private key does not match certificate")
+	}
+
+	return &CertAuthority{cert: cert, key: priv, parents: certs[1:]}, nil
+}
+
+// Certificate returns the certificate of the authority.
+func (ca *CertAuthority) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// CertPEM returns the PEM-encoded certificate of the authority.
+func (ca *CertAuthority) CertPEM() []byte {
+	return encodeCertPEM(ca.cert)
+}
+
+// KeyPEM returns the PEM-encoded private key of the authority.
+func (ca *CertAuthority) KeyPEM() ([]byte, error) {
+	return encodeKeyPEM(ca.key)
+}
+
+// ChainPEM returns the PEM-encoded certificates of the authority and of the
+// authorities above it, ending with the root.
+func (ca *CertAuthority) ChainPEM() []byte {
+	chain := encodeCertPEM(ca.cert)
+	for _, parent := range ca.parents {
+		chain = append(chain, encodeCertPEM(parent)...)
+	}
+	return chain
+}
+
+// RootPEM returns the PEM-encoded certificate of the root authority, which is
+// the certificate peers must trust.
+func (ca *CertAuthority) RootPEM() []byte {
+	return encodeCertPEM(ca.root())
+}
+
+// CertPool returns a pool holding the certificate of the root authority, for
+// use as the RootCAs or ClientCAs of a tls.Config.
+func (ca *CertAuthority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.root())
+	return pool
+}
+
+// Bundle returns the PEM-encoded certificate cert, as issued by the authority,
+// followed by the certificates of the authority and of any intermediate
+// authorities above it.  The root certificate is left out, since peers must
+// already trust it.  The bundle is the chain a server or client presents
+// during the TLS handshake.
+func (ca *CertAuthority) Bundle(cert []byte) []byte {
+	bundle := append([]byte(nil), cert...)
+	if ca.isRoot() {
+		return bundle
+	}
+	bundle = append(bundle, encodeCertPEM(ca.cert)...)
+	for _, parent := range ca.parents {
+		if isSelfSigned(parent) {
+			break
+		}
+		bundle = append(bundle, encodeCertPEM(parent)...)
+	}
+	return bundle
+}
+
+// NewIntermediate returns a new certificate authority for organization signed
+// by ca, valid until validUntil or the expiry of ca, whichever is earlier.
+func (ca *CertAuthority) NewIntermediate(organization string, validUntil time.Time) (*CertAuthority, error) {
+	template, err := ca.leafTemplate(organization,
+		organization+" Intermediate CA", validUntil)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign |
+		x509.KeyUsageDigitalSignature
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := createCert(template, ca.cert, &priv.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	parents := append([]*x509.Certificate{ca.cert}, ca.parents...)
+	return &CertAuthority{cert: cert, key: priv, parents: parents}, nil
+}
+
+// NewServerCert returns a new PEM-encoded certificate and private key for a
+// server named commonName, valid until validUntil or the expiry of ca,
+// whichever is earlier.  Each host is added as a subject alternative name,
+// either an IP address or a DNS name, and may carry a port which is ignored.
+// When hosts is empty, commonName is used.
+func (ca *CertAuthority) NewServerCert(commonName string, validUntil time.Time,
+	hosts []string) (cert, key []byte, err error) {
+
+	template, err := ca.leafTemplate(ca.organization(), commonName,
+		validUntil)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	if len(hosts) == 0 {
+		hosts = []string{commonName}
+	}
+	for _, hostStr := range hosts {
+		host, _, err := net.SplitHostPort(hostStr)
+		if err != nil {
+			host = hostStr
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	return ca.issue(template)
+}
+
+// NewClientCert returns a new PEM-encoded certificate and private key for a
+// client named commonName, valid until validUntil or the expiry of ca,
+// whichever is earlier.  Servers requiring client certificates identify the
+// client by the common name.
+func (ca *CertAuthority) NewClientCert(commonName string, validUntil time.Time) (cert, key []byte, err error) {
+	template, err := ca.leafTemplate(ca.organization(), commonName,
+		validUntil)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	return ca.issue(template)
+}
+
+// Revoke adds the PEM-encoded certificate cert, which must have been issued
+// by the authority, to its revocation list as revoked at revokedAt.
+func (ca *CertAuthority) Revoke(cert []byte, revokedAt time.Time) error {
+	certs, err := parseCertsPEM(cert)
+	if err != nil {
+		return err
+	}
+	if err := certs[0].CheckSignatureFrom(ca.cert); err != nil {
+		return fmt.Errorf("%v: %v", ErrNotIssued, err)
+	}
+	serial := certs[0].SerialNumber
+
+	ca.mtx.Lock()
+	defer ca.mtx.Unlock()
+	for _, entry := range ca.revoked {
+		if entry.SerialNumber.Cmp(serial) == 0 {
+			return nil
+		}
+	}
+	ca.revoked = append(ca.revoked, x509.RevocationListEntry{
+		SerialNumber:   serial,
+		RevocationTime: revokedAt,
+	})
+	return nil
+}
+
+// CRL returns a new PEM-encoded certificate revocation list signed by the
+// authority, listing every certificate it has revoked and due to be replaced
+// by nextUpdate.  Each list carries a number one higher than the last.
+func (ca *CertAuthority) CRL(nextUpdate time.Time) ([]byte, error) {
+	ca.mtx.Lock()
+	ca.crlNumber++
+	template := &x509.RevocationList{
+		RevokedCertificateEntries: append([]x509.RevocationListEntry(nil),
+			ca.revoked...),
+		Number:     big.NewInt(ca.crlNumber),
+		ThisUpdate: time.Now(),
+		NextUpdate: nextUpdate,
+	}
+	ca.mtx.Unlock()
+
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.cert,
+		ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revocation list: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
+
+// issue signs template with the authority and returns the PEM-encoded
+// certificate along with its new PEM-encoded private key.
+func (ca *CertAuthority) issue(template *x509.Certificate) (cert, key []byte, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	x509Cert, err := createCert(template, ca.cert, &priv.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err = encodeKeyPEM(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertPEM(x509Cert), key, nil
+}
+
+// leafTemplate returns the template of a certificate issued by the authority,
+// which may not outlive it.
+func (ca *CertAuthority) leafTemplate(organization, commonName string,
+	validUntil time.Time) (*x509.Certificate, error) {
+
+	if validUntil.After(ca.cert.NotAfter) {
+		validUntil = ca.cert.NotAfter
+	}
+	return certTemplate(organization, commonName, validUntil)
+}
+
+// organization returns the organization of the authority.
+func (ca *CertAuthority) organization() string {
+	if len(ca.cert.Subject.Organization) == 0 {
+		return ""
+	}
+	return ca.cert.Subject.Organization[0]
+}
+
+// isRoot returns whether the authority is a self-signed root.
+func (ca *CertAuthority) isRoot() bool {
+	return len(ca.parents) == 0 && isSelfSigned(ca.cert)
+}
+
+// root returns the certificate of the root authority, which is the last
+// certificate of the chain.
+func (ca *CertAuthority) root() *x509.Certificate {
+	if len(ca.parents) == 0 {
+		return ca.cert
+	}
+	return ca.parents[len(ca.parents)-1]
+}
+
+// isSelfSigned returns whether cert is signed by its own key.
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+		cert.CheckSignatureFrom(cert) == nil
+}
+
+// createCert signs template with the private key of the parent certificate and
+// returns the parsed certificate.
+func createCert(template, parent *x509.Certificate, pub *ecdsa.PublicKey,
+	priv *ecdsa.PrivateKey) (*x509.Certificate, error) {
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub,
+		priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %v", err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// encodeCertPEM returns the PEM encoding of cert.
+func encodeCertPEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// encodeKeyPEM returns the PEM encoding of an ECDSA private key.
+func encodeKeyPEM(priv *ecdsa.PrivateKey) ([]byte, error) {
+	keyBytes, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY",
+		Bytes: keyBytes}), nil
+}
+
+// parseCertsPEM parses the PEM-encoded certificates in data, of which there
+// must be at least one.
+func parseCertsPEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%v: no certificate", ErrBadPEM)
+	}
+	return certs, nil
+}
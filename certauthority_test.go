@@ -0,0 +1,369 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gcash/bchutil"
+)
+
+// parseCert returns the first PEM-encoded certificate in data.
+func parseCert(t *testing.T, data []byte) *x509.Certificate {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("pem.Decode was unable to decode the certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificate: unexpected error: %v", err)
+	}
+	return cert
+}
+
+// handshake runs a TLS handshake between a server and a client, returning the
+// errors seen by each side.
+func handshake(serverConfig, clientConfig *tls.Config) (serverErr, clientErr error) {
+	serverConn, clientConn := net.Pipe()
+	errs := make(chan error, 1)
+	go func() {
+		conn := tls.Server(serverConn, serverConfig)
+		err := conn.Handshake()
+		conn.Close()
+		errs <- err
+	}()
+
+	conn := tls.Client(clientConn, clientConfig)
+	clientErr = conn.Handshake()
+	if clientErr == nil {
+		// With TLS 1.3 the server checks the client certificate after
+		// the client completes its handshake, so read until the server
+		// closes the connection, possibly with an alert.
+		_, err := io.Copy(io.Discard, conn)
+		if err != nil {
+			clientErr = err
+		}
+	}
+	conn.Close()
+	return <-errs, clientErr
+}
+
+// TestCertAuthority ensures certificates issued by a certificate authority and
+// its intermediates secure a mutual TLS connection.
+func TestCertAuthority(t *testing.T) {
+	validUntil := time.Now().Add(365 * 24 * time.Hour)
+	root, err := bchutil.NewCertAuthority("test cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewCertAuthority: unexpected error: %v", err)
+	}
+	if !root.Certificate().IsCA {
+		t.Fatalf("NewCertAuthority: root is not a CA")
+	}
+
+	// Issue certificates from an intermediate which outlives the root, so
+	// its expiry is capped.
+	intermediate, err := root.NewIntermediate("test cluster",
+		validUntil.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("NewIntermediate: unexpected error: %v", err)
+	}
+	if !intermediate.Certificate().NotAfter.Equal(root.Certificate().NotAfter) {
+		t.Errorf("NewIntermediate: got expiry %v, want %v",
+			intermediate.Certificate().NotAfter,
+			root.Certificate().NotAfter)
+	}
+
+	hosts := []string{"node1.bogus:8335", "127.0.0.1", "::1"}
+	serverCert, serverKey, err := intermediate.NewServerCert("node1", validUntil,
+		hosts)
+	if err != nil {
+		t.Fatalf("NewServerCert: unexpected error: %v", err)
+	}
+	x509Server := parseCert(t, serverCert)
+	for _, host := range []string{"node1.bogus", "127.0.0.1", "::1"} {
+		if err := x509Server.VerifyHostname(host); err != nil {
+			t.Errorf("VerifyHostname(%s): unexpected error: %v", host, err)
+		}
+	}
+	if err := x509Server.VerifyHostname("localhost"); err == nil {
+		t.Errorf("VerifyHostname(localhost): expected error")
+	}
+
+	clientCert, clientKey, err := intermediate.NewClientCert("wallet",
+		validUntil)
+	if err != nil {
+		t.Fatalf("NewClientCert: unexpected error: %v", err)
+	}
+
+	// The bundles hold the leaf and intermediate, but not the root.
+	serverBundle := intermediate.Bundle(serverCert)
+	if n := strings.Count(string(serverBundle), "BEGIN CERTIFICATE"); n != 2 {
+		t.Errorf("Bundle: got %d certificates, want 2", n)
+	}
+	serverPair, err := tls.X509KeyPair(serverBundle, serverKey)
+	if err != nil {
+		t.Fatalf("X509KeyPair: unexpected error: %v", err)
+	}
+	clientPair, err := tls.X509KeyPair(intermediate.Bundle(clientCert),
+		clientKey)
+	if err != nil {
+		t.Fatalf("X509KeyPair: unexpected error: %v", err)
+	}
+
+	// Both sides trust only the root, loaded from its PEM encoding.
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(intermediate.RootPEM()) {
+		t.Fatalf("AppendCertsFromPEM: unable to add root")
+	}
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	clientConfig := &tls.Config{
+		Certificates: []tls.Certificate{clientPair},
+		RootCAs:      root.CertPool(),
+		ServerName:   "node1.bogus",
+	}
+	serverErr, clientErr := handshake(serverConfig, clientConfig)
+	if serverErr != nil || clientErr != nil {
+		t.Fatalf("handshake: unexpected errors: server %v, client %v",
+			serverErr, clientErr)
+	}
+
+	// Client certificates cannot authenticate servers.
+	wrongPair, err := tls.X509KeyPair(intermediate.Bundle(clientCert),
+		clientKey)
+	if err != nil {
+		t.Fatalf("X509KeyPair: unexpected error: %v", err)
+	}
+	badServer := serverConfig.Clone()
+	badServer.Certificates = []tls.Certificate{wrongPair}
+	if _, clientErr := handshake(badServer, clientConfig); clientErr == nil {
+		t.Errorf("handshake: expected error for client certificate " +
+			"used by server")
+	}
+
+	// Clients of another authority are rejected.
+	other, err := bchutil.NewCertAuthority("other cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewCertAuthority: unexpected error: %v", err)
+	}
+	otherCert, otherKey, err := other.NewClientCert("intruder", validUntil)
+	if err != nil {
+		t.Fatalf("NewClientCert: unexpected error: %v", err)
+	}
+	otherPair, err := tls.X509KeyPair(otherCert, otherKey)
+	if err != nil {
+		t.Fatalf("X509KeyPair: unexpected error: %v", err)
+	}
+	badClient := clientConfig.Clone()
+	badClient.Certificates = []tls.Certificate{otherPair}
+	if serverErr, _ := handshake(serverConfig, badClient); serverErr == nil {
+		t.Errorf("handshake: expected error for client of another " +
+			"authority")
+	}
+
+	if _, _, err := root.NewClientCert("late", time.Now().Add(-time.Hour)); err != bchutil.ErrCertExpired {
+		t.Errorf("NewClientCert: got error %v, want %v", err,
+			bchutil.ErrCertExpired)
+	}
+}
+
+// TestCertAuthorityLoad ensures a certificate authority survives a round trip
+// through its PEM encoding.
+func TestCertAuthorityLoad(t *testing.T) {
+	validUntil := time.Now().Add(24 * time.Hour)
+	root, err := bchutil.NewCertAuthority("test cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewCertAuthority: unexpected error: %v", err)
+	}
+	intermediate, err := root.NewIntermediate("test cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewIntermediate: unexpected error: %v", err)
+	}
+
+	key, err := intermediate.KeyPEM()
+	if err != nil {
+		t.Fatalf("KeyPEM: unexpected error: %v", err)
+	}
+	loaded, err := bchutil.LoadCertAuthority(intermediate.ChainPEM(), key)
+	if err != nil {
+		t.Fatalf("LoadCertAuthority: unexpected error: %v", err)
+	}
+	if string(loaded.RootPEM()) != string(root.CertPEM()) {
+		t.Errorf("LoadCertAuthority: root not restored")
+	}
+
+	cert, _, err := loaded.NewServerCert("node1", validUntil, nil)
+	if err != nil {
+		t.Fatalf("NewServerCert: unexpected error: %v", err)
+	}
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(loaded.Certificate())
+	_, err = parseCert(t, cert).Verify(x509.VerifyOptions{
+		Roots:         root.CertPool(),
+		Intermediates: intermediates,
+		DNSName:       "node1",
+	})
+	if err != nil {
+		t.Errorf("Verify: unexpected error: %v", err)
+	}
+
+	// Leaf certificates and mismatched keys are rejected.
+	_, err = bchutil.LoadCertAuthority(cert, key)
+	if err != bchutil.ErrNotCA {
+		t.Errorf("LoadCertAuthority: got error %v, want %v", err,
+			bchutil.ErrNotCA)
+	}
+	rootKey, err := root.KeyPEM()
+	if err != nil {
+		t.Fatalf("KeyPEM: unexpected error: %v", err)
+	}
+	if _, err := bchutil.LoadCertAuthority(intermediate.CertPEM(), rootKey); err == nil {
+		t.Errorf("LoadCertAuthority: expected error for mismatched key")
+	}
+	_, err = bchutil.LoadCertAuthority(nil, key)
+	if err == nil || !strings.HasPrefix(err.Error(), bchutil.ErrBadPEM.Error()) {
+		t.Errorf("LoadCertAuthority: got error %v, want %v", err,
+			bchutil.ErrBadPEM)
+	}
+}
+
+// TestCertAuthorityCRL ensures revoked certificates are listed in signed
+// certificate revocation lists.
+func TestCertAuthorityCRL(t *testing.T) {
+	validUntil := time.Now().Add(24 * time.Hour)
+	ca, err := bchutil.NewCertAuthority("test cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewCertAuthority: unexpected error: %v", err)
+	}
+	revoked, _, err := ca.NewClientCert("lost laptop", validUntil)
+	if err != nil {
+		t.Fatalf("NewClientCert: unexpected error: %v", err)
+	}
+	kept, _, err := ca.NewClientCert("wallet", validUntil)
+	if err != nil {
+		t.Fatalf("NewClientCert: unexpected error: %v", err)
+	}
+
+	revokedAt := time.Unix(time.Now().Unix(), 0).UTC()
+	for i := 0; i < 2; i++ {
+		if err := ca.Revoke(revoked, revokedAt); err != nil {
+			t.Fatalf("Revoke: unexpected error: %v", err)
+		}
+	}
+
+	other, err := bchutil.NewCertAuthority("other cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewCertAuthority: unexpected error: %v", err)
+	}
+	foreign, _, err := other.NewClientCert("intruder", validUntil)
+	if err != nil {
+		t.Fatalf("NewClientCert: unexpected error: %v", err)
+	}
+	err = ca.Revoke(foreign, revokedAt)
+	if err == nil || !strings.HasPrefix(err.Error(), bchutil.ErrNotIssued.Error()) {
+		t.Errorf("Revoke: got error %v, want %v", err, bchutil.ErrNotIssued)
+	}
+
+	for number := int64(1); number <= 2; number++ {
+		crlPEM, err := ca.CRL(time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("CRL: unexpected error: %v", err)
+		}
+		block, _ := pem.Decode(crlPEM)
+		if block == nil || block.Type != "X509 CRL" {
+			t.Fatalf("CRL: unable to decode revocation list")
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			t.Fatalf("ParseRevocationList: unexpected error: %v", err)
+		}
+		if err := crl.CheckSignatureFrom(ca.Certificate()); err != nil {
+			t.Errorf("CheckSignatureFrom: unexpected error: %v", err)
+		}
+		if crl.Number.Int64() != number {
+			t.Errorf("CRL: got number %v, want %d", crl.Number, number)
+		}
+
+		entries := crl.RevokedCertificateEntries
+		if len(entries) != 1 {
+			t.Fatalf("CRL: got %d entries, want 1", len(entries))
+		}
+		if entries[0].SerialNumber.Cmp(parseCert(t, revoked).SerialNumber) != 0 {
+			t.Errorf("CRL: revoked certificate not listed")
+		}
+		if entries[0].SerialNumber.Cmp(parseCert(t, kept).SerialNumber) == 0 {
+			t.Errorf("CRL: kept certificate listed")
+		}
+		if !entries[0].RevocationTime.Equal(revokedAt) {
+			t.Errorf("CRL: got revocation time %v, want %v",
+				entries[0].RevocationTime, revokedAt)
+		}
+	}
+}
+
+// TestCertAuthorityConcurrent ensures certificates may be revoked while
+// revocation lists are created concurrently.
+func TestCertAuthorityConcurrent(t *testing.T) {
+	validUntil := time.Now().Add(24 * time.Hour)
+	ca, err := bchutil.NewCertAuthority("test cluster", validUntil)
+	if err != nil {
+		t.Fatalf("NewCertAuthority: unexpected error: %v", err)
+	}
+
+	const numCerts = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*numCerts)
+	for i := 0; i < numCerts; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cert, _, err := ca.NewClientCert("client", validUntil)
+			if err == nil {
+				err = ca.Revoke(cert, time.Now())
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := ca.CRL(time.Now().Add(time.Hour))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	crlPEM, err := ca.CRL(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CRL: unexpected error: %v", err)
+	}
+	block, _ := pem.Decode(crlPEM)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseRevocationList: unexpected error: %v", err)
+	}
+	if crl.Number.Int64() != numCerts+1 {
+		t.Errorf("CRL: got number %v, want %d", crl.Number, numCerts+1)
+	}
+	if len(crl.RevokedCertificateEntries) != numCerts {
+		t.Errorf("CRL: got %d entries, want %d",
+			len(crl.RevokedCertificateEntries), numCerts)
+	}
+}
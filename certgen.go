@@ -21,6 +21,10 @@ import (
 	"time"
 )
 
+// ErrCertExpired describes an error where a certificate would already be
+// expired when issued.
+var ErrCertExpired = errors.New("validUntil would create an already-expired certificate")
+
 // NewTLSCertPair returns a new PEM-encoded x.509 certificate pair
 // based on a 256-bit ECDSA private key. The machine's local interface
 // addresses and all variants of IPv4 and IPv6 localhost are included as
@@ -29,26 +33,14 @@ import (
 // 256-bit ECDSA is chosen so a single cert can support both RPC and gRPC
 // connections when auto generating certificates in bchd.
 func NewTLSCertPair(organization string, validUntil time.Time, extraHosts []string) (cert, key []byte, err error) {
-	now := time.Now()
-	if validUntil.Before(now) {
-		return nil, nil, errors.New("validUntil would create an already-expired certificate")
-	}
-
-	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template, err := certTemplate(organization, "", validUntil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// end of ASN.1 time
-	endOfTime := time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC)
-	if validUntil.After(endOfTime) {
-		validUntil = endOfTime
-	}
-
-	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate serial number: %s", err)
+		return nil, nil, err
 	}
 
 	host, err := os.Hostname()
@@ -102,27 +94,15 @@ func NewTLSCertPair(organization string, validUntil time.Time, extraHosts []stri
 		}
 	}
 
-	template := x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			Organization: []string{organization},
-			CommonName:   host,
-		},
-		NotBefore: now.Add(-time.Hour * 24),
-		NotAfter:  validUntil,
-
-		KeyUsage: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature |
-			x509.KeyUsageCertSign,
-		BasicConstraintsValid: true,
-
-		IsCA: true,
+	template.Subject.CommonName = host
+	template.KeyUsage = x509.KeyUsageKeyEncipherment |
+		x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
+	template.IsCA = true
+	template.DNSNames = dnsNames
+	template.IPAddresses = ipAddresses
 
-		DNSNames:    dnsNames,
-		IPAddresses: ipAddresses,
-	}
-
-	derBytes, err := x509.CreateCertificate(rand.Reader, &template,
-		&template, &priv.PublicKey, priv)
+	derBytes, err := x509.CreateCertificate(rand.Reader, template,
+		template, &priv.PublicKey, priv)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create certificate: %v", err)
 	}
@@ -146,3 +126,35 @@ func NewTLSCertPair(organization string, validUntil time.Time, extraHosts []stri
 
 	return certBuf.Bytes(), keyBuf.Bytes(), nil
 }
+
+// certTemplate returns a certificate template with a random serial number,
+// valid from a day ago until validUntil.
+func certTemplate(organization, commonName string, validUntil time.Time) (*x509.Certificate, error) {
+	now := time.Now()
+	if validUntil.Before(now) {
+		return nil, ErrCertExpired
+	}
+
+	// end of ASN.1 time
+	endOfTime := time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC)
+	if validUntil.After(endOfTime) {
+		validUntil = endOfTime
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %s", err)
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{organization},
+			CommonName:   commonName,
+		},
+		NotBefore:             now.Add(-time.Hour * 24),
+		NotAfter:              validUntil,
+		BasicConstraintsValid: true,
+	}, nil
+}
@@ -120,4 +120,11 @@ func TestNewTLSCertPair(t *testing.T) {
 	if !x509Cert.BasicConstraintsValid {
 		t.Fatal("generated cert does not have valid basic constraints")
 	}
+
+	// Ensure an already-expired certificate is rejected.
+	_, _, err = bchutil.NewTLSCertPair(org, time.Now().Add(-time.Hour), nil)
+	if err != bchutil.ErrCertExpired {
+		t.Fatalf("expired cert: got error %v, want %v", err,
+			bchutil.ErrCertExpired)
+	}
 }
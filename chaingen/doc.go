@@ -0,0 +1,40 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package chaingen generates synthetic chains of valid blocks on the regression
+test network for use as test fixtures.
+
+# Overview
+
+A Generator mines blocks on top of the genesis block with the blocktemplate
+package.  Coinbases pay the configured addresses in turn and, once coinbases
+mature, each block includes transactions spending the oldest outputs of the
+chain which pay one of the configured keys:
+
+	g, err := chaingen.New(&chaingen.Config{SpendsPerBlock: 5})
+	if err != nil {
+		return err
+	}
+	blocks, err := g.Generate(150)
+	if err != nil {
+		return err
+	}
+
+When no addresses are configured, blocks pay a key derived from the seed of
+the configuration, so the outputs of every block are spent in later blocks.
+Generation is deterministic, so a configuration always yields the same chain.
+
+# Reorganizations
+
+Reorg forks the chain below its tip and mines a longer branch which replaces
+the disconnected blocks.  Blocks returns every generated block, including those
+of stale branches, while MainChain returns the blocks of the best chain.
+
+# Block Files
+
+WriteBlockFiles writes the generated blocks to blk*.dat files which can be read
+with the blockfile package or loaded by full nodes.
+*/
+package chaingen
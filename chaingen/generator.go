@@ -0,0 +1,390 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaingen
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gcash/bchd/bchec"
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blockfile"
+	"github.com/gcash/bchutil/blocktemplate"
+	"github.com/gcash/bchutil/txsign"
+)
+
+const (
+	// blockInterval is the time between the timestamps of consecutive
+	// generated blocks.
+	blockInterval = 10 * time.Minute
+
+	// spendFee is the fee paid by each generated spending transaction.
+	spendFee = 1000
+
+	// dustLimit is the smallest output value created by spending
+	// transactions, which keeps every output above the dust threshold of
+	// pay-to-pubkey-hash outputs.
+	dustLimit = 1000
+)
+
+// ErrBadReorg describes an error where a reorganization would fork below the
+// genesis block or not replace the blocks it disconnects.
+var ErrBadReorg = errors.New("invalid reorganization")
+
+// Config holds the settings of a Generator.
+type Config struct {
+	// Params are the parameters of the network, which must have an easily
+	// met proof of work limit.  When nil, chaincfg.RegressionNetParams is
+	// used.
+	Params *chaincfg.Params
+
+	// PayTo holds the addresses paid by coinbases and spending
+	// transactions in turn.  When empty, a pay-to-pubkey-hash address of
+	// a key derived from Seed is used and added to Keys.
+	PayTo []bchutil.Address
+
+	// Keys hold the private keys used to spend earlier outputs.  Only
+	// outputs paying pay-to-pubkey-hash addresses of these keys are spent.
+	Keys []*bchutil.WIF
+
+	// SpendsPerBlock is the maximum number of transactions spending
+	// earlier outputs included in each block.
+	SpendsPerBlock int
+
+	// Seed makes the key derived when PayTo is empty unique.
+	Seed []byte
+}
+
+// node is a generated block along with its parent.
+type node struct {
+	block  *bchutil.Block
+	parent *node
+	height int32
+	time   time.Time
+}
+
+// utxo is an unspent output of the chain.
+type utxo struct {
+	outPoint wire.OutPoint
+	txOut    *wire.TxOut
+	height   int32
+	coinbase bool
+}
+
+// Generator builds chains of valid blocks for use as test fixtures.  Each
+// block pays its subsidy and fees to the next PayTo address and, once
+// coinbases mature, includes transactions spending outputs of earlier blocks
+// which pay one of the Keys.  The generated blocks pass the sanity checks of
+// bchutil.Block and connect in a header chain.
+//
+// Generation is deterministic, so the same configuration always produces the
+// same blocks.
+type Generator struct {
+	params         *chaincfg.Params
+	payTo          []bchutil.Address
+	payToScripts   [][]byte
+	spendable      map[string]bool
+	signer         *txsign.Signer
+	spendsPerBlock int
+
+	genesis *node
+	tip     *node
+	blocks  []*bchutil.Block
+	txOuts  map[wire.OutPoint]*wire.TxOut
+
+	// utxos holds the unspent outputs of the chain ending at tip in the
+	// order they were created.
+	utxos     []*utxo
+	utxoIndex map[wire.OutPoint]*utxo
+}
+
+// New returns a Generator whose chain starts at the genesis block of the
+// network.
+func New(config *Config) (*Generator, error) {
+	params := config.Params
+	if params == nil {
+		params = &chaincfg.RegressionNetParams
+	}
+	payTo := config.PayTo
+	keys := config.Keys
+	if len(payTo) == 0 {
+		seed := sha256.Sum256(append([]byte("chaingen"), config.Seed...))
+		privKey, _ := bchec.PrivKeyFromBytes(bchec.S256(), seed[:])
+		wif, err := bchutil.NewWIF(privKey, params, true)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := bchutil.NewAddressPubKeyHash(bchutil.Hash160(
+			wif.SerializePubKey()), params)
+		if err != nil {
+			return nil, err
+		}
+		payTo = []bchutil.Address{addr}
+		keys = append(keys[:len(keys):len(keys)], wif)
+	}
+
+	g := &Generator{
+		params:         params,
+		spendable:      make(map[string]bool),
+		signer:         txsign.NewSigner(),
+		spendsPerBlock: config.SpendsPerBlock,
+		txOuts:         make(map[wire.OutPoint]*wire.TxOut),
+		utxoIndex:      make(map[wire.OutPoint]*utxo),
+	}
+	for _, addr := range payTo {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		g.payTo = append(g.payTo, addr)
+		g.payToScripts = append(g.payToScripts, pkScript)
+	}
+	for _, wif := range keys {
+		g.signer.AddWIF(wif)
+		pkScript, err := p2pkhScript(wif, params)
+		if err != nil {
+			return nil, err
+		}
+		g.spendable[string(pkScript)] = true
+	}
+
+	genesis := bchutil.NewBlock(params.GenesisBlock)
+	genesis.SetHeight(0)
+	g.genesis = &node{
+		block: genesis,
+		time:  params.GenesisBlock.Header.Timestamp,
+	}
+	g.tip = g.genesis
+	return g, nil
+}
+
+// Generate extends the chain by n blocks and returns them.
+func (g *Generator) Generate(n int) ([]*bchutil.Block, error) {
+	blocks := make([]*bchutil.Block, 0, n)
+	for i := 0; i < n; i++ {
+		block, err := g.nextBlock()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// Reorg forks the chain depth blocks below the tip and builds n blocks on the
+// fork, which replace the depth blocks of the former chain.  n must be
+// greater than depth so that the fork has more work.  The blocks of the fork
+// are returned.  When an error is returned the chain is left ending at the
+// former tip, although blocks generated on the fork before the error are still
+// included in Blocks.
+func (g *Generator) Reorg(depth, n int) ([]*bchutil.Block, error) {
+	if depth < 0 || depth > int(g.tip.height) || n <= depth {
+		return nil, fmt.Errorf("%w: depth %d, %d blocks at height %d",
+			ErrBadReorg, depth, n, g.tip.height)
+	}
+	forkPoint := g.tip
+	for i := 0; i < depth; i++ {
+		forkPoint = forkPoint.parent
+	}
+
+	// Rebuild the unspent outputs of the chain ending at the fork point.
+	// The outputs of the former chain are replaced rather than modified,
+	// so they are restored if the fork cannot be built.
+	tip, utxos, utxoIndex := g.tip, g.utxos, g.utxoIndex
+	var path []*node
+	for nd := forkPoint; nd != g.genesis; nd = nd.parent {
+		path = append(path, nd)
+	}
+	g.utxos = nil
+	g.utxoIndex = make(map[wire.OutPoint]*utxo)
+	for i := len(path) - 1; i >= 0; i-- {
+		g.connect(path[i])
+	}
+	g.tip = forkPoint
+
+	blocks, err := g.Generate(n)
+	if err != nil {
+		g.tip, g.utxos, g.utxoIndex = tip, utxos, utxoIndex
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Tip returns the block at the tip of the chain.  Before any blocks are
+// generated this is the genesis block.
+func (g *Generator) Tip() *bchutil.Block {
+	return g.tip.block
+}
+
+// Blocks returns every generated block, including those disconnected by
+// reorganizations, in the order they were generated.  The genesis block is
+// not included.
+func (g *Generator) Blocks() []*bchutil.Block {
+	return g.blocks
+}
+
+// MainChain returns the blocks of the chain ending at the tip in height
+// order, starting with the block at height one.
+func (g *Generator) MainChain() []*bchutil.Block {
+	blocks := make([]*bchutil.Block, g.tip.height)
+	for nd := g.tip; nd != g.genesis; nd = nd.parent {
+		blocks[nd.height-1] = nd.block
+	}
+	return blocks
+}
+
+// TxOut returns the output created by a generated block at the passed
+// outpoint, or nil if there is none.  It provides the previous outputs
+// needed to validate or compute statistics of the generated blocks.
+func (g *Generator) TxOut(outPoint wire.OutPoint) *wire.TxOut {
+	return g.txOuts[outPoint]
+}
+
+// WriteBlockFiles writes every generated block, in the order returned by
+// Blocks, to block files in dir.
+func (g *Generator) WriteBlockFiles(dir string) error {
+	w, err := blockfile.NewWriter(dir, g.params, 0)
+	if err != nil {
+		return err
+	}
+	for _, block := range g.blocks {
+		if _, err := w.WriteBlock(block); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// nextBlock builds the next block on the tip and connects it.
+func (g *Generator) nextBlock() (*bchutil.Block, error) {
+	height := g.tip.height + 1
+	timestamp := g.tip.time.Add(blockInterval)
+	txs, fees, err := g.spends(height)
+	if err != nil {
+		return nil, err
+	}
+
+	// The extra nonce and payout differ between the blocks of competing
+	// branches so that their coinbases differ.
+	count := len(g.blocks)
+	builder := &blocktemplate.Builder{
+		Params:     g.params,
+		PayTo:      g.payTo[count%len(g.payTo)],
+		ExtraNonce: uint64(count),
+	}
+	block, err := builder.Build(g.tip.block.Hash(), height, timestamp,
+		txs, fees)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &node{
+		block:  block,
+		parent: g.tip,
+		height: height,
+		time:   timestamp,
+	}
+	g.blocks = append(g.blocks, block)
+	g.connect(n)
+	g.tip = n
+	return block, nil
+}
+
+// spends returns the transactions of the block at height spending the oldest
+// spendable outputs of the chain, along with the fees they pay.
+func (g *Generator) spends(height int32) ([]*bchutil.Tx, bchutil.Amount, error) {
+	var txs []*bchutil.Tx
+	var fees bchutil.Amount
+	maturity := int32(g.params.CoinbaseMaturity)
+	for _, u := range g.utxos {
+		if len(txs) >= g.spendsPerBlock {
+			break
+		}
+		if g.utxoIndex[u.outPoint] != u ||
+			!g.spendable[string(u.txOut.PkScript)] ||
+			u.coinbase && height-u.height < maturity ||
+			u.txOut.Value < spendFee+dustLimit {
+
+			continue
+		}
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&u.outPoint, nil))
+		value := u.txOut.Value - spendFee
+		count := len(g.blocks) + len(txs)
+		if value >= 2*dustLimit {
+			tx.AddTxOut(wire.NewTxOut(value/2,
+				g.payToScripts[count%len(g.payTo)], wire.TokenData{}))
+			value -= value / 2
+			count++
+		}
+		tx.AddTxOut(wire.NewTxOut(value,
+			g.payToScripts[count%len(g.payTo)], wire.TokenData{}))
+
+		complete, err := g.signer.Sign(tx, []*wire.TxOut{u.txOut})
+		if err != nil {
+			return nil, 0, err
+		}
+		if !complete {
+			return nil, 0, fmt.Errorf("unable to sign spend of %v",
+				u.outPoint)
+		}
+		txs = append(txs, bchutil.NewTx(tx))
+		fees += spendFee
+	}
+	return txs, fees, nil
+}
+
+// connect updates the unspent outputs of the chain with the transactions of
+// the block of n.
+func (g *Generator) connect(n *node) {
+	for i, tx := range n.block.MsgBlock().Transactions {
+		if i > 0 {
+			for _, txIn := range tx.TxIn {
+				delete(g.utxoIndex, txIn.PreviousOutPoint)
+			}
+		}
+		txHash := tx.TxHash()
+		for idx, txOut := range tx.TxOut {
+			u := &utxo{
+				outPoint: *wire.NewOutPoint(&txHash, uint32(idx)),
+				txOut:    txOut,
+				height:   n.height,
+				coinbase: i == 0,
+			}
+			g.txOuts[u.outPoint] = txOut
+			g.utxoIndex[u.outPoint] = u
+			g.utxos = append(g.utxos, u)
+		}
+	}
+
+	// Drop spent outputs once they make up most of the list.
+	if len(g.utxos) > 2*len(g.utxoIndex) {
+		utxos := g.utxos[:0]
+		for _, u := range g.utxos {
+			if g.utxoIndex[u.outPoint] == u {
+				utxos = append(utxos, u)
+			}
+		}
+		g.utxos = utxos
+	}
+}
+
+// p2pkhScript returns the pay-to-pubkey-hash output script paying the public
+// key of wif.
+func p2pkhScript(wif *bchutil.WIF, params *chaincfg.Params) ([]byte, error) {
+	addr, err := bchutil.NewAddressPubKeyHash(bchutil.Hash160(
+		wif.SerializePubKey()), params)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
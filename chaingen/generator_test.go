@@ -0,0 +1,236 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaingen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blockfile"
+	"github.com/gcash/bchutil/chaingen"
+	"github.com/gcash/bchutil/header"
+	"github.com/gcash/bchutil/txsign"
+)
+
+// checkChain ensures blocks form a valid chain on params in which every
+// input spends an output created earlier in the chain which has not been
+// spent before.
+func checkChain(t *testing.T, g *chaingen.Generator, blocks []*bchutil.Block,
+	params *chaincfg.Params) int {
+
+	chain := header.NewChain(params)
+	spent := make(map[wire.OutPoint]bool)
+	created := make(map[wire.OutPoint]bool)
+	var spends int
+	for i, block := range blocks {
+		if err := block.CheckSanity(nil); err != nil {
+			t.Fatalf("CheckSanity #%d: unexpected error: %v", i, err)
+		}
+		if _, err := chain.Add(&block.MsgBlock().Header); err != nil {
+			t.Fatalf("Add #%d: unexpected error: %v", i, err)
+		}
+		height, err := block.CoinbaseHeight()
+		if err != nil || height != int32(i+1) {
+			t.Fatalf("CoinbaseHeight #%d: got %d (%v), want %d", i,
+				height, err, i+1)
+		}
+
+		for j, tx := range block.MsgBlock().Transactions {
+			if j > 0 {
+				prevOuts := make([]*wire.TxOut, len(tx.TxIn))
+				for k, txIn := range tx.TxIn {
+					op := txIn.PreviousOutPoint
+					if !created[op] || spent[op] {
+						t.Fatalf("block #%d: bad spend of %v",
+							i, op)
+					}
+					spent[op] = true
+					prevOuts[k] = g.TxOut(op)
+				}
+				if err := txsign.Verify(tx, prevOuts); err != nil {
+					t.Fatalf("Verify: block #%d tx %d: %v", i,
+						j, err)
+				}
+				spends++
+			}
+			txHash := tx.TxHash()
+			for k := range tx.TxOut {
+				created[*wire.NewOutPoint(&txHash, uint32(k))] = true
+			}
+		}
+	}
+	return spends
+}
+
+// TestGenerator ensures generated chains are valid, spend earlier outputs
+// and reorganize.
+func TestGenerator(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	g, err := chaingen.New(&chaingen.Config{SpendsPerBlock: 3})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	if !g.Tip().Hash().IsEqual(params.GenesisHash) {
+		t.Errorf("Tip: got %v, want genesis", g.Tip().Hash())
+	}
+
+	blocks, err := g.Generate(110)
+	if err != nil {
+		t.Fatalf("Generate: unexpected error: %v", err)
+	}
+	if len(blocks) != 110 || g.Tip() != blocks[109] {
+		t.Fatalf("Generate: got %d blocks", len(blocks))
+	}
+
+	// Coinbases mature after 100 blocks, so block 101 spends the first
+	// coinbase and later blocks also spend the outputs of earlier spends.
+	for i, block := range blocks {
+		want := 1
+		switch {
+		case i == 100:
+			want = 2
+		case i > 100:
+			want = 4
+		}
+		if len(block.Transactions()) != want {
+			t.Errorf("block %d: got %d transactions, want %d", i+1,
+				len(block.Transactions()), want)
+		}
+	}
+	if spends := checkChain(t, g, g.MainChain(), params); spends != 28 {
+		t.Errorf("got %d spends, want 28", spends)
+	}
+
+	// Replace the last five blocks with a branch of eight.
+	fork, err := g.Reorg(5, 8)
+	if err != nil {
+		t.Fatalf("Reorg: unexpected error: %v", err)
+	}
+	mainChain := g.MainChain()
+	if len(mainChain) != 113 || g.Tip() != fork[7] {
+		t.Fatalf("MainChain: got %d blocks, want 113", len(mainChain))
+	}
+	if *fork[0].Hash() == *blocks[105].Hash() {
+		t.Errorf("Reorg: fork repeats the disconnected block")
+	}
+	if fork[0].MsgBlock().Header.PrevBlock != *blocks[104].Hash() {
+		t.Errorf("Reorg: fork does not build on the fork point")
+	}
+	checkChain(t, g, mainChain, params)
+	if len(g.Blocks()) != 118 {
+		t.Errorf("Blocks: got %d, want 118", len(g.Blocks()))
+	}
+
+	// Every generated block connects to a header chain, which follows the
+	// reorganization.
+	chain := header.NewChain(params)
+	for _, block := range g.Blocks() {
+		if _, err := chain.Add(&block.MsgBlock().Header); err != nil {
+			t.Fatalf("Add: unexpected error: %v", err)
+		}
+	}
+	if chain.Tip().Hash != *g.Tip().Hash() {
+		t.Errorf("header chain tip: got %v, want %v", chain.Tip().Hash,
+			g.Tip().Hash())
+	}
+
+	if _, err := g.Reorg(3, 3); !errors.Is(err, chaingen.ErrBadReorg) {
+		t.Errorf("Reorg: unexpected error for a fork without more "+
+			"blocks - got %v, want %v", err, chaingen.ErrBadReorg)
+	}
+	if _, err := g.Reorg(200, 201); !errors.Is(err, chaingen.ErrBadReorg) {
+		t.Errorf("Reorg: unexpected error for a fork below genesis - "+
+			"got %v, want %v", err, chaingen.ErrBadReorg)
+	}
+}
+
+// TestGeneratorPayTo ensures coinbases pay the configured addresses in turn
+// and generation is deterministic.
+func TestGeneratorPayTo(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	var payTo []bchutil.Address
+	for i := byte(1); i <= 3; i++ {
+		hash := make([]byte, 20)
+		hash[0] = i
+		addr, err := bchutil.NewAddressPubKeyHash(hash, params)
+		if err != nil {
+			t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+		}
+		payTo = append(payTo, addr)
+	}
+
+	var hashes [2][]string
+	for run := range hashes {
+		g, err := chaingen.New(&chaingen.Config{
+			PayTo:          payTo,
+			SpendsPerBlock: 1,
+		})
+		if err != nil {
+			t.Fatalf("New: unexpected error: %v", err)
+		}
+		blocks, err := g.Generate(6)
+		if err != nil {
+			t.Fatalf("Generate: unexpected error: %v", err)
+		}
+		for i, block := range blocks {
+			payouts, err := block.CoinbasePayouts(params)
+			if err != nil {
+				t.Fatalf("CoinbasePayouts: unexpected error: %v", err)
+			}
+			want := payTo[i%len(payTo)].String()
+			if len(payouts) != 1 || payouts[0].Address.String() != want {
+				t.Errorf("block %d: got payouts %v, want %v", i+1,
+					payouts, want)
+			}
+			hashes[run] = append(hashes[run], block.Hash().String())
+		}
+	}
+	for i := range hashes[0] {
+		if hashes[0][i] != hashes[1][i] {
+			t.Errorf("block %d: got %v and %v from identical "+
+				"generators", i+1, hashes[0][i], hashes[1][i])
+		}
+	}
+}
+
+// TestWriteBlockFiles ensures generated blocks are written to block files.
+func TestWriteBlockFiles(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	g, err := chaingen.New(&chaingen.Config{})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	if _, err := g.Generate(10); err != nil {
+		t.Fatalf("Generate: unexpected error: %v", err)
+	}
+	if _, err := g.Reorg(2, 3); err != nil {
+		t.Fatalf("Reorg: unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := g.WriteBlockFiles(dir); err != nil {
+		t.Fatalf("WriteBlockFiles: unexpected error: %v", err)
+	}
+	r, err := blockfile.NewReader(dir, params)
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	defer r.Close()
+	blocks := g.Blocks()
+	var i int
+	for ; r.Next(); i++ {
+		if *r.Block().Hash() != *blocks[i].Hash() {
+			t.Errorf("block #%d: got %v, want %v", i, r.Block().Hash(),
+				blocks[i].Hash())
+		}
+	}
+	if err := r.Err(); err != nil || i != len(blocks) {
+		t.Errorf("Next: read %d blocks (%v), want %d", i, err,
+			len(blocks))
+	}
+}
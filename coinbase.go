@@ -0,0 +1,259 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/gcash/bchd/chaincfg"
+)
+
+const (
+	// serializedHeightVersion is the block version which changed block
+	// coinbases to start with the serialized block height.
+	serializedHeightVersion = 2
+
+	// baseSubsidy is the starting subsidy amount for mined blocks.  This
+	// value is halved every SubsidyReductionInterval blocks.
+	baseSubsidy = 50 * SatoshiPerBitcoin
+
+	// minTagLen is the minimum length of a run of printable characters in
+	// a coinbase signature script for it to be considered part of the
+	// miner tag.
+	minTagLen = 4
+)
+
+// CoinbasePayout is an output of a coinbase transaction.
+type CoinbasePayout struct {
+	// Address is the address paid by the output, or nil if the output
+	// script is not one of the standard pay-to-pubkey, pay-to-pubkey-hash
+	// or pay-to-script-hash forms.
+	Address Address
+
+	// Amount is the value of the output.
+	Amount Amount
+
+	// PkScript is the public key script of the output.
+	PkScript []byte
+}
+
+// ExtractCoinbaseHeight attempts to extract the height of the block from the
+// signature script of a coinbase transaction.  Coinbase heights are only
+// present in blocks of version 2 or later.  This was added as part of BIP0034.
+func ExtractCoinbaseHeight(coinbaseTx *Tx) (int32, error) {
+	msgTx := coinbaseTx.MsgTx()
+	if len(msgTx.TxIn) == 0 || len(msgTx.TxIn[0].SignatureScript) < 1 {
+		return 0, blockError(ErrMissingCoinbaseHeight, "the coinbase "+
+			"signature script must start with the length of the "+
+			"serialized block height")
+	}
+	sigScript := msgTx.TxIn[0].SignatureScript
+
+	// Detect the case when the block height is a small integer encoded with
+	// a single byte.
+	const op0, op1, op16 = 0x00, 0x51, 0x60
+	opcode := sigScript[0]
+	if opcode == op0 {
+		return 0, nil
+	}
+	if opcode >= op1 && opcode <= op16 {
+		return int32(opcode - (op1 - 1)), nil
+	}
+
+	// Otherwise, the opcode is the length of the following bytes which
+	// encode in the block height.
+	serializedLen := int(sigScript[0])
+	if serializedLen > 8 || len(sigScript[1:]) < serializedLen {
+		return 0, blockError(ErrMissingCoinbaseHeight, "the coinbase "+
+			"signature script must start with the serialized block "+
+			"height")
+	}
+
+	serializedHeightBytes := make([]byte, 8)
+	copy(serializedHeightBytes, sigScript[1:serializedLen+1])
+	serializedHeight := binary.LittleEndian.Uint64(serializedHeightBytes)
+
+	return int32(serializedHeight), nil
+}
+
+// CalcBlockSubsidy returns the subsidy amount a block at the provided height
+// should have.  This is mainly used for determining how much the coinbase for
+// newly generated blocks awards as well as validating the coinbase for blocks
+// has the expected value.
+//
+// The subsidy is halved every SubsidyReductionInterval blocks.  Mathematically
+// this is: baseSubsidy / 2^(height/SubsidyReductionInterval)
+func CalcBlockSubsidy(height int32, params *chaincfg.Params) Amount {
+	if params.SubsidyReductionInterval == 0 {
+		return baseSubsidy
+	}
+	halvings := uint(height / params.SubsidyReductionInterval)
+	if halvings >= 64 {
+		return 0
+	}
+
+	// Equivalent to: baseSubsidy / 2^(height/subsidyHalvingInterval)
+	return baseSubsidy >> halvings
+}
+
+// CoinbaseTag returns the readable text a miner placed in the coinbase
+// signature script of the block, such as a pool name.  The serialized block
+// height is skipped and runs of printable ASCII characters are joined with
+// single spaces, which drops extra nonces and other binary data.
+func (b *Block) CoinbaseTag() (string, error) {
+	coinbase, err := b.coinbase()
+	if err != nil {
+		return "", err
+	}
+	sigScript := coinbase.MsgTx().TxIn[0].SignatureScript
+
+	// Skip the serialized height push of blocks which commit to one.
+	if b.msgBlock.Header.Version >= serializedHeightVersion &&
+		len(sigScript) > 0 {
+
+		skip := 1
+		if n := int(sigScript[0]); n <= 8 {
+			skip += n
+		}
+		if skip > len(sigScript) {
+			skip = len(sigScript)
+		}
+		sigScript = sigScript[skip:]
+	}
+
+	var runs []string
+	start := -1
+	for i := 0; i <= len(sigScript); i++ {
+		printable := i < len(sigScript) && sigScript[i] >= 0x20 &&
+			sigScript[i] <= 0x7e
+		switch {
+		case printable && start < 0:
+			start = i
+		case !printable && start >= 0:
+			run := strings.TrimSpace(string(sigScript[start:i]))
+			if len(run) >= minTagLen {
+				runs = append(runs, run)
+			}
+			start = -1
+		}
+	}
+	return strings.Join(runs, " "), nil
+}
+
+// CoinbasePayouts returns the outputs of the coinbase transaction of the block
+// along with the address each pays on the passed network.
+func (b *Block) CoinbasePayouts(params *chaincfg.Params) ([]CoinbasePayout, error) {
+	coinbase, err := b.coinbase()
+	if err != nil {
+		return nil, err
+	}
+	txOuts := coinbase.MsgTx().TxOut
+	payouts := make([]CoinbasePayout, len(txOuts))
+	for i, txOut := range txOuts {
+		payouts[i] = CoinbasePayout{
+			Address:  extractPkScriptAddr(txOut.PkScript, params),
+			Amount:   Amount(txOut.Value),
+			PkScript: txOut.PkScript,
+		}
+	}
+	return payouts, nil
+}
+
+// CoinbaseReward splits the total value claimed by the coinbase transaction of
+// the block into the block subsidy and the transaction fees.  The height of
+// the block is taken from Height when it has been set and from the coinbase
+// otherwise.  A coinbase which claims less than the subsidy reports no fees.
+func (b *Block) CoinbaseReward(params *chaincfg.Params) (subsidy, fees Amount, err error) {
+	height := b.Height()
+	if height == BlockHeightUnknown {
+		height, err = b.CoinbaseHeight()
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	coinbase, err := b.coinbase()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total Amount
+	for _, txOut := range coinbase.MsgTx().TxOut {
+		total += Amount(txOut.Value)
+	}
+	subsidy = CalcBlockSubsidy(height, params)
+	if total > subsidy {
+		fees = total - subsidy
+	}
+	return subsidy, fees, nil
+}
+
+// coinbase returns the coinbase transaction of the block.
+func (b *Block) coinbase() (*Tx, error) {
+	if len(b.msgBlock.Transactions) == 0 {
+		return nil, blockError(ErrNoTransactions, "block does not "+
+			"contain any transactions")
+	}
+	if !isCoinBaseTx(b.msgBlock.Transactions[0]) {
+		return nil, blockError(ErrFirstTxNotCoinbase, "first "+
+			"transaction in block is not a coinbase")
+	}
+	return b.Tx(0)
+}
+
+// extractPkScriptAddr returns the address paid by a standard pay-to-pubkey,
+// pay-to-pubkey-hash or pay-to-script-hash public key script, or nil if the
+// script is not one of these forms.  The script templates are matched here
+// because the txscript package cannot be imported without an import cycle.
+func extractPkScriptAddr(pkScript []byte, params *chaincfg.Params) Address {
+	const (
+		opData20      = 0x14
+		opData32      = 0x20
+		opData33      = 0x21
+		opData65      = 0x41
+		opDup         = 0x76
+		opEqual       = 0x87
+		opEqualVerify = 0x88
+		opHash160     = 0xa9
+		opHash256     = 0xaa
+		opCheckSig    = 0xac
+	)
+
+	var addr Address
+	var err error
+	switch n := len(pkScript); {
+	// OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+	case n == 25 && pkScript[0] == opDup && pkScript[1] == opHash160 &&
+		pkScript[2] == opData20 && pkScript[23] == opEqualVerify &&
+		pkScript[24] == opCheckSig:
+
+		addr, err = NewAddressPubKeyHash(pkScript[3:23], params)
+
+	// OP_HASH160 <20 bytes> OP_EQUAL
+	case n == 23 && pkScript[0] == opHash160 && pkScript[1] == opData20 &&
+		pkScript[22] == opEqual:
+
+		addr, err = NewAddressScriptHashFromHash(pkScript[2:22], params)
+
+	// OP_HASH256 <32 bytes> OP_EQUAL
+	case n == 35 && pkScript[0] == opHash256 && pkScript[1] == opData32 &&
+		pkScript[34] == opEqual:
+
+		addr, err = NewAddressScriptHash32FromHash(pkScript[2:34], params)
+
+	// <33 or 65 byte public key> OP_CHECKSIG
+	case (n == 35 && pkScript[0] == opData33 ||
+		n == 67 && pkScript[0] == opData65) && pkScript[n-1] == opCheckSig:
+
+		addr, err = NewAddressPubKey(pkScript[1:n-1], params)
+
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return addr
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+)
+
+// newCoinbaseBlock returns a version 4 block whose coinbase has the passed
+// signature script and outputs.
+func newCoinbaseBlock(sigScript []byte, txOuts ...*wire.TxOut) *bchutil.Block {
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), sigScript))
+	coinbase.TxOut = txOuts
+	return bchutil.NewBlock(&wire.MsgBlock{
+		Header:       wire.BlockHeader{Version: 4},
+		Transactions: []*wire.MsgTx{coinbase},
+	})
+}
+
+// TestCoinbase ensures the height, tag, payouts and reward are extracted from
+// a coinbase transaction.
+func TestCoinbase(t *testing.T) {
+	pkHash := bytes.Repeat([]byte{0x01}, 20)
+	p2pkh := append([]byte{0x76, 0xa9, 0x14}, pkHash...)
+	p2pkh = append(p2pkh, 0x88, 0xac)
+	nullData := []byte{0x6a, 0x04, 0xde, 0xad, 0xbe, 0xef}
+
+	// Height 800000, a pool tag and an extra nonce.
+	sigScript := []byte{0x03, 0x00, 0x35, 0x0c, 0x16}
+	sigScript = append(sigScript, "/ViaBTC/Mined by test/"...)
+	sigScript = append(sigScript, 0x08, 0x00, 0xff, 0x01, 0x7f, 0x10, 0x00,
+		0x00, 0x00)
+	block := newCoinbaseBlock(sigScript,
+		wire.NewTxOut(625000000+12345, p2pkh, wire.TokenData{}),
+		wire.NewTxOut(0, nullData, wire.TokenData{}))
+
+	height, err := block.CoinbaseHeight()
+	if err != nil || height != 800000 {
+		t.Errorf("CoinbaseHeight: got %d (%v), want 800000", height, err)
+	}
+	tag, err := block.CoinbaseTag()
+	if err != nil || tag != "/ViaBTC/Mined by test/" {
+		t.Errorf("CoinbaseTag: got %q (%v), want %q", tag, err,
+			"/ViaBTC/Mined by test/")
+	}
+
+	payouts, err := block.CoinbasePayouts(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("CoinbasePayouts: unexpected error: %v", err)
+	}
+	wantAddr, _ := bchutil.NewAddressPubKeyHash(pkHash,
+		&chaincfg.MainNetParams)
+	if len(payouts) != 2 {
+		t.Fatalf("CoinbasePayouts: got %d payouts, want 2", len(payouts))
+	}
+	if payouts[0].Address == nil ||
+		payouts[0].Address.String() != wantAddr.String() ||
+		payouts[0].Amount != 625012345 {
+
+		t.Errorf("CoinbasePayouts: unexpected payout %v", payouts[0])
+	}
+	if payouts[1].Address != nil || payouts[1].Amount != 0 ||
+		!bytes.Equal(payouts[1].PkScript, nullData) {
+
+		t.Errorf("CoinbasePayouts: unexpected payout %v", payouts[1])
+	}
+
+	// The reward uses the coinbase height until a height is set.
+	subsidy, fees, err := block.CoinbaseReward(&chaincfg.MainNetParams)
+	if err != nil || subsidy != 625000000 || fees != 12345 {
+		t.Errorf("CoinbaseReward: got subsidy %d fees %d (%v), want "+
+			"subsidy 625000000 fees 12345", subsidy, fees, err)
+	}
+	block.SetHeight(100)
+	subsidy, fees, err = block.CoinbaseReward(&chaincfg.MainNetParams)
+	if err != nil || subsidy != 5000000000 || fees != 0 {
+		t.Errorf("CoinbaseReward: got subsidy %d fees %d (%v), want "+
+			"subsidy 5000000000 fees 0", subsidy, fees, err)
+	}
+}
+
+// TestCoinbaseBlock100000 ensures a version 1 block reports that it does not
+// commit to its height while its payouts are still extracted.
+func TestCoinbaseBlock100000(t *testing.T) {
+	block := bchutil.NewBlock(&Block100000)
+
+	_, err := block.CoinbaseHeight()
+	berr, ok := err.(bchutil.BlockError)
+	if !ok || berr.ErrorCode != bchutil.ErrMissingCoinbaseHeight {
+		t.Errorf("CoinbaseHeight: unexpected error - got %v, want %v",
+			err, bchutil.ErrMissingCoinbaseHeight)
+	}
+	if _, _, err := block.CoinbaseReward(&chaincfg.MainNetParams); err == nil {
+		t.Errorf("CoinbaseReward: expected error without height")
+	}
+
+	payouts, err := block.CoinbasePayouts(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("CoinbasePayouts: unexpected error: %v", err)
+	}
+	pubKeyAddr, ok := payouts[0].Address.(*bchutil.AddressPubKey)
+	if len(payouts) != 1 || !ok || payouts[0].Amount != 5000000000 {
+		t.Fatalf("CoinbasePayouts: unexpected payouts %v", payouts)
+	}
+	pkScript := Block100000.Transactions[0].TxOut[0].PkScript
+	if !bytes.Equal(pubKeyAddr.ScriptAddress(), pkScript[1:66]) {
+		t.Errorf("CoinbasePayouts: unexpected public key %x",
+			pubKeyAddr.ScriptAddress())
+	}
+
+	block.SetHeight(100000)
+	subsidy, fees, err := block.CoinbaseReward(&chaincfg.MainNetParams)
+	if err != nil || subsidy != 5000000000 || fees != 0 {
+		t.Errorf("CoinbaseReward: got subsidy %d fees %d (%v), want "+
+			"subsidy 5000000000 fees 0", subsidy, fees, err)
+	}
+}
+
+// TestExtractCoinbaseHeight ensures heights are extracted from the possible
+// encodings of BIP0034 coinbase signature scripts.
+func TestExtractCoinbaseHeight(t *testing.T) {
+	tests := []struct {
+		name      string
+		sigScript []byte
+		height    int32
+		valid     bool
+	}{
+		{"OP_0", []byte{0x00, 0x01}, 0, true},
+		{"OP_1", []byte{0x51}, 1, true},
+		{"OP_16", []byte{0x60}, 16, true},
+		{"one byte", []byte{0x01, 0x11}, 17, true},
+		{"three bytes", []byte{0x03, 0x40, 0x0d, 0x03}, 200000, true},
+		{"empty", nil, 0, false},
+		{"truncated", []byte{0x03, 0x40, 0x0d}, 0, false},
+		{"oversized", append([]byte{0x09}, make([]byte, 9)...), 0, false},
+	}
+
+	for _, test := range tests {
+		block := newCoinbaseBlock(test.sigScript)
+		coinbase, _ := block.Tx(0)
+		height, err := bchutil.ExtractCoinbaseHeight(coinbase)
+		if test.valid != (err == nil) {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if height != test.height {
+			t.Errorf("%s: got height %d, want %d", test.name, height,
+				test.height)
+		}
+	}
+}
+
+// TestCalcBlockSubsidy ensures the subsidy halves every reduction interval.
+func TestCalcBlockSubsidy(t *testing.T) {
+	tests := []struct {
+		height  int32
+		subsidy bchutil.Amount
+	}{
+		{0, 5000000000},
+		{209999, 5000000000},
+		{210000, 2500000000},
+		{840000, 312500000},
+		{6720000, 1},
+		{6930000, 0},
+		{13440000, 0},
+	}
+
+	for _, test := range tests {
+		subsidy := bchutil.CalcBlockSubsidy(test.height,
+			&chaincfg.MainNetParams)
+		if subsidy != test.subsidy {
+			t.Errorf("CalcBlockSubsidy(%d): got %d, want %d",
+				test.height, subsidy, test.subsidy)
+		}
+	}
+}
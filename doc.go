@@ -12,6 +12,39 @@ manipulation of raw wire protocol blocks.  It also memoizes hashes for the
 block and its transactions on their first access so subsequent accesses don't
 have to repeat the relatively expensive hashing operations.
 
+A Block can also compute and verify its merkle root and perform context free
+sanity checks on its structure with CheckSanity, which reports violations as
+a BlockError.
+
+The coinbase transaction of a Block can be inspected for the block height it
+commits to under BIP0034, the tag left by the miner, the addresses it pays and
+the split of its value between the block subsidy and transaction fees.  The
+coinbase height lets a block deserialized from bytes learn its own height.
+
+The memoized values of a Block and its transactions are safe for concurrent
+access.  HashTransactions hashes every transaction of a block across a pool of
+goroutines, which is useful before indexing the transactions of large blocks.
+
+A BlockReader walks the transactions of a serialized block without decoding
+the whole block.  It yields the location, raw bytes and hash of each
+transaction and only decodes a transaction into a Tx when asked, which avoids
+large allocations when indexing big blocks:
+
+	r, err := bchutil.NewBlockReader(serializedBlock)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+		if !interesting(r.TxHash()) {
+			continue
+		}
+		tx, err := r.Tx()
+		...
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
 # Tx Overview
 
 A Tx defines a bitcoin cash transaction that provides more efficient manipulation of
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package gateway provides an HTTP/JSON gateway to gRPC services such as bchrpc,
+for clients which cannot speak gRPC directly.
+
+# Overview
+
+A Gateway is an http.Handler which forwards requests to the unary and server
+streaming methods of the configured services over a gRPC connection.  The
+methods are found from the service descriptors in the global protobuf
+registry, so importing the generated package of a service is all that is
+needed to expose it:
+
+	g, err := gateway.New(&gateway.Config{
+		Conn:     conn,
+		Services: []string{"pb.bchrpc"},
+	})
+	if err != nil {
+		return err
+	}
+	http.Handle("/", g)
+
+Each method is served at the path of its gRPC method name, such as
+/pb.bchrpc/GetBlockInfo.  Requests and responses are JSON in the form of the
+jsonpb package, with bytes as hex and hashes in reversed byte order:
+
+	POST /pb.bchrpc/GetBlockInfo
+	{"hash":"000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"}
+
+The request of a GET request is taken from its request query parameter.
+Errors returned by the server are mapped to the matching HTTP status with a
+body holding the gRPC code and message.
+
+HTTP headers are not forwarded to the gRPC server unless named in the
+ForwardHeaders of the Config, which forwards them as request metadata.  A
+gateway in front of a bchd requiring an authentication token forwards the
+header holding it:
+
+	g, err := gateway.New(&gateway.Config{
+		Conn:           conn,
+		Services:       []string{"pb.bchrpc"},
+		ForwardHeaders: []string{"AuthenticationToken"},
+	})
+
+# Streams
+
+Server streaming methods, such as the bchrpc block and transaction
+subscriptions, are served as server-sent events so they can be consumed by an
+EventSource in a browser.  Each message is sent as a data event holding one
+line of JSON.  An end event follows the last message when the server closes
+the stream, and an error event describes a stream which fails.  The gRPC stream
+is canceled when the HTTP client disconnects.
+*/
+package gateway
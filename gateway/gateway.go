@@ -0,0 +1,398 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gcash/bchutil/jsonpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// DefaultMaxRequestSize is the default limit on the size of request bodies.
+const DefaultMaxRequestSize = 1 << 20
+
+// requestParam is the query parameter holding the JSON request of a GET
+// request.
+const requestParam = "request"
+
+var (
+	// ErrNoConn describes an error where a gateway is configured without a
+	// gRPC connection.
+	ErrNoConn = errors.New("no gRPC connection")
+
+	// ErrUnknownService describes an error where a service to expose is not
+	// in the global protobuf registry.
+	ErrUnknownService = errors.New("unknown gRPC service")
+)
+
+// Config is the configuration of a Gateway.
+type Config struct {
+	// Conn is the connection to the gRPC server which requests are
+	// forwarded to.
+	Conn grpc.ClientConnInterface
+
+	// Services holds the full names of the services to expose, such as
+	// pb.bchrpc.  Their descriptors are looked up in the global protobuf
+	// registry, which the generated code of a service registers with when
+	// its package is imported.
+	Services []string
+
+	// Marshaler renders response messages as JSON.  When nil, the default
+	// options are used.  Responses are written on a single line unless an
+	// indent is set, and streamed messages always are.
+	Marshaler *jsonpb.MarshalerV2
+
+	// Unmarshaler parses request messages from JSON.  When nil, the
+	// default options are used, so unknown fields are rejected.
+	Unmarshaler *jsonpb.UnmarshalerV2
+
+	// MaxRequestSize limits the size of request bodies in bytes.  When
+	// zero, DefaultMaxRequestSize is used.
+	MaxRequestSize int64
+
+	// ForwardHeaders holds the names of the HTTP request headers forwarded
+	// to the gRPC server as request metadata, such as the
+	// AuthenticationToken header of an authenticated bchd.  Metadata keys
+	// are the lowercase header names.  Other headers are not forwarded.
+	ForwardHeaders []string
+}
+
+// method describes a gRPC method exposed by a Gateway.
+type method struct {
+	name          string
+	input         protoreflect.MessageType
+	output        protoreflect.MessageType
+	serverStreams bool
+}
+
+// Gateway is an http.Handler exposing the unary and server streaming methods of
+// gRPC services as JSON over HTTP.
+//
+// Each method is served at the path of its gRPC method name, such as
+// /pb.bchrpc/GetBlockInfo.  The request message is read from the JSON body of
+// a POST request or from the request query parameter of a GET request, and an
+// empty body or missing parameter is an empty request.  Messages are rendered
+// in the hex form of the jsonpb package.
+//
+// Unary methods respond with the JSON response message.  Server streaming
+// methods respond with a stream of server-sent events, one data event per
+// message, ending with an end event once the server closes the stream or an
+// error event if it fails.  Methods streaming from the client are not exposed.
+type Gateway struct {
+	conn        grpc.ClientConnInterface
+	marshaler   *jsonpb.MarshalerV2
+	unmarshaler *jsonpb.UnmarshalerV2
+	maxSize     int64
+	headers     []string
+	methods     map[string]*method
+}
+
+// New returns a Gateway exposing the services described by config.
+func New(config *Config) (*Gateway, error) {
+	if config.Conn == nil {
+		return nil, ErrNoConn
+	}
+
+	g := &Gateway{
+		conn:        config.Conn,
+		marshaler:   config.Marshaler,
+		unmarshaler: config.Unmarshaler,
+		maxSize:     config.MaxRequestSize,
+		methods:     make(map[string]*method),
+	}
+	if g.marshaler == nil {
+		g.marshaler = new(jsonpb.MarshalerV2)
+	}
+	if g.unmarshaler == nil {
+		g.unmarshaler = new(jsonpb.UnmarshalerV2)
+	}
+	if g.maxSize == 0 {
+		g.maxSize = DefaultMaxRequestSize
+	}
+	for _, name := range config.ForwardHeaders {
+		g.headers = append(g.headers, http.CanonicalHeaderKey(name))
+	}
+
+	for _, name := range config.Services {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(
+			protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s", ErrUnknownService, name)
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%v: %s is not a service",
+				ErrUnknownService, name)
+		}
+
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			md := methods.Get(i)
+			if md.IsStreamingClient() {
+				continue
+			}
+			input, err := protoregistry.GlobalTypes.FindMessageByName(
+				md.Input().FullName())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", md.FullName(), err)
+			}
+			output, err := protoregistry.GlobalTypes.FindMessageByName(
+				md.Output().FullName())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", md.FullName(), err)
+			}
+
+			m := &method{
+				name:          fmt.Sprintf("/%s/%s", sd.FullName(), md.Name()),
+				input:         input,
+				output:        output,
+				serverStreams: md.IsStreamingServer(),
+			}
+			g.methods[m.name] = m
+		}
+	}
+	return g, nil
+}
+
+// Methods returns the paths of the methods exposed by the gateway in sorted
+// order.
+func (g *Gateway) Methods() []string {
+	names := make([]string, 0, len(g.methods))
+	for name := range g.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP forwards an HTTP request to the gRPC method at its path.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m, ok := g.methods[r.URL.Path]
+	if !ok {
+		writeError(w, status.New(codes.NotFound, "unknown method"))
+		return
+	}
+
+	var body io.Reader
+	switch r.Method {
+	case http.MethodPost:
+		body = http.MaxBytesReader(w, r.Body, g.maxSize)
+	case http.MethodGet:
+		body = strings.NewReader(r.URL.Query().Get(requestParam))
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorStatus(w, http.StatusMethodNotAllowed,
+			status.New(codes.Unimplemented, "method not allowed"))
+		return
+	}
+
+	req := m.input.New().Interface()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		writeError(w, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+	if len(bytes.TrimSpace(data)) != 0 {
+		err := g.unmarshaler.Unmarshal(bytes.NewReader(data), req)
+		if err != nil {
+			writeError(w, status.New(codes.InvalidArgument, err.Error()))
+			return
+		}
+	}
+
+	ctx := g.outgoingContext(r)
+	if m.serverStreams {
+		g.serveStream(ctx, w, m, req)
+		return
+	}
+
+	resp := m.output.New().Interface()
+	if err := g.conn.Invoke(ctx, m.name, req, resp); err != nil {
+		writeError(w, status.Convert(err))
+		return
+	}
+	data, err = g.marshal(resp, g.marshaler.Indent == "")
+	if err != nil {
+		writeError(w, status.New(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// outgoingContext returns the context of an HTTP request carrying the values of
+// the headers forwarded by the gateway as outgoing gRPC metadata.
+func (g *Gateway) outgoingContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if len(g.headers) == 0 {
+		return ctx
+	}
+	md := make(metadata.MD)
+	for _, name := range g.headers {
+		if values := r.Header.Values(name); len(values) != 0 {
+			md.Append(name, values...)
+		}
+	}
+	if len(md) == 0 {
+		return ctx
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// marshal renders msg as JSON, on a single line when compact is set.
+func (g *Gateway) marshal(msg protoreflect.ProtoMessage, compact bool) ([]byte, error) {
+	s, err := g.marshaler.MarshalToString(msg)
+	if err != nil || !compact {
+		return []byte(s), err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// serveStream forwards a request to a server streaming method and writes each
+// message the server sends as a server-sent event.  The stream is canceled
+// when ctx, the context of the HTTP request, is done as the client goes away.
+func (g *Gateway) serveStream(ctx context.Context, w http.ResponseWriter,
+	m *method, req protoreflect.ProtoMessage) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, status.New(codes.Internal, "streaming unsupported"))
+		return
+	}
+
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	stream, err := g.conn.NewStream(ctx, desc, m.name)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err != nil {
+		writeError(w, status.Convert(err))
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		msg := m.output.New().Interface()
+		err := stream.RecvMsg(msg)
+		if err == io.EOF {
+			io.WriteString(w, "event: end\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			writeEvent(w, "error", errorJSON(status.Convert(err)))
+			flusher.Flush()
+			return
+		}
+
+		data, err := g.marshal(msg, true)
+		if err != nil {
+			writeEvent(w, "error", errorJSON(status.New(codes.Internal,
+				err.Error())))
+			flusher.Flush()
+			return
+		}
+		if err := writeEvent(w, "", data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a server-sent event with the passed type, which is omitted
+// when empty, and single line data.
+func writeEvent(w io.Writer, event string, data []byte) error {
+	var buf bytes.Buffer
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	buf.WriteString("data: ")
+	buf.Write(data)
+	buf.WriteString("\n\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// errorJSON returns the JSON body describing a gRPC status.
+func errorJSON(s *status.Status) []byte {
+	b, _ := json.Marshal(struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+	}{s.Code(), s.Message()})
+	return b
+}
+
+// writeError writes a gRPC status as an HTTP error response with the HTTP
+// status matching its code.
+func writeError(w http.ResponseWriter, s *status.Status) {
+	writeErrorStatus(w, HTTPStatus(s.Code()), s)
+}
+
+// writeErrorStatus writes a gRPC status as an HTTP error response with the
+// passed HTTP status.
+func writeErrorStatus(w http.ResponseWriter, httpStatus int, s *status.Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(errorJSON(s))
+}
+
+// HTTPStatus returns the HTTP status code matching a gRPC status code.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// Client closed request, as used by nginx.
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package gateway_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchutil/gateway"
+	"github.com/gcash/bchutil/jsonpb"
+	pb "github.com/gcash/bchutil/jsonpb/testpb"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // testpb is generated with github.com/golang/protobuf
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testServer implements the bchrpc methods used by the tests.  Calling any
+// other method panics.
+type testServer struct {
+	pb.BchrpcServer
+
+	info       *pb.BlockInfo
+	streamErr  error
+	blockCount int
+
+	// token, when set, must be sent as the authenticationtoken metadata
+	// of every call.
+	token string
+}
+
+// checkToken returns an error when the token of the server is not in the
+// metadata of the call with the passed context.
+func (s *testServer) checkToken(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if tokens := md.Get("authenticationtoken"); len(tokens) != 1 ||
+		tokens[0] != s.token {
+
+		return status.Error(codes.Unauthenticated, "bad token")
+	}
+	return nil
+}
+
+func (s *testServer) GetBlockInfo(ctx context.Context,
+	req *pb.GetBlockInfoRequest) (*pb.GetBlockInfoResponse, error) {
+
+	if err := s.checkToken(ctx); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(req.GetHash(), s.info.Hash) {
+		return nil, status.Error(codes.NotFound, "block not found")
+	}
+	return &pb.GetBlockInfoResponse{Info: s.info}, nil
+}
+
+func (s *testServer) SubscribeBlocks(req *pb.SubscribeBlocksRequest,
+	stream pb.Bchrpc_SubscribeBlocksServer) error {
+
+	if err := s.checkToken(stream.Context()); err != nil {
+		return err
+	}
+	for i := 0; i < s.blockCount; i++ {
+		info := *s.info
+		info.Height = int32(i)
+		err := stream.Send(&pb.BlockNotification{
+			Type:  pb.BlockNotification_CONNECTED,
+			Block: &info,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.streamErr
+}
+
+// newGateway starts an in-process gRPC server for srv and returns the URL of
+// an HTTP server exposing it through a gateway which forwards the passed
+// headers.
+func newGateway(t *testing.T, srv *testServer,
+	forwardHeaders ...string) (string, *gateway.Gateway) {
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterBchrpcServer(grpcServer, srv)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient: unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	g, err := gateway.New(&gateway.Config{
+		Conn:           conn,
+		Services:       []string{"pb.bchrpc"},
+		ForwardHeaders: forwardHeaders,
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	httpServer := httptest.NewServer(g)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL, g
+}
+
+// genesisInfo returns the block info of the mainnet genesis block.
+func genesisInfo() *pb.BlockInfo {
+	hdr := &chaincfg.MainNetParams.GenesisBlock.Header
+	hash := hdr.BlockHash()
+	return &pb.BlockInfo{
+		Hash:          hash.CloneBytes(),
+		Version:       hdr.Version,
+		PreviousBlock: hdr.PrevBlock.CloneBytes(),
+		MerkleRoot:    hdr.MerkleRoot.CloneBytes(),
+		Timestamp:     hdr.Timestamp.Unix(),
+		Bits:          hdr.Bits,
+		Nonce:         hdr.Nonce,
+	}
+}
+
+// TestGatewayUnary ensures unary methods are served as JSON with gRPC errors
+// mapped to HTTP statuses.
+func TestGatewayUnary(t *testing.T) {
+	info := genesisInfo()
+	base, g := newGateway(t, &testServer{info: info})
+
+	genesisHash := chaincfg.MainNetParams.GenesisHash.String()
+	request := `{"hash":"` + genesisHash + `"}`
+	methodURL := base + "/pb.bchrpc/GetBlockInfo"
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		status int
+		code   codes.Code
+	}{
+		{
+			name:   "post",
+			method: http.MethodPost,
+			url:    methodURL,
+			body:   request,
+			status: http.StatusOK,
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			url:    methodURL + "?request=" + url.QueryEscape(request),
+			status: http.StatusOK,
+		},
+		{
+			name:   "unknown block",
+			method: http.MethodPost,
+			url:    methodURL,
+			body:   `{"hash":"` + strings.Repeat("00", 32) + `"}`,
+			status: http.StatusNotFound,
+			code:   codes.NotFound,
+		},
+		{
+			name:   "empty request",
+			method: http.MethodPost,
+			url:    methodURL,
+			status: http.StatusNotFound,
+			code:   codes.NotFound,
+		},
+		{
+			name:   "bad hex",
+			method: http.MethodPost,
+			url:    methodURL,
+			body:   `{"hash":"zz"}`,
+			status: http.StatusBadRequest,
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "unknown field",
+			method: http.MethodPost,
+			url:    methodURL,
+			body:   `{"other":1}`,
+			status: http.StatusBadRequest,
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "unknown method",
+			method: http.MethodPost,
+			url:    base + "/pb.bchrpc/GetNothing",
+			status: http.StatusNotFound,
+			code:   codes.NotFound,
+		},
+		{
+			name:   "client streaming method",
+			method: http.MethodPost,
+			url:    base + "/pb.bchrpc/SubscribeTransactionStream",
+			status: http.StatusNotFound,
+			code:   codes.NotFound,
+		},
+		{
+			name:   "bad http method",
+			method: http.MethodPut,
+			url:    methodURL,
+			status: http.StatusMethodNotAllowed,
+			code:   codes.Unimplemented,
+		},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, test.url,
+			strings.NewReader(test.body))
+		if err != nil {
+			t.Fatalf("%s: NewRequest: unexpected error: %v", test.name, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("%s: Do: unexpected error: %v", test.name, err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: got status %d, want %d (%s)", test.name,
+				resp.StatusCode, test.status, body)
+			continue
+		}
+
+		if test.status != http.StatusOK {
+			var e struct {
+				Code codes.Code `json:"code"`
+			}
+			if err := json.Unmarshal(body, &e); err != nil {
+				t.Errorf("%s: Unmarshal: unexpected error: %v",
+					test.name, err)
+			} else if e.Code != test.code {
+				t.Errorf("%s: got code %v, want %v", test.name,
+					e.Code, test.code)
+			}
+			continue
+		}
+
+		// Hashes are rendered as reversed hex.
+		if !strings.Contains(string(body), `"hash":"`+genesisHash+`"`) {
+			t.Errorf("%s: response does not hold the block hash: %s",
+				test.name, body)
+		}
+		got := new(pb.GetBlockInfoResponse)
+		err = jsonpb.Unmarshal(bytes.NewReader(body), got)
+		if err != nil {
+			t.Errorf("%s: Unmarshal: unexpected error: %v", test.name, err)
+		} else if !proto.Equal(got.Info, info) {
+			t.Errorf("%s: got %v, want %v", test.name, got.Info, info)
+		}
+	}
+
+	for _, name := range g.Methods() {
+		if name == "/pb.bchrpc/SubscribeTransactionStream" {
+			t.Errorf("Methods: client streaming method %s exposed", name)
+		}
+	}
+}
+
+// readEvents reads the server-sent events of a response as pairs of event
+// type and data.
+func readEvents(t *testing.T, r io.Reader) [][2]string {
+	var events [][2]string
+	var event string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, [2]string{event,
+				strings.TrimPrefix(line, "data: ")})
+			event = ""
+		case line != "":
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+	return events
+}
+
+// TestGatewayStream ensures server streaming methods are served as server-sent
+// events.
+func TestGatewayStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		streamErr error
+		last      string
+	}{
+		{
+			name:  "stream ends",
+			count: 3,
+			last:  "end",
+		},
+		{
+			name:      "stream fails",
+			count:     2,
+			streamErr: status.Error(codes.Unavailable, "shutting down"),
+			last:      "error",
+		},
+	}
+
+	for _, test := range tests {
+		base, _ := newGateway(t, &testServer{info: genesisInfo(),
+			blockCount: test.count, streamErr: test.streamErr})
+		resp, err := http.Get(base + "/pb.bchrpc/SubscribeBlocks")
+		if err != nil {
+			t.Fatalf("%s: Get: unexpected error: %v", test.name, err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("%s: got content type %q", test.name, ct)
+		}
+		events := readEvents(t, resp.Body)
+		resp.Body.Close()
+
+		if len(events) != test.count+1 {
+			t.Errorf("%s: got %d events, want %d", test.name,
+				len(events), test.count+1)
+			continue
+		}
+		for i, event := range events[:test.count] {
+			if event[0] != "" {
+				t.Errorf("%s: event %d has type %q", test.name, i,
+					event[0])
+			}
+			got := new(pb.BlockNotification)
+			err := jsonpb.Unmarshal(strings.NewReader(event[1]), got)
+			if err != nil {
+				t.Errorf("%s: Unmarshal: unexpected error: %v",
+					test.name, err)
+				continue
+			}
+			if got.Block.GetHeight() != int32(i) {
+				t.Errorf("%s: event %d has height %d", test.name, i,
+					got.Block.GetHeight())
+			}
+		}
+		if last := events[test.count][0]; last != test.last {
+			t.Errorf("%s: got final event %q, want %q", test.name, last,
+				test.last)
+		}
+	}
+}
+
+// TestGatewayForwardHeaders ensures only the configured headers are forwarded
+// to the gRPC server as metadata.
+func TestGatewayForwardHeaders(t *testing.T) {
+	srv := &testServer{info: genesisInfo(), blockCount: 1, token: "secret"}
+	forwarding, _ := newGateway(t, srv, "authenticationToken")
+	plain, _ := newGateway(t, srv)
+	request := url.QueryEscape(`{"hash":"` +
+		chaincfg.MainNetParams.GenesisHash.String() + `"}`)
+
+	tests := []struct {
+		name   string
+		base   string
+		path   string
+		token  string
+		status int
+	}{
+		{"unary forwarded", forwarding, "/pb.bchrpc/GetBlockInfo?request=" +
+			request, "secret", http.StatusOK},
+		{"unary wrong token", forwarding, "/pb.bchrpc/GetBlockInfo?request=" +
+			request, "guess", http.StatusUnauthorized},
+		{"unary not forwarded", plain, "/pb.bchrpc/GetBlockInfo?request=" +
+			request, "secret", http.StatusUnauthorized},
+		{"stream forwarded", forwarding, "/pb.bchrpc/SubscribeBlocks",
+			"secret", http.StatusOK},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodGet, test.base+test.path,
+			nil)
+		if err != nil {
+			t.Fatalf("NewRequest: unexpected error: %v", err)
+		}
+		req.Header.Set("AuthenticationToken", test.token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: Do: unexpected error: %v", test.name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: got status %d, want %d: %s", test.name,
+				resp.StatusCode, test.status, body)
+		}
+		if strings.Contains(string(body), "event: error") {
+			t.Errorf("%s: stream failed: %s", test.name, body)
+		}
+	}
+}
+
+// TestNewErrors ensures bad gateway configurations are rejected.
+func TestNewErrors(t *testing.T) {
+	_, err := gateway.New(&gateway.Config{Services: []string{"pb.bchrpc"}})
+	if err != gateway.ErrNoConn {
+		t.Errorf("New: got error %v, want %v", err, gateway.ErrNoConn)
+	}
+
+	conn, err := grpc.NewClient("passthrough:///unused",
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient: unexpected error: %v", err)
+	}
+	defer conn.Close()
+	for _, name := range []string{"pb.nothing", "pb.BlockInfo"} {
+		_, err := gateway.New(&gateway.Config{Conn: conn,
+			Services: []string{name}})
+		if err == nil || !strings.HasPrefix(err.Error(),
+			gateway.ErrUnknownService.Error()) {
+
+			t.Errorf("New(%s): got error %v, want %v", name, err,
+				gateway.ErrUnknownService)
+		}
+	}
+}
+
+// TestHTTPStatus ensures gRPC codes map to the expected HTTP statuses.
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.DataLoss, http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if got := gateway.HTTPStatus(test.code); got != test.want {
+			t.Errorf("HTTPStatus(%v): got %d, want %d", test.code, got,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020 The bchd developers
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package header
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/wire"
+)
+
+const (
+	// idealBlockTime is the target time between blocks in seconds.
+	idealBlockTime = 600
+
+	// radix is the fixed point radix of the exponent in the aserti3-2d
+	// calculation.
+	radix = 65536
+
+	// rbits is the number of bits after the radix point.
+	rbits = 16
+)
+
+// maxShift bounds the number of bits the target is shifted by.  A larger shift
+// would move any target past zero or the largest possible target.
+var maxShift = big.NewInt(512)
+
+// Asert computes the difficulty required by the aserti3-2d algorithm, which
+// sets the target of each block from the target of a fixed anchor block and
+// how far ahead of or behind an ideal 600 second schedule the chain is since
+// the anchor.  The target doubles for every HalfLife seconds the chain falls
+// behind schedule and halves for every HalfLife seconds it gets ahead.
+type Asert struct {
+	// AnchorHeight is the height of the anchor block.
+	AnchorHeight int32
+
+	// AnchorParentTimestamp is the timestamp, in seconds since the Unix
+	// epoch, of the parent of the anchor block.
+	AnchorParentTimestamp int64
+
+	// AnchorBits is the difficulty bits of the anchor block.
+	AnchorBits uint32
+
+	// HalfLife is the number of seconds the chain must fall behind schedule
+	// for the target to double.
+	HalfLife int64
+
+	// PowLimit is the highest target allowed and PowLimitBits its compact
+	// representation.
+	PowLimit     *big.Int
+	PowLimitBits uint32
+
+	// MinDiffReductionTime, when not zero, allows a block to use the
+	// proof of work limit when its timestamp is more than this long after
+	// its parent, as on the test networks.
+	MinDiffReductionTime time.Duration
+}
+
+// NewAsert returns the aserti3-2d parameters of the passed network.
+func NewAsert(params *chaincfg.Params) *Asert {
+	a := &Asert{
+		AnchorHeight:          params.AsertDifficultyAnchorHeight,
+		AnchorParentTimestamp: params.AsertDifficultyAnchorParentTimestamp,
+		AnchorBits:            params.AsertDifficultyAnchorBits,
+		HalfLife:              params.AsertDifficultyHalflife,
+		PowLimit:              params.PowLimit,
+		PowLimitBits:          params.PowLimitBits,
+	}
+	if params.ReduceMinDifficulty {
+		a.MinDiffReductionTime = params.MinDiffReductionTime
+	}
+	return a
+}
+
+// Target returns the target required of the child of the passed parent header
+// at parentHeight.  The target is clamped to the range from one to PowLimit.
+func (a *Asert) Target(parent *wire.BlockHeader, parentHeight int32) *big.Int {
+	target := a.calcTarget(parentHeight, parent.Timestamp.Unix())
+	if target.Sign() == 0 {
+		return big.NewInt(1)
+	}
+	if target.Cmp(a.PowLimit) > 0 {
+		return new(big.Int).Set(a.PowLimit)
+	}
+	return target
+}
+
+// RequiredBits returns the difficulty bits required of a block with the passed
+// timestamp which is the child of the passed parent header at parentHeight.
+// Unlike Target, this applies the minimum difficulty rule of the test networks.
+func (a *Asert) RequiredBits(parent *wire.BlockHeader, parentHeight int32,
+	timestamp time.Time) uint32 {
+
+	// For networks that support it, allow special reduction of the
+	// required difficulty once too much time has elapsed without mining a
+	// block.
+	if a.MinDiffReductionTime != 0 {
+		reductionTime := int64(a.MinDiffReductionTime / time.Second)
+		allowMinTime := parent.Timestamp.Unix() + reductionTime
+		if timestamp.Unix() > allowMinTime {
+			return a.PowLimitBits
+		}
+	}
+
+	target := a.calcTarget(parentHeight, parent.Timestamp.Unix())
+	if target.Sign() == 0 {
+		return BigToCompact(bigOne)
+	}
+	if target.Cmp(a.PowLimit) > 0 {
+		return a.PowLimitBits
+	}
+	return BigToCompact(target)
+}
+
+// calcTarget returns the unclamped aserti3-2d target for the child of the
+// block at parentHeight with parentTimestamp.
+func (a *Asert) calcTarget(parentHeight int32, parentTimestamp int64) *big.Int {
+	target := CompactToBig(a.AnchorBits)
+
+	// Heights are subtracted as 32-bit integers so the difference remains
+	// correct when heights wrap, as the reference implementation requires.
+	tDelta := parentTimestamp - a.AnchorParentTimestamp
+	hDelta := parentHeight - a.AnchorHeight
+	bigRadix := big.NewInt(radix)
+
+	// exponent = int(((time_diff - IDEAL_BLOCK_TIME * (height_diff + 1)) * RADIX) / HALFLIFE)
+	exponent := new(big.Int).Sub(big.NewInt(tDelta),
+		new(big.Int).Mul(big.NewInt(idealBlockTime),
+			new(big.Int).Add(big.NewInt(int64(hDelta)), bigOne)))
+	exponent.Mul(exponent, bigRadix)
+	exponent.Quo(exponent, big.NewInt(a.HalfLife))
+
+	// shifts = exponent >> RBITS
+	shifts := new(big.Int).Rsh(exponent, rbits)
+
+	// exponent -= shifts * RADIX
+	exponent.Sub(exponent, new(big.Int).Mul(shifts, bigRadix))
+
+	// target *= RADIX + ((195766423245049 * exponent + 971821376 * exponent**2 + 5127 * exponent**3 + 2**47) >> (RBITS * 3))
+	factor := new(big.Int).Mul(big.NewInt(195766423245049), exponent)
+	factor.Add(factor, new(big.Int).Mul(big.NewInt(971821376),
+		new(big.Int).Exp(exponent, big.NewInt(2), nil)))
+	factor.Add(factor, new(big.Int).Mul(big.NewInt(5127),
+		new(big.Int).Exp(exponent, big.NewInt(3), nil)))
+	factor.Add(factor, new(big.Int).Lsh(bigOne, 47))
+	factor.Rsh(factor, rbits*3)
+	target.Mul(target, new(big.Int).Add(bigRadix, factor))
+
+	// Any shift beyond the width of a target saturates the result, so the
+	// shift is bounded to avoid huge allocations for absurd timestamps.
+	if shifts.CmpAbs(maxShift) > 0 {
+		shifts.Mul(maxShift, big.NewInt(int64(shifts.Sign())))
+	}
+
+	// if shifts < 0: target >>= -shifts else: target <<= shifts
+	if shifts.Sign() < 0 {
+		target.Rsh(target, uint(-shifts.Int64()))
+	} else {
+		target.Lsh(target, uint(shifts.Int64()))
+	}
+
+	// target >>= RBITS
+	return target.Rsh(target, rbits)
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package header
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+)
+
+// Node is a header known to a Chain along with its position in the tree of
+// headers.  Nodes are never modified once added to a Chain.
+type Node struct {
+	// Header is the block header.
+	Header wire.BlockHeader
+
+	// Hash is the hash of Header.
+	Hash chainhash.Hash
+
+	// Height is the height of the header in the chain.
+	Height int32
+
+	// Work is the total work of the chain up to and including the header.
+	Work *big.Int
+
+	// Parent is the node of the previous header, or nil for the root of the
+	// chain.
+	Parent *Node
+
+	// skip is an ancestor further back than Parent, at the height given by
+	// skipHeight, which lets Ancestor find any ancestor in a logarithmic
+	// number of steps.  It is nil when that height is below the root.
+	skip *Node
+}
+
+// invertLowestOne turns the lowest 1 bit in the binary representation of n
+// into a 0.
+func invertLowestOne(n int32) int32 {
+	return n & (n - 1)
+}
+
+// skipHeight returns the height of the ancestor a node at the passed height
+// links to with its skip pointer.  Any height below the node works, but these
+// heights make walking back to any ancestor take a logarithmic number of
+// steps.
+func skipHeight(height int32) int32 {
+	if height < 2 {
+		return 0
+	}
+
+	// Determine which height to jump back to.  Any number strictly lower
+	// than height is acceptable, but the following expression seems to
+	// perform well in simulations (max 110 steps to go back up to 2**18
+	// blocks).
+	if (height & 1) != 0 {
+		return invertLowestOne(invertLowestOne(height-1)) + 1
+	}
+	return invertLowestOne(height)
+}
+
+// Ancestor returns the ancestor of the node at the passed height, or nil if
+// the height is above the node or below the root of the chain.
+func (n *Node) Ancestor(height int32) *Node {
+	if height < 0 || height > n.Height {
+		return nil
+	}
+
+	node := n
+	for node != nil && node.Height != height {
+		// Take the skip pointer unless it overshoots the target
+		// height, or the parent's skip pointer gets closer to it.
+		skip := skipHeight(node.Height)
+		skipPrev := skipHeight(node.Height - 1)
+		if node.skip != nil && (skip == height || (skip > height &&
+			!(skipPrev < skip-2 && skipPrev >= height))) {
+
+			node = node.skip
+		} else {
+			node = node.Parent
+		}
+	}
+	return node
+}
+
+// newNode returns a node for header as a child of parent.
+func newNode(header *wire.BlockHeader, parent *Node) *Node {
+	node := &Node{
+		Header: *header,
+		Hash:   header.BlockHash(),
+		Parent: parent,
+		Work:   CalcWork(header.Bits),
+	}
+	if parent != nil {
+		node.Height = parent.Height + 1
+		node.Work.Add(node.Work, parent.Work)
+		node.skip = parent.Ancestor(skipHeight(node.Height))
+	}
+	return node
+}
+
+// Chain is a tree of block headers rooted at the genesis block or a trusted
+// checkpoint.  Headers are only accepted when they link to a known header and
+// carry valid proof of work, and the tip is the header with the most
+// cumulative work.  A Chain is safe for concurrent access.
+//
+// On networks which adjust difficulty, headers above the aserti3-2d anchor
+// block must also carry the bits required by the algorithm.  The difficulty of
+// earlier headers, which used older algorithms, is not checked.
+type Chain struct {
+	params *chaincfg.Params
+	asert  *Asert
+
+	mtx   sync.RWMutex
+	nodes map[chainhash.Hash]*Node
+	tip   *Node
+
+	// mainChain holds the nodes of the chain ending at the tip indexed by
+	// their height above the root, so main chain lookups do not walk the
+	// chain.
+	mainChain []*Node
+}
+
+// NewChain returns a Chain rooted at the genesis block of the passed network.
+func NewChain(params *chaincfg.Params) *Chain {
+	return newChain(params, newNode(&params.GenesisBlock.Header, nil))
+}
+
+// NewChainFromCheckpoint returns a Chain rooted at a trusted header at the
+// passed height with the passed total chain work, which allows headers to be
+// synced from a checkpoint instead of the genesis block.
+func NewChainFromCheckpoint(params *chaincfg.Params, header *wire.BlockHeader,
+	height int32, work *big.Int) *Chain {
+
+	root := newNode(header, nil)
+	root.Height = height
+	root.Work = new(big.Int).Set(work)
+	return newChain(params, root)
+}
+
+// newChain returns a Chain holding only the passed root node.
+func newChain(params *chaincfg.Params, root *Node) *Chain {
+	c := &Chain{
+		params:    params,
+		nodes:     map[chainhash.Hash]*Node{root.Hash: root},
+		tip:       root,
+		mainChain: []*Node{root},
+	}
+	if !params.NoDifficultyAdjustment {
+		c.asert = NewAsert(params)
+	}
+	return c
+}
+
+// Add validates the passed header and adds it to the chain.  The previous
+// block of the header must already be in the chain and the header must carry
+// the required difficulty and valid proof of work for the network.  The header
+// becomes the new tip when its chain has more work than the current tip.  A
+// RuleError is returned when the header is rejected.
+func (c *Chain) Add(header *wire.BlockHeader) (*Node, error) {
+	hash := header.BlockHash()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.nodes[hash]; ok {
+		str := fmt.Sprintf("already have header %v", hash)
+		return nil, ruleError(ErrDuplicateHeader, str)
+	}
+	parent, ok := c.nodes[header.PrevBlock]
+	if !ok {
+		str := fmt.Sprintf("previous block %v of header %v is unknown",
+			header.PrevBlock, hash)
+		return nil, ruleError(ErrOrphanHeader, str)
+	}
+	if c.asert != nil && parent.Height >= c.asert.AnchorHeight {
+		bits := c.asert.RequiredBits(&parent.Header, parent.Height,
+			header.Timestamp)
+		if header.Bits != bits {
+			str := fmt.Sprintf("header %v has bits %08x, expected %08x",
+				hash, header.Bits, bits)
+			return nil, ruleError(ErrBadDifficulty, str)
+		}
+	}
+	if err := CheckProofOfWork(header, c.params.PowLimit); err != nil {
+		return nil, err
+	}
+
+	node := newNode(header, parent)
+	c.nodes[hash] = node
+	if node.Work.Cmp(c.tip.Work) > 0 {
+		c.setTip(node)
+	}
+	return node, nil
+}
+
+// setTip makes the passed node the tip and updates the main chain to end at
+// it.  Only the nodes above the fork point with the old main chain are
+// replaced.
+//
+// This function MUST be called with the chain lock held for writes.
+func (c *Chain) setTip(node *Node) {
+	c.tip = node
+
+	n := int(node.Height-c.mainChain[0].Height) + 1
+	if n <= len(c.mainChain) {
+		// Clear the nodes above the new tip so they can be garbage
+		// collected.
+		for i := n; i < len(c.mainChain); i++ {
+			c.mainChain[i] = nil
+		}
+		c.mainChain = c.mainChain[:n]
+	} else {
+		c.mainChain = append(c.mainChain,
+			make([]*Node, n-len(c.mainChain))...)
+	}
+	for i := n - 1; node != nil && c.mainChain[i] != node; i-- {
+		c.mainChain[i] = node
+		node = node.Parent
+	}
+}
+
+// AddHeaders adds each of the passed headers in order, such as those of a
+// headers message.  It stops at the first header which is rejected and returns
+// the number of headers added along with the error.
+func (c *Chain) AddHeaders(headers []*wire.BlockHeader) (int, error) {
+	for i, header := range headers {
+		if _, err := c.Add(header); err != nil {
+			return i, err
+		}
+	}
+	return len(headers), nil
+}
+
+// Tip returns the node with the most cumulative work.  When several nodes have
+// the same work, the first one added is returned.
+func (c *Chain) Tip() *Node {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.tip
+}
+
+// Lookup returns the node of the header with the passed hash, or nil if the
+// header is not in the chain.
+func (c *Chain) Lookup(hash *chainhash.Hash) *Node {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.nodes[*hash]
+}
+
+// MainChainNode returns the node at the passed height in the chain ending at
+// the tip, or nil if there is no such node.
+func (c *Chain) MainChainNode(height int32) *Node {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.mainChainNode(height)
+}
+
+// mainChainNode returns the node at the passed height in the chain ending at
+// the tip, or nil if there is no such node.
+//
+// This function MUST be called with the chain lock held.
+func (c *Chain) mainChainNode(height int32) *Node {
+	i := int64(height) - int64(c.mainChain[0].Height)
+	if i < 0 || i >= int64(len(c.mainChain)) {
+		return nil
+	}
+	return c.mainChain[i]
+}
+
+// InMainChain returns whether the header with the passed hash is part of the
+// chain ending at the tip.
+func (c *Chain) InMainChain(hash *chainhash.Hash) bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	node := c.nodes[*hash]
+	if node == nil {
+		return false
+	}
+	return c.mainChainNode(node.Height) == node
+}
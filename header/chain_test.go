@@ -0,0 +1,210 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package header_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/header"
+)
+
+// mineHeader returns a regtest header building on parent with the passed bits
+// and a nonce solving it.  The extra value makes sibling headers distinct.
+func mineHeader(parent *wire.BlockHeader, bits uint32, extra uint32) *wire.BlockHeader {
+	h := &wire.BlockHeader{
+		Version:   1,
+		PrevBlock: parent.BlockHash(),
+		Timestamp: parent.Timestamp.Add(time.Duration(extra+1) * time.Second),
+		Bits:      bits,
+	}
+	target := header.CompactToBig(bits)
+	for {
+		hash := h.BlockHash()
+		if header.HashToBig(&hash).Cmp(target) <= 0 {
+			return h
+		}
+		h.Nonce++
+	}
+}
+
+// TestChain ensures headers are linked, work is accumulated and the tip
+// follows the chain with the most work.
+func TestChain(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	genesis := &params.GenesisBlock.Header
+	bits := params.PowLimitBits
+	chain := header.NewChain(params)
+
+	// Build a main chain of three headers.
+	var main []*wire.BlockHeader
+	parent := genesis
+	for i := 0; i < 3; i++ {
+		h := mineHeader(parent, bits, 0)
+		main = append(main, h)
+		parent = h
+	}
+	if n, err := chain.AddHeaders(main); err != nil || n != len(main) {
+		t.Fatalf("AddHeaders: added %d headers: %v", n, err)
+	}
+	tip := chain.Tip()
+	if tip.Height != 3 || tip.Hash != main[2].BlockHash() {
+		t.Fatalf("Tip: got %v at height %d, want %v at height 3",
+			tip.Hash, tip.Height, main[2].BlockHash())
+	}
+	wantWork := new(big.Int).Mul(header.CalcWork(bits), big.NewInt(4))
+	if tip.Work.Cmp(wantWork) != 0 {
+		t.Errorf("Work: got %v, want %v", tip.Work, wantWork)
+	}
+
+	// A fork from the first header with equal work does not replace the
+	// tip, but one with more work does.
+	fork1 := mineHeader(main[0], bits, 1)
+	fork2 := mineHeader(fork1, bits, 1)
+	if _, err := chain.AddHeaders([]*wire.BlockHeader{fork1, fork2}); err != nil {
+		t.Fatalf("AddHeaders: unexpected error: %v", err)
+	}
+	if chain.Tip().Hash != main[2].BlockHash() {
+		t.Errorf("Tip: equal work fork replaced tip")
+	}
+	fork3 := mineHeader(fork2, bits, 1)
+	node, err := chain.Add(fork3)
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	if chain.Tip() != node {
+		t.Errorf("Tip: got %v, want fork tip %v", chain.Tip().Hash,
+			node.Hash)
+	}
+
+	// The main chain now follows the fork.
+	hash := main[2].BlockHash()
+	if chain.InMainChain(&hash) {
+		t.Errorf("InMainChain: stale header is in main chain")
+	}
+	hash = main[0].BlockHash()
+	if !chain.InMainChain(&hash) {
+		t.Errorf("InMainChain: common ancestor is not in main chain")
+	}
+	if n := chain.MainChainNode(2); n == nil || n.Hash != fork1.BlockHash() {
+		t.Errorf("MainChainNode: unexpected node at height 2")
+	}
+	if chain.MainChainNode(5) != nil {
+		t.Errorf("MainChainNode: unexpected node above tip")
+	}
+	if n := chain.Lookup(&hash); n == nil || n.Height != 1 {
+		t.Errorf("Lookup: unexpected node %v", n)
+	}
+
+	// Rejected headers.
+	orphan := mineHeader(mineHeader(fork3, bits, 2), bits, 2)
+	tooEasy := mineHeader(fork3, 0x2100ffff, 3)
+	tests := []struct {
+		name   string
+		header *wire.BlockHeader
+		code   header.ErrorCode
+	}{
+		{"duplicate", main[1], header.ErrDuplicateHeader},
+		{"orphan", orphan, header.ErrOrphanHeader},
+		{"target above limit", tooEasy, header.ErrBadTarget},
+	}
+	for _, test := range tests {
+		_, err := chain.Add(test.header)
+		rerr, ok := err.(header.RuleError)
+		if !ok || rerr.ErrorCode != test.code {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, test.code)
+		}
+	}
+}
+
+// TestChainFromCheckpoint ensures a chain can be rooted at a checkpoint.
+func TestChainFromCheckpoint(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	checkpoint := mineHeader(&params.GenesisBlock.Header,
+		params.PowLimitBits, 0)
+	chain := header.NewChainFromCheckpoint(params, checkpoint, 1000,
+		big.NewInt(5000))
+
+	node, err := chain.Add(mineHeader(checkpoint, params.PowLimitBits, 0))
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	if node.Height != 1001 || node.Work.Int64() != 5002 {
+		t.Errorf("Add: got height %d work %v, want height 1001 work 5002",
+			node.Height, node.Work)
+	}
+	if node.Ancestor(1000) != node.Parent || node.Ancestor(999) != nil {
+		t.Errorf("Ancestor: unexpected ancestors")
+	}
+}
+
+// TestAncestor ensures ancestors found through skip pointers and main chain
+// lookups match those found by walking parents across a reorganization.
+func TestAncestor(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	bits := params.PowLimitBits
+	chain := header.NewChainFromCheckpoint(params,
+		&params.GenesisBlock.Header, 10, big.NewInt(0))
+
+	// addChain adds n headers building on parent and returns the last.
+	addChain := func(parent *wire.BlockHeader, n int,
+		extra uint32) *wire.BlockHeader {
+
+		for i := 0; i < n; i++ {
+			parent = mineHeader(parent, bits, extra)
+			if _, err := chain.Add(parent); err != nil {
+				t.Fatalf("Add: unexpected error: %v", err)
+			}
+		}
+		return parent
+	}
+	tip := addChain(&params.GenesisBlock.Header, 300, 0)
+	forkHash := chain.MainChainNode(150).Hash
+	fork := &chain.Lookup(&forkHash).Header
+	stale := chain.Tip()
+	addChain(fork, 200, 1)
+
+	// check ensures every ancestor of node matches the one found by
+	// walking its parents.
+	check := func(node *header.Node) {
+		for n := node; n != nil; n = n.Parent {
+			if got := node.Ancestor(n.Height); got != n {
+				t.Fatalf("Ancestor(%d) of %d: got %v, want %v",
+					n.Height, node.Height, got, n)
+			}
+		}
+		if node.Ancestor(9) != nil {
+			t.Errorf("Ancestor: got ancestor below root")
+		}
+	}
+	check(stale)
+	check(chain.Tip())
+
+	newTip := chain.Tip()
+	if newTip.Height != 350 {
+		t.Fatalf("Tip: got height %d, want 350", newTip.Height)
+	}
+	for height := int32(10); height <= newTip.Height; height++ {
+		want := newTip.Ancestor(height)
+		if got := chain.MainChainNode(height); got != want {
+			t.Fatalf("MainChainNode(%d): got %v, want %v", height,
+				got, want)
+		}
+		if !chain.InMainChain(&want.Hash) {
+			t.Errorf("InMainChain(%d): got false, want true", height)
+		}
+	}
+	hash := tip.BlockHash()
+	if chain.InMainChain(&hash) {
+		t.Errorf("InMainChain: stale tip is in main chain")
+	}
+	if chain.MainChainNode(9) != nil || chain.MainChainNode(351) != nil {
+		t.Errorf("MainChainNode: got node outside the main chain")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package header provides proof of work and chain work helpers for bitcoin cash
+block headers along with a header chain for clients which do not run a node.
+
+# Overview
+
+CompactToBig and BigToCompact convert between the compact Bits of a header and
+the target its hash must not exceed, CheckProofOfWork checks a header against
+its target and the proof of work limit of a network, and CalcWork returns the
+expected number of hashes represented by a target.
+
+# Header Chains
+
+A Chain holds a tree of headers rooted at the genesis block of a network or at
+a trusted checkpoint.  Each added header must link to a header already in the
+chain and carry valid proof of work.  The cumulative work of every header is
+tracked and the tip is the header with the most work, so the chain reorganizes
+onto forks with more work as their headers arrive:
+
+	chain := header.NewChain(&chaincfg.MainNetParams)
+	if _, err := chain.AddHeaders(msgHeaders.Headers); err != nil {
+		return err
+	}
+	tip := chain.Tip()
+	fmt.Println(tip.Height, tip.Hash, tip.Work)
+
+Headers which are rejected are reported as a RuleError whose ErrorCode
+identifies the reason.
+
+# Difficulty Adjustment
+
+Asert computes the difficulty required by aserti3-2d, the difficulty adjustment
+algorithm of bitcoin cash since November 2020.  Given the anchor block of the
+algorithm and the parent of a block, it returns the target or compact bits the
+block must carry:
+
+	asert := header.NewAsert(&chaincfg.MainNetParams)
+	bits := asert.RequiredBits(&parent.Header, parent.Height, timestamp)
+
+On networks which adjust difficulty, a Chain uses Asert to reject headers above
+the anchor block which do not carry the required bits.
+*/
+package header
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package header
+
+import (
+	"fmt"
+)
+
+// ErrorCode identifies a kind of header validation failure.
+type ErrorCode int
+
+// These constants are used to identify a specific RuleError.
+const (
+	// ErrBadTarget indicates the target encoded by the header bits is not
+	// positive or is higher than the proof of work limit of the network.
+	ErrBadTarget ErrorCode = iota
+
+	// ErrHighHash indicates the header hash is higher than the target
+	// encoded by its bits.
+	ErrHighHash
+
+	// ErrOrphanHeader indicates the previous block of the header is not
+	// known to the chain.
+	ErrOrphanHeader
+
+	// ErrDuplicateHeader indicates the header is already known to the
+	// chain.
+	ErrDuplicateHeader
+
+	// ErrBadDifficulty indicates the header bits do not match the
+	// difficulty required by the difficulty adjustment algorithm.
+	ErrBadDifficulty
+)
+
+// Map of ErrorCode values back to their constant names for pretty printing.
+var errorCodeStrings = map[ErrorCode]string{
+	ErrBadTarget:       "ErrBadTarget",
+	ErrHighHash:        "ErrHighHash",
+	ErrOrphanHeader:    "ErrOrphanHeader",
+	ErrDuplicateHeader: "ErrDuplicateHeader",
+	ErrBadDifficulty:   "ErrBadDifficulty",
+}
+
+// String returns the ErrorCode as a human-readable name.
+func (e ErrorCode) String() string {
+	if s := errorCodeStrings[e]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown ErrorCode (%d)", int(e))
+}
+
+// RuleError describes a single reason a header is invalid.  The caller can use
+// type assertions and the ErrorCode field to determine the specific rule that
+// was violated.
+type RuleError struct {
+	// ErrorCode identifies the rule that was violated.
+	ErrorCode ErrorCode
+
+	// Description is a human-readable explanation of the violation.
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e RuleError) Error() string {
+	return e.Description
+}
+
+// ruleError creates a RuleError given a set of arguments.
+func ruleError(c ErrorCode, desc string) RuleError {
+	return RuleError{ErrorCode: c, Description: desc}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package header_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/header"
+)
+
+// TestCompact ensures compact bits convert to and from targets as expected.
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name    string
+		compact uint32
+		target  string
+	}{
+		{"zero", 0, "0"},
+		{"mainnet pow limit", 0x1d00ffff,
+			"ffff0000000000000000000000000000000000000000000000000000"},
+		{"regtest pow limit", 0x207fffff,
+			"7fffff0000000000000000000000000000000000000000000000000000000000"},
+		{"mainnet block 100000", 0x1b04864c,
+			"4864c000000000000000000000000000000000000000000000000"},
+		{"small exponent", 0x03123456, "123456"},
+		{"negative", 0x04923456, "-12345600"},
+	}
+
+	for _, test := range tests {
+		want, _ := new(big.Int).SetString(test.target, 16)
+		got := header.CompactToBig(test.compact)
+		if got.Cmp(want) != 0 {
+			t.Errorf("%s: CompactToBig got %x, want %x", test.name, got,
+				want)
+		}
+		if compact := header.BigToCompact(want); compact != test.compact {
+			t.Errorf("%s: BigToCompact got %08x, want %08x",
+				test.name, compact, test.compact)
+		}
+	}
+}
+
+// TestCalcWork ensures the work of difficulty bits is calculated as expected.
+func TestCalcWork(t *testing.T) {
+	tests := []struct {
+		bits uint32
+		work int64
+	}{
+		{0x1d00ffff, 4295032833},
+		{0x207fffff, 2},
+		{0x04923456, 0},
+	}
+
+	for _, test := range tests {
+		if work := header.CalcWork(test.bits); work.Int64() != test.work {
+			t.Errorf("CalcWork(%08x): got %v, want %d", test.bits, work,
+				test.work)
+		}
+	}
+}
+
+// TestCheckProofOfWork ensures headers are checked against their targets and
+// the proof of work limit.
+func TestCheckProofOfWork(t *testing.T) {
+	genesis := chaincfg.MainNetParams.GenesisBlock.Header
+	highHash := genesis
+	highHash.Nonce++
+	badTarget := genesis
+	badTarget.Bits = 0x1e00ffff
+	negTarget := genesis
+	negTarget.Bits = 0x1d80ffff
+
+	tests := []struct {
+		name   string
+		header *wire.BlockHeader
+		valid  bool
+		code   header.ErrorCode
+	}{
+		{"genesis", &genesis, true, 0},
+		{"high hash", &highHash, false, header.ErrHighHash},
+		{"target above limit", &badTarget, false, header.ErrBadTarget},
+		{"negative target", &negTarget, false, header.ErrBadTarget},
+	}
+
+	for _, test := range tests {
+		err := header.CheckProofOfWork(test.header,
+			chaincfg.MainNetParams.PowLimit)
+		if test.valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(header.RuleError)
+		if !ok {
+			t.Errorf("%s: wrong error - got %v <%T>, want <%T>",
+				test.name, err, err, header.RuleError{})
+			continue
+		}
+		if rerr.ErrorCode != test.code {
+			t.Errorf("%s: wrong error code - got %v, want %v",
+				test.name, rerr.ErrorCode, test.code)
+		}
+	}
+}
+
+// TestErrorCodeStringer tests the stringized output for the ErrorCode type.
+func TestErrorCodeStringer(t *testing.T) {
+	tests := []struct {
+		in   header.ErrorCode
+		want string
+	}{
+		{header.ErrBadTarget, "ErrBadTarget"},
+		{header.ErrHighHash, "ErrHighHash"},
+		{header.ErrOrphanHeader, "ErrOrphanHeader"},
+		{header.ErrDuplicateHeader, "ErrDuplicateHeader"},
+		{header.ErrBadDifficulty, "ErrBadDifficulty"},
+		{0xffff, "Unknown ErrorCode (65535)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
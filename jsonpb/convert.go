@@ -0,0 +1,375 @@
+package jsonpb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var (
+	// ErrBadBytes describes an error where the JSON value of a bytes field
+	// is not a hex string, or for a hash field, not a hash string.
+	ErrBadBytes = errors.New("invalid hex string for bytes field")
+
+	// ErrBadJSON describes an error where the JSON value of a field does
+	// not have the type expected from its descriptor.
+	ErrBadJSON = errors.New("unexpected JSON value for field")
+)
+
+// anyName is the full name of the well-known Any message, whose JSON form
+// holds the fields of the embedded message alongside its type URL.
+const anyName = "google.protobuf.Any"
+
+// nullValueName is the full name of the well-known NullValue enum, which is
+// rendered as a JSON null rather than by name.
+const nullValueName = "google.protobuf.NullValue"
+
+// maxSafeInt is the magnitude from which 64-bit integers are rendered as
+// strings, since larger values cannot be held exactly by a javascript number.
+const maxSafeInt = 1 << 53
+
+// wrapperNames holds the full names of the well-known wrapper messages, which
+// are rendered as their bare value.
+var wrapperNames = map[protoreflect.FullName]bool{
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt64Value": true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.BytesValue":  true,
+}
+
+// HashField is the default rule deciding which bytes fields hold hashes.  It
+// matches fields named hash or hashes, fields ending in _hash or _hashes and the
+// previous_block and merkle_root fields of block headers, which covers the
+// hashes in the bchrpc messages.  The bytes values of a map field are hashes
+// when the rule matches the map field.
+func HashField(fd protoreflect.FieldDescriptor) bool {
+	name := string(fd.Name())
+	switch {
+	case name == "hash", name == "hashes":
+		return true
+	case strings.HasSuffix(name, "_hash"), strings.HasSuffix(name, "_hashes"):
+		return true
+	case name == "previous_block", name == "merkle_root":
+		return true
+	}
+	return false
+}
+
+// converter rewrites the bytes fields of a decoded JSON object between the
+// base64 strings used by the protobuf JSON mapping and the hex strings used by
+// this package.
+//
+// When safeInts is set, 64-bit integers which the protobuf JSON mapping renders
+// as strings are rendered as numbers if a javascript number holds them exactly,
+// matching the integers written by the OpenBazaar marshaler.
+//
+// When zeroEnums is set, enum fields left out for having the zero value are
+// added, as the OpenBazaar marshaler writes them.
+type converter struct {
+	isHash    func(protoreflect.FieldDescriptor) bool
+	toHex     bool
+	safeInts  bool
+	zeroEnums *enumOptions
+}
+
+// enumOptions describes how the enum fields added by a converter are written.
+type enumOptions struct {
+	asInts   bool
+	origName bool
+}
+
+// marshal converts the protobuf JSON encoding of a message described by md to
+// hex and indents it with indent.
+func (c *converter) marshal(data []byte, md protoreflect.MessageDescriptor, indent string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	v, err := c.root(v, md)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(v, "", indent)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// unmarshal reads the next JSON value holding a message described by md from
+// dec and returns it converted to the protobuf JSON encoding.
+func (c *converter) unmarshal(dec *json.Decoder, md protoreflect.MessageDescriptor) ([]byte, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	v, err := c.root(v, md)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// root returns the JSON value v of a whole message described by md with its
+// bytes converted.
+func (c *converter) root(v interface{}, md protoreflect.MessageDescriptor) (interface{}, error) {
+	if wrapperNames[md.FullName()] {
+		return c.value(v, md.Fields().ByNumber(1))
+	}
+	return v, c.message(v, md)
+}
+
+// message converts the bytes fields of the JSON object v holding a message
+// described by md.  Fields set to null are removed.
+func (c *converter) message(v interface{}, md protoreflect.MessageDescriptor) error {
+	if md.FullName() == anyName {
+		return c.any(v)
+	}
+	if strings.HasPrefix(string(md.FullName()), "google.protobuf.") {
+		// The remaining well-known types have their own JSON forms
+		// without bytes.
+		return nil
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%v: %s is not an object", ErrBadJSON,
+			md.FullName())
+	}
+	fields := md.Fields()
+	for k, fv := range obj {
+		if fv == nil {
+			delete(obj, k)
+			continue
+		}
+		fd := fields.ByJSONName(k)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(k))
+		}
+		if fd == nil {
+			// Unknown fields are left to the protobuf decoder.
+			continue
+		}
+		nv, err := c.field(fv, fd)
+		if err != nil {
+			return err
+		}
+		obj[k] = nv
+	}
+	if c.zeroEnums != nil {
+		c.addZeroEnums(obj, md)
+	}
+	return nil
+}
+
+// addZeroEnums adds the enum fields of the JSON object obj holding a message
+// described by md which were left out for having the zero value.
+func (c *converter) addZeroEnums(obj map[string]interface{}, md protoreflect.MessageDescriptor) {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.EnumKind || fd.IsList() ||
+			fd.IsMap() || fd.HasPresence() ||
+			fd.Enum().FullName() == nullValueName {
+
+			continue
+		}
+		if _, ok := obj[fd.JSONName()]; ok {
+			continue
+		}
+		if _, ok := obj[string(fd.Name())]; ok {
+			continue
+		}
+
+		key := fd.JSONName()
+		if c.zeroEnums.origName {
+			key = string(fd.Name())
+		}
+		zero := fd.Enum().Values().ByNumber(0)
+		if c.zeroEnums.asInts || zero == nil {
+			obj[key] = json.Number("0")
+		} else {
+			obj[key] = string(zero.Name())
+		}
+	}
+}
+
+// field returns the JSON value fv of the field described by fd with its bytes
+// converted.
+func (c *converter) field(fv interface{}, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	switch {
+	case fd.IsMap():
+		obj, ok := fv.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: %s is not an object", ErrBadJSON,
+				fd.FullName())
+		}
+		valueFd := fd.MapValue()
+		for k, ev := range obj {
+			var nv interface{}
+			var err error
+			if valueFd.Kind() == protoreflect.BytesKind {
+				// Bytes map values follow the hash rule of the
+				// map field itself.
+				nv, err = c.bytes(ev, fd)
+			} else {
+				nv, err = c.value(ev, valueFd)
+			}
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = nv
+		}
+		return obj, nil
+
+	case fd.IsList():
+		list, ok := fv.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: %s is not an array", ErrBadJSON,
+				fd.FullName())
+		}
+		for i, ev := range list {
+			nv, err := c.value(ev, fd)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = nv
+		}
+		return list, nil
+	}
+	return c.value(fv, fd)
+}
+
+// value returns a single JSON value v of the field described by fd, such as
+// an element of a repeated field, with its bytes converted.
+func (c *converter) value(v interface{}, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	if v == nil {
+		return v, nil
+	}
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		return c.bytes(v, fd)
+
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return c.root(v, fd.Message())
+
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind, protoreflect.Uint64Kind,
+		protoreflect.Fixed64Kind:
+
+		return c.int64(v), nil
+	}
+	return v, nil
+}
+
+// any converts the bytes fields of the JSON object v holding an Any message.
+// The embedded message is found from the type URL in the global registry, and
+// an Any holding a type which is not registered is left for the protobuf
+// decoder to reject.
+func (c *converter) any(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%v: %s is not an object", ErrBadJSON, anyName)
+	}
+	url, _ := obj["@type"].(string)
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(url)
+	if err != nil {
+		return nil
+	}
+
+	// Well-known types are embedded under a value field, while the fields
+	// of other messages sit alongside the type URL.
+	md := mt.Descriptor()
+	if strings.HasPrefix(string(md.FullName()), "google.protobuf.") {
+		value, ok := obj["value"]
+		if !ok {
+			return nil
+		}
+		nv, err := c.root(value, md)
+		if err != nil {
+			return err
+		}
+		obj["value"] = nv
+		return nil
+	}
+	return c.message(obj, md)
+}
+
+// int64 returns the JSON value v of a 64-bit integer field as a number when
+// safeInts is set and the value is held exactly by a javascript number.
+func (c *converter) int64(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !c.safeInts || !ok {
+		return v
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err == nil && n > -maxSafeInt && n < maxSafeInt {
+		return json.Number(s)
+	}
+	return v
+}
+
+// bytes converts the JSON string v of the bytes field described by fd.
+func (c *converter) bytes(v interface{}, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%v: %s is not a string", ErrBadJSON,
+			fd.FullName())
+	}
+	isHash := c.isHash != nil && c.isHash(fd)
+
+	if c.toHex {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s: %v", ErrBadJSON,
+				fd.FullName(), err)
+		}
+		if isHash && len(b) == chainhash.HashSize {
+			var hash chainhash.Hash
+			copy(hash[:], b)
+			return hash.String(), nil
+		}
+		return hex.EncodeToString(b), nil
+	}
+
+	if isHash && len(s) == chainhash.MaxHashStringSize {
+		hash, err := chainhash.NewHashFromStr(s)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s: %v", ErrBadBytes,
+				fd.FullName(), err)
+		}
+		return base64.StdEncoding.EncodeToString(hash[:]), nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s: %v", ErrBadBytes, fd.FullName(),
+			err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
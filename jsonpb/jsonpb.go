@@ -2,13 +2,12 @@ package jsonpb
 
 import (
 	"bytes"
-	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
-	"github.com/gcash/bchd/chaincfg/chainhash"
-	"github.com/golang/protobuf/proto" //nolint:staticcheck // OpenBazaar jsonpb API uses github.com/golang/protobuf's proto.Message
 	"io"
 
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // OpenBazaar jsonpb API uses github.com/golang/protobuf's proto.Message
+	"google.golang.org/protobuf/reflect/protoreflect"
+
 	// The OpenBazaar fork of this package better handles large integers. Normally
 	// the package will marshal everything over 32 bits as a string. Whereas this
 	// fork allows integers up to the maximum int handled by javascript of 53 bits.
@@ -21,8 +20,10 @@ import (
 //
 // The original jsonpb marshaler will marshal bytes as base64
 // strings. For Bitcoin we obviously prefer hex strings. This
-// marshaler will also convert all 32 byte litte endian arrays
-// to big endian hex strings.
+// marshaler uses the field descriptors of the message to render
+// every bytes field as hex, and the 32 byte little endian hashes
+// held by hash fields as big endian hex strings. Fields of other
+// types are left untouched.
 type Marshaler struct {
 	// Whether to render enum values as integers, as opposed to string values.
 	EnumsAsInts bool
@@ -38,34 +39,21 @@ type Marshaler struct {
 
 	// Whether to use the original (.proto) name for fields.
 	OrigName bool
+
+	// IsHash reports whether a bytes field holds hashes, which are
+	// rendered in reversed byte order. Values of other lengths held by
+	// a hash field are rendered as plain hex. When nil, HashField is
+	// used.
+	IsHash func(fd protoreflect.FieldDescriptor) bool
 }
 
 // Marshal marshals a protocol buffer into JSON.
 func (m *Marshaler) Marshal(out io.Writer, pb proto.Message) error {
-	marshaler := jsonpb.Marshaler{
-		EnumsAsInts:  m.EnumsAsInts,
-		EmitDefaults: m.EmitDefaults,
-		Indent:       m.Indent,
-		OrigName:     m.OrigName,
-	}
-
-	s, err := marshaler.MarshalToString(pb)
-	if err != nil {
-		return err
-	}
-
-	var v interface{}
-	if err := json.Unmarshal([]byte(s), &v); err != nil {
-		return err
-	}
-
-	convertBase64(v)
-
-	r, err := json.MarshalIndent(v, "", m.Indent)
+	s, err := m.MarshalToString(pb)
 	if err != nil {
 		return err
 	}
-	_, err = out.Write(r)
+	_, err = io.WriteString(out, s)
 	return err
 }
 
@@ -74,7 +62,6 @@ func (m *Marshaler) MarshalToString(pb proto.Message) (string, error) {
 	marshaler := jsonpb.Marshaler{
 		EnumsAsInts:  m.EnumsAsInts,
 		EmitDefaults: m.EmitDefaults,
-		Indent:       m.Indent,
 		OrigName:     m.OrigName,
 	}
 
@@ -83,18 +70,9 @@ func (m *Marshaler) MarshalToString(pb proto.Message) (string, error) {
 		return "", err
 	}
 
-	var v interface{}
-	if err := json.Unmarshal([]byte(s), &v); err != nil {
-		return "", err
-	}
-
-	convertBase64(v)
-
-	out, err := json.MarshalIndent(v, "", m.Indent)
-	if err != nil {
-		return "", err
-	}
-	return string(out), nil
+	c := &converter{isHash: hashRule(m.IsHash), toHex: true}
+	return c.marshal([]byte(s), proto.MessageReflect(pb).Descriptor(),
+		m.Indent)
 }
 
 // Unmarshaler is a configurable object for converting from a JSON
@@ -103,21 +81,21 @@ type Unmarshaler struct {
 	// Whether to allow messages to contain unknown fields, as opposed to
 	// failing to unmarshal.
 	AllowUnknownFields bool
+
+	// IsHash reports whether a bytes field holds hashes, which are
+	// parsed from reversed hex strings. When nil, HashField is used.
+	// It must match the rule used to marshal the JSON.
+	IsHash func(fd protoreflect.FieldDescriptor) bool
 }
 
 // UnmarshalNext unmarshals the next protocol buffer from a JSON object stream.
 // This function is lenient and will decode any options permutations of the
-// related Marshaler.
+// related Marshaler. Bytes fields must hold hex strings, and hash fields
+// either a hash string or, for values which are not hashes, plain hex,
+// otherwise an error wrapping ErrBadBytes is returned.
 func (u *Unmarshaler) UnmarshalNext(dec *json.Decoder, pb proto.Message) error {
-
-	var i interface{}
-	if err := dec.Decode(&i); err != nil {
-		return err
-	}
-
-	convertHex(i)
-
-	out, err := json.Marshal(&i)
+	c := &converter{isHash: hashRule(u.IsHash)}
+	out, err := c.unmarshal(dec, proto.MessageReflect(pb).Descriptor())
 	if err != nil {
 		return err
 	}
@@ -150,108 +128,14 @@ func UnmarshalNext(dec *json.Decoder, pb proto.Message) error {
 // buffer. This function is lenient and will decode any options
 // permutations of the related Marshaler.
 func Unmarshal(r io.Reader, pb proto.Message) error {
-	u := &Unmarshaler{true}
+	u := &Unmarshaler{AllowUnknownFields: true}
 	return u.Unmarshal(r, pb)
 }
 
-func convertBase64(data interface{}) {
-	switch d := data.(type) {
-	case map[string]interface{}:
-		for k, v := range d {
-			switch tv := v.(type) {
-			case string:
-				decoded, err := base64.StdEncoding.DecodeString(tv)
-				if err == nil && len(decoded) == 32 {
-					ch, err := chainhash.NewHash(decoded)
-					if err == nil {
-						d[k] = ch.String()
-					}
-				} else if err == nil {
-					d[k] = hex.EncodeToString(decoded)
-				}
-			case map[string]interface{}:
-				convertBase64(tv)
-			case []interface{}:
-				convertBase64(tv)
-			case nil:
-				delete(d, k)
-			}
-		}
-	case []interface{}:
-		if len(d) > 0 {
-			switch d[0].(type) {
-			case string:
-				for i, s := range d {
-					decoded, err := base64.StdEncoding.DecodeString(s.(string))
-					if err == nil && len(decoded) == 32 {
-						ch, err := chainhash.NewHash(decoded)
-						if err == nil {
-							d[i] = ch.String()
-						}
-					} else if err == nil {
-						d[i] = hex.EncodeToString(decoded)
-					}
-				}
-			case map[string]interface{}:
-				for _, t := range d {
-					convertBase64(t)
-				}
-			case []interface{}:
-				for _, t := range d {
-					convertBase64(t)
-				}
-			}
-		}
-	}
-}
-
-func convertHex(data interface{}) {
-	switch d := data.(type) {
-	case map[string]interface{}:
-		for k, v := range d {
-			switch tv := v.(type) {
-			case string:
-				ch, err := chainhash.NewHashFromStr(tv)
-				if err == nil && len(tv) == 64 {
-					d[k] = base64.StdEncoding.EncodeToString(ch.CloneBytes())
-					continue
-				}
-				decoded, err := hex.DecodeString(tv)
-				if err == nil {
-					d[k] = base64.StdEncoding.EncodeToString(decoded)
-				}
-			case map[string]interface{}:
-				convertHex(tv)
-			case []interface{}:
-				convertHex(tv)
-			case nil:
-				delete(d, k)
-			}
-		}
-	case []interface{}:
-		if len(d) > 0 {
-			switch d[0].(type) {
-			case string:
-				for i, s := range d {
-					ch, err := chainhash.NewHashFromStr(s.(string))
-					if err == nil && len(s.(string)) == 64 {
-						d[i] = base64.StdEncoding.EncodeToString(ch.CloneBytes())
-						continue
-					}
-					decoded, err := hex.DecodeString(s.(string))
-					if err == nil {
-						d[i] = base64.StdEncoding.EncodeToString(decoded)
-					}
-				}
-			case map[string]interface{}:
-				for _, t := range d {
-					convertHex(t)
-				}
-			case []interface{}:
-				for _, t := range d {
-					convertHex(t)
-				}
-			}
-		}
+// hashRule returns the passed hash field rule, or HashField when it is nil.
+func hashRule(isHash func(protoreflect.FieldDescriptor) bool) func(protoreflect.FieldDescriptor) bool {
+	if isHash == nil {
+		return HashField
 	}
+	return isHash
 }
@@ -2,8 +2,13 @@ package jsonpb
 
 import (
 	"bytes"
-	pb "github.com/gcash/bchutil/jsonpb/testpb"
+	"encoding/json"
+	"strings"
 	"testing"
+
+	pb "github.com/gcash/bchutil/jsonpb/testpb"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // testpb is generated with github.com/golang/protobuf
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 var testMarshaledTransaction = `{
@@ -63,3 +68,137 @@ func TestUnmarshalAndMarshall(t *testing.T) {
 		t.Errorf("Failed to produce idential JSON")
 	}
 }
+
+func TestMarshalBytesFields(t *testing.T) {
+	hash := make([]byte, 32)
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+	reversed := "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	plain := "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	notHash := func(protoreflect.FieldDescriptor) bool { return false }
+
+	tests := []struct {
+		name   string
+		pb     proto.Message
+		isHash func(protoreflect.FieldDescriptor) bool
+		want   string
+	}{
+		{
+			name: "32 byte script is not a hash",
+			pb:   &pb.Transaction_Output{PubkeyScript: hash},
+			want: `{"pubkeyScript":"` + plain + `"}`,
+		},
+		{
+			name: "base64 string field is untouched",
+			pb: &pb.Transaction_Output{
+				DisassembledScript: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+			},
+			want: `{"disassembledScript":"AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="}`,
+		},
+		{
+			name: "block hashes are reversed",
+			pb: &pb.BlockInfo{
+				Hash:          hash,
+				PreviousBlock: hash,
+				MerkleRoot:    hash,
+			},
+			want: `{"hash":"` + reversed + `","merkleRoot":"` + reversed +
+				`","previousBlock":"` + reversed + `"}`,
+		},
+		{
+			name: "repeated hashes are reversed",
+			pb: &pb.GetMerkleProofResponse{
+				Hashes: [][]byte{hash, hash},
+				Flags:  []byte{0x1d},
+			},
+			want: `{"flags":"1d","hashes":["` + reversed + `","` +
+				reversed + `"]}`,
+		},
+		{
+			name: "short value of hash field is plain hex",
+			pb:   &pb.Transaction_Input_Outpoint{Hash: []byte{0x01, 0x02}},
+			want: `{"hash":"0102"}`,
+		},
+		{
+			name:   "custom hash rule",
+			pb:     &pb.Transaction_Input_Outpoint{Hash: hash, Index: 1},
+			isHash: notHash,
+			want:   `{"hash":"` + plain + `","index":1}`,
+		},
+	}
+
+	for _, test := range tests {
+		m := Marshaler{IsHash: test.isHash}
+		s, err := m.MarshalToString(test.pb)
+		if err != nil {
+			t.Errorf("%s: MarshalToString: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(s)); err != nil {
+			t.Errorf("%s: Compact: unexpected error: %v", test.name, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, buf.String(),
+				test.want)
+		}
+
+		// The matching unmarshaler restores the exact message.
+		u := Unmarshaler{IsHash: test.isHash}
+		got := proto.Clone(test.pb)
+		got.Reset()
+		if err := u.Unmarshal(strings.NewReader(s), got); err != nil {
+			t.Errorf("%s: Unmarshal: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if !proto.Equal(got, test.pb) {
+			t.Errorf("%s: round trip got %v, want %v", test.name, got,
+				test.pb)
+		}
+	}
+}
+
+func TestUnmarshalBytesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		err  error
+	}{
+		{
+			name: "bad hex",
+			json: `{"signatureScript":"0g"}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "odd length hex",
+			json: `{"signatureScript":"012"}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "base64",
+			json: `{"signatureScript":"AQI="}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "bad hash",
+			json: `{"outpoint":{"hash":"` + strings.Repeat("zz", 32) + `"}}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "bytes field holding a number",
+			json: `{"signatureScript":12}`,
+			err:  ErrBadJSON,
+		},
+	}
+
+	for _, test := range tests {
+		err := Unmarshal(strings.NewReader(test.json), &pb.Transaction_Input{})
+		if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+}
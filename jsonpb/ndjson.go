@@ -0,0 +1,129 @@
+package jsonpb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // OpenBazaar jsonpb API uses github.com/golang/protobuf's proto.Message
+	"google.golang.org/grpc"
+)
+
+// Encoder writes protocol buffers to an output stream as newline delimited
+// JSON, with each message on its own line in the hex form of Marshaler.
+type Encoder struct {
+	w   io.Writer
+	m   *Marshaler
+	buf bytes.Buffer
+}
+
+// NewEncoder returns a new encoder that writes to w using the options of m.
+// The Indent option is ignored since every message must fit on one line. When
+// m is nil the default options are used.
+func NewEncoder(w io.Writer, m *Marshaler) *Encoder {
+	if m == nil {
+		m = new(Marshaler)
+	}
+	return &Encoder{w: w, m: m}
+}
+
+// Encode writes pb to the stream as a single line of JSON.
+func (e *Encoder) Encode(pb proto.Message) error {
+	s, err := e.m.MarshalToString(pb)
+	if err != nil {
+		return err
+	}
+	e.buf.Reset()
+	if err := json.Compact(&e.buf, []byte(s)); err != nil {
+		return err
+	}
+	e.buf.WriteByte('\n')
+	_, err = e.w.Write(e.buf.Bytes())
+	return err
+}
+
+// EncodeStream receives messages from a gRPC server stream, such as the block
+// and transaction subscriptions of bchrpc, and writes each one to the output
+// stream as it arrives.  newMsg returns an empty message of the type sent by
+// the server.  It returns the number of messages written, with a nil error once
+// the server ends the stream.
+//
+// Messages are received one at a time and the next is not received until the
+// last has been written, so a slow writer applies backpressure to the server
+// through gRPC flow control.  When ctx is done EncodeStream returns ctx.Err().
+// A receive which is still blocked is only released by canceling the context
+// the stream was created with, so that context should be derived from ctx.
+func (e *Encoder) EncodeStream(ctx context.Context, stream grpc.ClientStream,
+	newMsg func() proto.Message) (int, error) {
+
+	type result struct {
+		msg proto.Message
+		err error
+	}
+	results := make(chan result)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			msg := newMsg()
+			err := stream.RecvMsg(msg)
+			select {
+			case results <- result{msg, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var n int
+	for {
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+
+		case r := <-results:
+			if r.err == io.EOF {
+				return n, nil
+			}
+			if r.err != nil {
+				return n, r.err
+			}
+			if err := e.Encode(r.msg); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+}
+
+// Decoder reads protocol buffers from newline delimited JSON, such as the
+// output of Encoder.
+type Decoder struct {
+	dec *json.Decoder
+	u   *Unmarshaler
+}
+
+// NewDecoder returns a new decoder that reads from r using the options of u.
+// When u is nil the default options are used.
+func NewDecoder(r io.Reader, u *Unmarshaler) *Decoder {
+	if u == nil {
+		u = new(Unmarshaler)
+	}
+	return &Decoder{dec: json.NewDecoder(r), u: u}
+}
+
+// More reports whether there is another message in the stream.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Decode reads the next message from the stream into pb.  It returns io.EOF
+// once the stream has no more messages.
+func (d *Decoder) Decode(pb proto.Message) error {
+	return d.u.UnmarshalNext(d.dec, pb)
+}
@@ -0,0 +1,203 @@
+package jsonpb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/gcash/bchutil/jsonpb/testpb"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // testpb is generated with github.com/golang/protobuf
+	"google.golang.org/grpc"
+)
+
+// testStream is a grpc.ClientStream which returns msgs in order and then
+// either err or, when block is set, waits for its context to be done.
+type testStream struct {
+	grpc.ClientStream
+	ctx   context.Context
+	msgs  []proto.Message
+	err   error
+	block bool
+	recvs int32
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) RecvMsg(m interface{}) error {
+	i := atomic.AddInt32(&s.recvs, 1) - 1
+	if int(i) < len(s.msgs) {
+		proto.Merge(m.(proto.Message), s.msgs[i])
+		return nil
+	}
+	if s.block {
+		<-s.ctx.Done()
+		return s.ctx.Err()
+	}
+	return s.err
+}
+
+// blockNotifications returns a sequence of block notifications.
+func blockNotifications(n int) []proto.Message {
+	msgs := make([]proto.Message, n)
+	for i := range msgs {
+		hash := make([]byte, 32)
+		hash[0] = byte(i)
+		msgs[i] = &pb.BlockNotification{
+			Type: pb.BlockNotification_CONNECTED,
+			Block: &pb.BlockInfo{
+				Hash:          hash,
+				Height:        int32(i),
+				PreviousBlock: make([]byte, 32),
+				Timestamp:     1555442269 + int64(i),
+			},
+		}
+	}
+	return msgs
+}
+
+func newBlockNotification() proto.Message {
+	return new(pb.BlockNotification)
+}
+
+func TestEncodeStream(t *testing.T) {
+	msgs := blockNotifications(3)
+	stream := &testStream{ctx: context.Background(), msgs: msgs, err: io.EOF}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, &Marshaler{Indent: "  "})
+	n, err := enc.EncodeStream(context.Background(), stream,
+		newBlockNotification)
+	if err != nil {
+		t.Fatalf("EncodeStream: unexpected error: %v", err)
+	}
+	if n != len(msgs) {
+		t.Errorf("EncodeStream: wrote %d messages, want %d", n, len(msgs))
+	}
+
+	// Each message is a complete JSON object on its own line.
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	var lines int
+	for scanner.Scan() {
+		var v map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			t.Errorf("line %d: unexpected error: %v", lines, err)
+		}
+		lines++
+	}
+	if lines != len(msgs) {
+		t.Errorf("got %d lines, want %d", lines, len(msgs))
+	}
+	want := `{"block":{"hash":"` + strings.Repeat("00", 31) + `00",` +
+		`"previousBlock":"` + strings.Repeat("00", 32) + `",` +
+		`"timestamp":1555442269},"type":"CONNECTED"}` + "\n"
+	if line := strings.SplitAfter(buf.String(), "\n")[0]; line != want {
+		t.Errorf("got line %s, want %s", line, want)
+	}
+
+	// The decoder restores the exact messages.
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()), nil)
+	for i := 0; dec.More(); i++ {
+		got := new(pb.BlockNotification)
+		if err := dec.Decode(got); err != nil {
+			t.Fatalf("Decode #%d: unexpected error: %v", i, err)
+		}
+		if !proto.Equal(got, msgs[i]) {
+			t.Errorf("Decode #%d: got %v, want %v", i, got, msgs[i])
+		}
+	}
+	if err := dec.Decode(new(pb.BlockNotification)); err != io.EOF {
+		t.Errorf("Decode: got error %v, want %v", err, io.EOF)
+	}
+
+	// Errors ending the stream are returned.
+	streamErr := errors.New("stream reset")
+	stream = &testStream{ctx: context.Background(), msgs: msgs[:1],
+		err: streamErr}
+	n, err = NewEncoder(io.Discard, nil).EncodeStream(context.Background(),
+		stream, newBlockNotification)
+	if n != 1 || err != streamErr {
+		t.Errorf("EncodeStream: got %d, %v, want 1, %v", n, err, streamErr)
+	}
+}
+
+func TestEncodeStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &testStream{ctx: ctx, msgs: blockNotifications(2), block: true}
+
+	w := &signalWriter{wrote: make(chan struct{}, 2)}
+	errs := make(chan error, 1)
+	go func() {
+		_, err := NewEncoder(w, nil).EncodeStream(ctx, stream,
+			newBlockNotification)
+		errs <- err
+	}()
+
+	<-w.wrote
+	<-w.wrote
+	cancel()
+	select {
+	case err := <-errs:
+		if err != context.Canceled {
+			t.Errorf("EncodeStream: got error %v, want %v", err,
+				context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("EncodeStream: not canceled")
+	}
+}
+
+func TestEncodeStreamBackpressure(t *testing.T) {
+	stream := &testStream{ctx: context.Background(),
+		msgs: blockNotifications(10), err: io.EOF}
+
+	w := &signalWriter{wrote: make(chan struct{}, 10),
+		release: make(chan struct{})}
+	errs := make(chan error, 1)
+	go func() {
+		_, err := NewEncoder(w, nil).EncodeStream(context.Background(),
+			stream, newBlockNotification)
+		errs <- err
+	}()
+
+	// While the first write is blocked at most one further message is
+	// received.
+	<-w.wrote
+	time.Sleep(50 * time.Millisecond)
+	if recvs := atomic.LoadInt32(&stream.recvs); recvs > 2 {
+		t.Errorf("received %d messages while the writer was blocked",
+			recvs)
+	}
+	close(w.release)
+	if err := <-errs; err != nil {
+		t.Errorf("EncodeStream: unexpected error: %v", err)
+	}
+}
+
+// signalWriter signals each write on wrote and, when release is set, blocks
+// writes until it is closed.
+type signalWriter struct {
+	mtx     sync.Mutex
+	wrote   chan struct{}
+	release chan struct{}
+}
+
+func (w *signalWriter) Write(b []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.wrote <- struct{}{}
+	if w.release != nil {
+		<-w.release
+	}
+	return len(b), nil
+}
@@ -0,0 +1,116 @@
+package jsonpb
+
+import (
+	"encoding/json"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MarshalerV2 is a configurable object for converting messages of the
+// google.golang.org/protobuf API, such as those generated by recent versions
+// of protoc-gen-go, to JSON.
+//
+// It is built on protojson and the protoreflect API and follows the same
+// conventions as Marshaler. Bytes fields are rendered as hex and hash fields
+// as reversed hashes, including within maps, oneofs and the messages embedded
+// in Any. Well-known types keep their standard JSON forms. As with Marshaler,
+// 64-bit integers are rendered as numbers when a javascript number holds them
+// exactly and enum fields are written even when they hold the zero value.
+type MarshalerV2 struct {
+	// Whether to render enum values as integers, as opposed to string values.
+	EnumsAsInts bool
+
+	// Whether to render fields with zero values.
+	EmitDefaults bool
+
+	// A string to indent each level by. The presence of this field will
+	// also cause a space to appear between the field separator and
+	// value, and for newlines to be appear between fields and array
+	// elements.
+	Indent string
+
+	// Whether to use the original (.proto) name for fields.
+	OrigName bool
+
+	// IsHash reports whether a bytes field holds hashes, which are
+	// rendered in reversed byte order. Values of other lengths held by
+	// a hash field are rendered as plain hex. When nil, HashField is
+	// used.
+	IsHash func(fd protoreflect.FieldDescriptor) bool
+}
+
+// Marshal marshals a protocol buffer into JSON.
+func (m *MarshalerV2) Marshal(out io.Writer, pb protov2.Message) error {
+	s, err := m.MarshalToString(pb)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, s)
+	return err
+}
+
+// MarshalToString converts a protocol buffer object to JSON string.
+func (m *MarshalerV2) MarshalToString(pb protov2.Message) (string, error) {
+	marshaler := protojson.MarshalOptions{
+		UseEnumNumbers:  m.EnumsAsInts,
+		EmitUnpopulated: m.EmitDefaults,
+		UseProtoNames:   m.OrigName,
+	}
+
+	b, err := marshaler.Marshal(pb)
+	if err != nil {
+		return "", err
+	}
+
+	c := &converter{isHash: hashRule(m.IsHash), toHex: true, safeInts: true}
+	if !m.EmitDefaults {
+		c.zeroEnums = &enumOptions{
+			asInts:   m.EnumsAsInts,
+			origName: m.OrigName,
+		}
+	}
+	return c.marshal(b, pb.ProtoReflect().Descriptor(), m.Indent)
+}
+
+// UnmarshalerV2 is a configurable object for converting from a JSON
+// representation to a protocol buffer object of the google.golang.org/protobuf
+// API.
+type UnmarshalerV2 struct {
+	// Whether to allow messages to contain unknown fields, as opposed to
+	// failing to unmarshal.
+	AllowUnknownFields bool
+
+	// IsHash reports whether a bytes field holds hashes, which are
+	// parsed from reversed hex strings. When nil, HashField is used.
+	// It must match the rule used to marshal the JSON.
+	IsHash func(fd protoreflect.FieldDescriptor) bool
+}
+
+// UnmarshalNext unmarshals the next protocol buffer from a JSON object stream.
+// It decodes the output of MarshalerV2 and Marshaler with any options. Bytes
+// fields must hold hex strings, and hash fields either a hash string or, for
+// values which are not hashes, plain hex, otherwise an error wrapping
+// ErrBadBytes is returned.
+func (u *UnmarshalerV2) UnmarshalNext(dec *json.Decoder, pb protov2.Message) error {
+	c := &converter{isHash: hashRule(u.IsHash)}
+	b, err := c.unmarshal(dec, pb.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+
+	unmarshaler := protojson.UnmarshalOptions{
+		DiscardUnknown: u.AllowUnknownFields,
+	}
+	return unmarshaler.Unmarshal(b, pb)
+}
+
+// Unmarshal unmarshals a JSON object stream into a protocol
+// buffer. It decodes the output of MarshalerV2 and Marshaler with
+// any options.
+func (u *UnmarshalerV2) Unmarshal(r io.Reader, pb protov2.Message) error {
+	dec := json.NewDecoder(r)
+	return u.UnmarshalNext(dec, pb)
+}
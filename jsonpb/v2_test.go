@@ -0,0 +1,378 @@
+package jsonpb
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/gcash/bchutil/jsonpb/testpb"
+	protov1 "github.com/golang/protobuf/proto" //nolint:staticcheck // testpb is generated with github.com/golang/protobuf
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// holderDescriptor returns the descriptor of a message exercising maps,
+// oneofs, enums, 64-bit integers and well-known types:
+//
+//	message Holder {
+//	    enum Kind { UNKNOWN = 0; OTHER = 1; }
+//	    message Outpoint { bytes hash = 1; uint32 index = 2; }
+//	    map<string, bytes> block_hashes = 1;
+//	    map<int32, Outpoint> outpoints = 2;
+//	    oneof id { bytes tx_hash = 3; bytes script = 4; }
+//	    int64 small = 5;
+//	    uint64 large = 6;
+//	    Kind kind = 7;
+//	    google.protobuf.BytesValue data = 8;
+//	    google.protobuf.Timestamp time = 9;
+//	}
+func holderDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type,
+		typeName string) *descriptorpb.FieldDescriptorProto {
+
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(jsonName(name)),
+			Number:   proto.Int32(num),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	inOneof := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.OneofIndex = proto.Int32(0)
+		return f
+	}
+	mapEntry := func(name string, key, value *descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name:    proto.String(name),
+			Field:   []*descriptorpb.FieldDescriptorProto{key, value},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+	}
+
+	const (
+		typeBytes   = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+		typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		typeInt32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		typeUint32  = descriptorpb.FieldDescriptorProto_TYPE_UINT32
+		typeInt64   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		typeUint64  = descriptorpb.FieldDescriptorProto_TYPE_UINT64
+		typeEnum    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+		typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("jsonpb/holder.proto"),
+		Package: proto.String("jsonpbtest"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"google/protobuf/wrappers.proto",
+			"google/protobuf/timestamp.proto",
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Holder"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				repeated(field("block_hashes", 1, typeMessage,
+					".jsonpbtest.Holder.BlockHashesEntry")),
+				repeated(field("outpoints", 2, typeMessage,
+					".jsonpbtest.Holder.OutpointsEntry")),
+				inOneof(field("tx_hash", 3, typeBytes, "")),
+				inOneof(field("script", 4, typeBytes, "")),
+				field("small", 5, typeInt64, ""),
+				field("large", 6, typeUint64, ""),
+				field("kind", 7, typeEnum, ".jsonpbtest.Holder.Kind"),
+				field("data", 8, typeMessage, ".google.protobuf.BytesValue"),
+				field("time", 9, typeMessage, ".google.protobuf.Timestamp"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Outpoint"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("hash", 1, typeBytes, ""),
+						field("index", 2, typeUint32, ""),
+					},
+				},
+				mapEntry("BlockHashesEntry",
+					field("key", 1, typeString, ""),
+					field("value", 2, typeBytes, "")),
+				mapEntry("OutpointsEntry",
+					field("key", 1, typeInt32, ""),
+					field("value", 2, typeMessage,
+						".jsonpbtest.Holder.Outpoint")),
+			},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("Kind"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("OTHER"), Number: proto.Int32(1)},
+				},
+			}},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{
+				{Name: proto.String("id")},
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("NewFile: unexpected error: %v", err)
+	}
+	return fd.Messages().ByName("Holder")
+}
+
+// jsonName returns the JSON name protoc assigns to a field name.
+func jsonName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func TestMarshalerV2MatchesV1(t *testing.T) {
+	tx := &pb.TransactionNotification{}
+	if err := Unmarshal(strings.NewReader(testMarshaledTransaction), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	m := MarshalerV2{Indent: "    "}
+	s, err := m.MarshalToString(protov1.MessageV2(tx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != testMarshaledTransaction {
+		t.Errorf("Failed to produce identical JSON, got %s", s)
+	}
+
+	got := &pb.TransactionNotification{}
+	u := UnmarshalerV2{}
+	if err := u.Unmarshal(strings.NewReader(s), protov1.MessageV2(got)); err != nil {
+		t.Fatal(err)
+	}
+	if !protov1.Equal(got, tx) {
+		t.Errorf("round trip got %v, want %v", got, tx)
+	}
+}
+
+func TestMarshalerV2(t *testing.T) {
+	hash := make([]byte, 32)
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+	reversed := "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	plain := "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+	md := holderDescriptor(t)
+	holder := func(set func(m protoreflect.Message)) proto.Message {
+		m := dynamicpb.NewMessage(md)
+		set(m)
+		return m
+	}
+	fields := md.Fields()
+	outpointMd := fields.ByName("outpoints").MapValue().Message()
+
+	anyBlock, err := anypb.New(protov1.MessageV2(&pb.BlockInfo{Hash: hash, Height: 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	anyBytes, err := anypb.New(wrapperspb.Bytes([]byte{0x01, 0x02}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := structpb.NewStruct(map[string]interface{}{"hash": plain})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		pb   proto.Message
+		want string
+	}{
+		{
+			name: "map of hashes",
+			pb: holder(func(m protoreflect.Message) {
+				mp := m.Mutable(fields.ByName("block_hashes")).Map()
+				mp.Set(protoreflect.ValueOfString("tip").MapKey(),
+					protoreflect.ValueOfBytes(hash))
+			}),
+			want: `{"blockHashes":{"tip":"` + reversed + `"},"kind":"UNKNOWN"}`,
+		},
+		{
+			name: "map of messages",
+			pb: holder(func(m protoreflect.Message) {
+				op := dynamicpb.NewMessage(outpointMd)
+				op.Set(outpointMd.Fields().ByName("hash"),
+					protoreflect.ValueOfBytes(hash))
+				op.Set(outpointMd.Fields().ByName("index"),
+					protoreflect.ValueOfUint32(3))
+				mp := m.Mutable(fields.ByName("outpoints")).Map()
+				mp.Set(protoreflect.ValueOfInt32(5).MapKey(),
+					protoreflect.ValueOfMessage(op))
+			}),
+			want: `{"kind":"UNKNOWN","outpoints":{"5":{"hash":"` +
+				reversed + `","index":3}}}`,
+		},
+		{
+			name: "oneof hash",
+			pb: holder(func(m protoreflect.Message) {
+				m.Set(fields.ByName("tx_hash"), protoreflect.ValueOfBytes(hash))
+			}),
+			want: `{"kind":"UNKNOWN","txHash":"` + reversed + `"}`,
+		},
+		{
+			name: "oneof script",
+			pb: holder(func(m protoreflect.Message) {
+				m.Set(fields.ByName("script"), protoreflect.ValueOfBytes(hash))
+			}),
+			want: `{"kind":"UNKNOWN","script":"` + plain + `"}`,
+		},
+		{
+			name: "64-bit integers",
+			pb: holder(func(m protoreflect.Message) {
+				m.Set(fields.ByName("small"), protoreflect.ValueOfInt64(-42))
+				m.Set(fields.ByName("large"),
+					protoreflect.ValueOfUint64(1<<60))
+				m.Set(fields.ByName("kind"),
+					protoreflect.ValueOfEnum(1))
+			}),
+			want: `{"kind":"OTHER","large":"1152921504606846976","small":-42}`,
+		},
+		{
+			name: "wrapper and timestamp fields",
+			pb: holder(func(m protoreflect.Message) {
+				m.Set(fields.ByName("data"), protoreflect.ValueOfMessage(
+					wrapperspb.Bytes([]byte{0xab}).ProtoReflect()))
+				m.Set(fields.ByName("time"), protoreflect.ValueOfMessage(
+					timestamppb.New(time.Unix(1555442269, 0)).ProtoReflect()))
+			}),
+			want: `{"data":"ab","kind":"UNKNOWN","time":"2019-04-16T19:17:49Z"}`,
+		},
+		{
+			name: "any holding a message",
+			pb:   anyBlock,
+			want: `{"@type":"type.googleapis.com/pb.BlockInfo","hash":"` +
+				reversed + `","height":7}`,
+		},
+		{
+			name: "any holding a well-known type",
+			pb:   anyBytes,
+			want: `{"@type":"type.googleapis.com/google.protobuf.BytesValue","value":"0102"}`,
+		},
+		{
+			name: "bytes wrapper",
+			pb:   wrapperspb.Bytes([]byte{0x01, 0x02}),
+			want: `"0102"`,
+		},
+		{
+			name: "int64 wrapper",
+			pb:   wrapperspb.Int64(1555442269),
+			want: `1555442269`,
+		},
+		{
+			name: "struct strings are untouched",
+			pb:   st,
+			want: `{"hash":"` + plain + `"}`,
+		},
+	}
+
+	for _, test := range tests {
+		m := MarshalerV2{}
+		s, err := m.MarshalToString(test.pb)
+		if err != nil {
+			t.Errorf("%s: MarshalToString: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(s)); err != nil {
+			t.Errorf("%s: Compact: unexpected error: %v", test.name, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, buf.String(),
+				test.want)
+		}
+
+		// The matching unmarshaler restores the exact message.
+		u := UnmarshalerV2{}
+		got := test.pb.ProtoReflect().New().Interface()
+		if err := u.Unmarshal(strings.NewReader(s), got); err != nil {
+			t.Errorf("%s: Unmarshal: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if !proto.Equal(got, test.pb) {
+			t.Errorf("%s: round trip got %v, want %v", test.name, got,
+				test.pb)
+		}
+	}
+}
+
+func TestUnmarshalerV2Errors(t *testing.T) {
+	md := holderDescriptor(t)
+
+	tests := []struct {
+		name string
+		json string
+		err  error
+	}{
+		{
+			name: "bad hex in map",
+			json: `{"blockHashes":{"tip":"zz"}}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "bad hash in oneof",
+			json: `{"txHash":"` + strings.Repeat("zz", 32) + `"}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "bad bytes wrapper",
+			json: `{"data":"0"}`,
+			err:  ErrBadBytes,
+		},
+		{
+			name: "map holding an array",
+			json: `{"blockHashes":["00"]}`,
+			err:  ErrBadJSON,
+		},
+	}
+
+	for _, test := range tests {
+		u := UnmarshalerV2{}
+		err := u.Unmarshal(strings.NewReader(test.json),
+			dynamicpb.NewMessage(md))
+		if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	// Unknown fields are rejected unless allowed.
+	u := UnmarshalerV2{}
+	err := u.Unmarshal(strings.NewReader(`{"other":1}`), dynamicpb.NewMessage(md))
+	if err == nil {
+		t.Errorf("Unmarshal: expected error for unknown field")
+	}
+	u.AllowUnknownFields = true
+	err = u.Unmarshal(strings.NewReader(`{"other":1}`), dynamicpb.NewMessage(md))
+	if err != nil {
+		t.Errorf("Unmarshal: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package merkle provides computation and verification of bitcoin cash merkle
+trees, branches and proofs.
+
+# Overview
+
+CalcRoot computes the merkle root of a list of leaf hashes, usually the txids
+of a block, and reports whether the tree was mutated by duplicating trailing
+leaves.  Branch returns the hashes needed to prove that the leaf at an index is
+committed to by the root and VerifyBranch checks such a branch.
+
+	branch, err := merkle.Branch(txids, index)
+	if err != nil {
+		return err
+	}
+	ok := merkle.VerifyBranch(txids[index], index, branch, root)
+
+# Partial Trees
+
+BuildPartialTree and ExtractPartialTree encode and decode the partial merkle
+trees carried by merkleblock messages.  They are shared by the bloom and
+merkleblock packages.
+
+# Proof Formats
+
+ElectrumProof marshals to and from the JSON returned by the Electrum
+blockchain.transaction.get_merkle method.  TSCProof reads and writes the
+binary merkle proof format of the Technical Standards Committee for single
+transaction proofs targeting a block hash, block header or merkle root.
+*/
+package merkle
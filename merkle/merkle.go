@@ -0,0 +1,141 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+)
+
+var (
+	// ErrNoLeaves describes an error where a merkle branch was requested
+	// for a tree without any leaves.
+	ErrNoLeaves = errors.New("merkle tree has no leaves")
+
+	// ErrInvalidPartialTree describes an error where a partial merkle tree
+	// is malformed, such as when it has too few or too many hashes or
+	// flag bits, or contains two identical sibling subtrees.
+	ErrInvalidPartialTree = errors.New("invalid partial merkle tree")
+
+	// ErrUnusedPartialTreeData describes an error where a partial merkle
+	// tree is traversed without using every hash and flag byte passed.
+	ErrUnusedPartialTreeData = errors.New("unused partial merkle tree data")
+)
+
+// HashMerkleBranches returns the double sha256 of the concatenation of the
+// left and right nodes of a merkle tree.
+func HashMerkleBranches(left, right *chainhash.Hash) chainhash.Hash {
+	var buf [chainhash.HashSize * 2]byte
+	copy(buf[:chainhash.HashSize], left[:])
+	copy(buf[chainhash.HashSize:], right[:])
+	return chainhash.DoubleHashH(buf[:])
+}
+
+// CalcRoot returns the merkle root of the passed leaf hashes, which for a
+// block are the txids of its transactions in order.  A level with an odd
+// number of nodes is completed by pairing the last node with itself.  The root
+// of a tree without leaves is the zero hash.
+//
+// The returned bool reports whether any level pairs two identical nodes.  Such
+// a tree has the same root as a tree with the duplicated nodes removed, which
+// is the mutation described by CVE-2012-2459, so a block whose transactions
+// produce a mutated tree must be rejected even though its root matches.
+func CalcRoot(leaves []chainhash.Hash) (chainhash.Hash, bool) {
+	if len(leaves) == 0 {
+		return chainhash.Hash{}, false
+	}
+
+	level := make([]chainhash.Hash, len(leaves))
+	copy(level, leaves)
+	mutated := false
+	for len(level) > 1 {
+		// Each parent is written over the nodes already consumed.
+		next := level[:0]
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, HashMerkleBranches(&level[i],
+					&level[i]))
+				break
+			}
+			if level[i] == level[i+1] {
+				mutated = true
+			}
+			next = append(next, HashMerkleBranches(&level[i],
+				&level[i+1]))
+		}
+		level = next
+	}
+	return level[0], mutated
+}
+
+// Branch returns the merkle branch proving the inclusion of the leaf at index
+// in the tree built from leaves.  The branch holds the sibling of the leaf and
+// of each of its ancestors below the root, from the bottom of the tree up.  A
+// node without a sibling is paired with itself, so its own hash appears in the
+// branch.
+func Branch(leaves []chainhash.Hash, index int) ([]chainhash.Hash, error) {
+	if len(leaves) == 0 {
+		return nil, ErrNoLeaves
+	}
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index %d is out of range - max %d",
+			index, len(leaves)-1)
+	}
+
+	level := make([]chainhash.Hash, len(leaves))
+	copy(level, leaves)
+	var branch []chainhash.Hash
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		branch = append(branch, level[sibling])
+
+		next := level[:0]
+		for i := 0; i < len(level); i += 2 {
+			right := i + 1
+			if right == len(level) {
+				right = i
+			}
+			next = append(next, HashMerkleBranches(&level[i],
+				&level[right]))
+		}
+		level = next
+		index >>= 1
+	}
+	return branch, nil
+}
+
+// BranchRoot returns the merkle root implied by a leaf at index and its merkle
+// branch as returned by Branch.
+func BranchRoot(leaf chainhash.Hash, index int, branch []chainhash.Hash) chainhash.Hash {
+	node := leaf
+	for i := range branch {
+		if index&1 == 0 {
+			node = HashMerkleBranches(&node, &branch[i])
+		} else {
+			node = HashMerkleBranches(&branch[i], &node)
+		}
+		index >>= 1
+	}
+	return node
+}
+
+// VerifyBranch returns whether the merkle branch proves the inclusion of the
+// leaf at index in the tree with the passed root.  The index must fit within
+// the depth of the branch, otherwise the same branch would prove the leaf at
+// several positions.
+func VerifyBranch(leaf chainhash.Hash, index int, branch []chainhash.Hash,
+	root chainhash.Hash) bool {
+
+	if index < 0 || len(branch) < 63 && index>>uint(len(branch)) != 0 {
+		return false
+	}
+	return BranchRoot(leaf, index, branch) == root
+}
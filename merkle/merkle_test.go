@@ -0,0 +1,164 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle_test
+
+import (
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchutil/merkle"
+)
+
+// block100000TxIDs are the txids of mainnet block 100000.
+var block100000TxIDs = []chainhash.Hash{
+	hashFromStr("8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87"),
+	hashFromStr("fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4"),
+	hashFromStr("6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4"),
+	hashFromStr("e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d"),
+}
+
+// block100000Root is the merkle root of mainnet block 100000.
+var block100000Root = hashFromStr("f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766")
+
+// hashFromStr converts the passed big-endian hex string into a chainhash.Hash.
+// It only differs from the one available in chainhash in that it ignores the
+// error since it will only (and must only) be called with hard-coded, and
+// therefore known good, hashes.
+func hashFromStr(s string) chainhash.Hash {
+	hash, _ := chainhash.NewHashFromStr(s)
+	return *hash
+}
+
+// newLeaves returns n distinct leaf hashes.
+func newLeaves(n int) []chainhash.Hash {
+	leaves := make([]chainhash.Hash, n)
+	for i := range leaves {
+		leaves[i] = chainhash.DoubleHashH([]byte{byte(i), byte(i >> 8)})
+	}
+	return leaves
+}
+
+// TestCalcRoot ensures merkle roots and mutations are computed as expected.
+func TestCalcRoot(t *testing.T) {
+	root, mutated := merkle.CalcRoot(block100000TxIDs)
+	if root != block100000Root || mutated {
+		t.Errorf("CalcRoot: got %v (mutated %v), want %v", root,
+			mutated, block100000Root)
+	}
+
+	// A single leaf is its own root.
+	leaves := newLeaves(3)
+	if root, _ := merkle.CalcRoot(leaves[:1]); root != leaves[0] {
+		t.Errorf("CalcRoot: single leaf root %v, want %v", root,
+			leaves[0])
+	}
+
+	// Duplicating the last leaf of an odd tree yields the same root but
+	// is reported as mutated.
+	want, _ := merkle.CalcRoot(leaves)
+	got, mutated := merkle.CalcRoot(append(leaves, leaves[2]))
+	if got != want || !mutated {
+		t.Errorf("CalcRoot: mutated tree root %v (mutated %v), want %v "+
+			"(mutated true)", got, mutated, want)
+	}
+}
+
+// TestBranch ensures the branch of every leaf of trees of various sizes
+// verifies against the root and does not verify at other positions.
+func TestBranch(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		leaves := newLeaves(n)
+		root, _ := merkle.CalcRoot(leaves)
+		for i, leaf := range leaves {
+			branch, err := merkle.Branch(leaves, i)
+			if err != nil {
+				t.Fatalf("Branch(%d, %d): unexpected error: %v",
+					n, i, err)
+			}
+			if !merkle.VerifyBranch(leaf, i, branch, root) {
+				t.Errorf("VerifyBranch(%d, %d): branch does not "+
+					"verify", n, i)
+			}
+			if merkle.VerifyBranch(leaf, i+1<<uint(len(branch)),
+				branch, root) {
+
+				t.Errorf("VerifyBranch(%d, %d): out of range "+
+					"index verifies", n, i)
+			}
+			other := leaves[(i+1)%n]
+			if n > 1 && merkle.VerifyBranch(other, i, branch, root) {
+				t.Errorf("VerifyBranch(%d, %d): wrong leaf "+
+					"verifies", n, i)
+			}
+		}
+	}
+
+	if _, err := merkle.Branch(nil, 0); err != merkle.ErrNoLeaves {
+		t.Errorf("Branch: unexpected error - got %v, want %v", err,
+			merkle.ErrNoLeaves)
+	}
+	if _, err := merkle.Branch(newLeaves(2), 2); err == nil {
+		t.Errorf("Branch: expected error for out of range index")
+	}
+}
+
+// TestPartialTree ensures partial merkle trees round trip through
+// BuildPartialTree and ExtractPartialTree.
+func TestPartialTree(t *testing.T) {
+	for n := 1; n <= 20; n++ {
+		leaves := newLeaves(n)
+		root, _ := merkle.CalcRoot(leaves)
+
+		// Match every third leaf.
+		matched := make([]bool, n)
+		var want []uint32
+		for i := 0; i < n; i += 3 {
+			matched[i] = true
+			want = append(want, uint32(i))
+		}
+
+		hashes, flags := merkle.BuildPartialTree(leaves, matched)
+		gotRoot, matches, err := merkle.ExtractPartialTree(uint32(n),
+			hashes, flags)
+		if err != nil {
+			t.Errorf("ExtractPartialTree(%d): unexpected error: %v",
+				n, err)
+			continue
+		}
+		if gotRoot != root {
+			t.Errorf("ExtractPartialTree(%d): root %v, want %v", n,
+				gotRoot, root)
+		}
+		if len(matches) != len(want) {
+			t.Errorf("ExtractPartialTree(%d): got %d matches, want "+
+				"%d", n, len(matches), len(want))
+			continue
+		}
+		for i, match := range matches {
+			if match.Index != want[i] || match.Hash != leaves[want[i]] {
+				t.Errorf("ExtractPartialTree(%d): unexpected "+
+					"match %d: %v", n, i, match)
+			}
+		}
+
+		// Dropping a hash invalidates the tree.
+		_, _, err = merkle.ExtractPartialTree(uint32(n),
+			hashes[:len(hashes)-1], flags)
+		if err != merkle.ErrInvalidPartialTree {
+			t.Errorf("ExtractPartialTree(%d): unexpected error for "+
+				"truncated tree - got %v, want %v", n, err,
+				merkle.ErrInvalidPartialTree)
+		}
+
+		// Trailing flag bytes are not used by the tree.
+		_, _, err = merkle.ExtractPartialTree(uint32(n), hashes,
+			append(flags, 0))
+		if err != merkle.ErrUnusedPartialTreeData {
+			t.Errorf("ExtractPartialTree(%d): unexpected error for "+
+				"unused flags - got %v, want %v", n, err,
+				merkle.ErrUnusedPartialTreeData)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"github.com/gcash/bchd/chaincfg/chainhash"
+)
+
+// calcTreeWidth returns the number of nodes at the given height of a merkle
+// tree with numLeaves leaves, where the leaves are at height 0.
+func calcTreeWidth(numLeaves, height uint32) uint32 {
+	return (numLeaves + (1 << height) - 1) >> height
+}
+
+// calcTreeHeight returns the height of the root of a merkle tree with
+// numLeaves leaves.
+func calcTreeHeight(numLeaves uint32) uint32 {
+	height := uint32(0)
+	for calcTreeWidth(numLeaves, height) > 1 {
+		height++
+	}
+	return height
+}
+
+// partialTreeBuilder houses the intermediate state used to build a partial
+// merkle tree.
+type partialTreeBuilder struct {
+	leaves  []chainhash.Hash
+	matched []bool
+	hashes  []chainhash.Hash
+	bits    []bool
+}
+
+// calcHash returns the hash for a sub-tree given a depth-first height and
+// node position.
+func (b *partialTreeBuilder) calcHash(height, pos uint32) chainhash.Hash {
+	if height == 0 {
+		return b.leaves[pos]
+	}
+
+	left := b.calcHash(height-1, pos*2)
+	right := left
+	if pos*2+1 < calcTreeWidth(uint32(len(b.leaves)), height-1) {
+		right = b.calcHash(height-1, pos*2+1)
+	}
+	return HashMerkleBranches(&left, &right)
+}
+
+// traverseAndBuild builds a partial merkle tree using a recursive depth-first
+// approach.  As it calculates the hashes, it also saves whether or not each
+// node is a parent node and a list of final hashes to be included in the
+// partial tree.
+func (b *partialTreeBuilder) traverseAndBuild(height, pos uint32) {
+	// Determine whether this node is a parent of a matched node.
+	numLeaves := uint32(len(b.leaves))
+	isParent := false
+	for i := pos << height; i < (pos+1)<<height && i < numLeaves; i++ {
+		isParent = isParent || b.matched[i]
+	}
+	b.bits = append(b.bits, isParent)
+
+	// When the node is a leaf node or not a parent of a matched node,
+	// append the hash to the list that will be part of the final partial
+	// tree.
+	if height == 0 || !isParent {
+		b.hashes = append(b.hashes, b.calcHash(height, pos))
+		return
+	}
+
+	// Descend into the left child and process its sub-tree, then into the
+	// right child if there is one.
+	b.traverseAndBuild(height-1, pos*2)
+	if pos*2+1 < calcTreeWidth(numLeaves, height-1) {
+		b.traverseAndBuild(height-1, pos*2+1)
+	}
+}
+
+// BuildPartialTree returns the hashes and packed flag bits of the partial
+// merkle tree, as carried by a wire.MsgMerkleBlock, which proves the inclusion
+// of every leaf whose entry in matched is true.  The matched slice must be the
+// same length as leaves.
+func BuildPartialTree(leaves []chainhash.Hash, matched []bool) ([]chainhash.Hash, []byte) {
+	if len(leaves) == 0 {
+		return nil, nil
+	}
+
+	b := partialTreeBuilder{leaves: leaves, matched: matched}
+	b.traverseAndBuild(calcTreeHeight(uint32(len(leaves))), 0)
+
+	flags := make([]byte, (len(b.bits)+7)/8)
+	for i, bit := range b.bits {
+		if bit {
+			flags[i/8] |= 1 << (uint(i) % 8)
+		}
+	}
+	return b.hashes, flags
+}
+
+// PartialTreeMatch is a leaf proven by a partial merkle tree.
+type PartialTreeMatch struct {
+	// Hash is the leaf hash, which for a block is the txid.
+	Hash chainhash.Hash
+
+	// Index is the position of the leaf in the tree.
+	Index uint32
+}
+
+// partialTreeExtractor houses the intermediate state used to traverse a
+// partial merkle tree.
+type partialTreeExtractor struct {
+	numLeaves  uint32
+	hashes     []chainhash.Hash
+	flags      []byte
+	bad        bool
+	bitsUsed   uint32
+	hashesUsed uint32
+	matches    []PartialTreeMatch
+}
+
+// traverseAndExtract traverses over a partial merkle tree and finds matched
+// leaf hashes and their position in the tree.
+func (e *partialTreeExtractor) traverseAndExtract(height, pos uint32) chainhash.Hash {
+	if e.bitsUsed >= uint32(len(e.flags))*8 {
+		// The flag bits have overflowed.
+		e.bad = true
+		return chainhash.Hash{}
+	}
+	parent := e.flags[e.bitsUsed/8]&(1<<(e.bitsUsed%8)) != 0
+	e.bitsUsed++
+
+	if height == 0 || !parent {
+		// At height 0 or when the flag bit is not set the tree is not
+		// descended and the hash is taken as is.
+		if e.hashesUsed >= uint32(len(e.hashes)) {
+			e.bad = true
+			return chainhash.Hash{}
+		}
+		hash := e.hashes[e.hashesUsed]
+		e.hashesUsed++
+
+		if height == 0 && parent {
+			e.matches = append(e.matches, PartialTreeMatch{hash, pos})
+		}
+		return hash
+	}
+
+	// Descend into the subtrees to extract matched leaves.
+	left := e.traverseAndExtract(height-1, pos*2)
+	right := left
+	if pos*2+1 < calcTreeWidth(e.numLeaves, height-1) {
+		right = e.traverseAndExtract(height-1, pos*2+1)
+
+		// The left and right branches must not be identical as the
+		// leaves covered by them must each be unique.
+		if right == left {
+			e.bad = true
+		}
+	}
+	return HashMerkleBranches(&left, &right)
+}
+
+// ExtractPartialTree traverses a partial merkle tree built from numLeaves
+// leaves and returns its merkle root along with the leaves it proves, in tree
+// order.  ErrInvalidPartialTree is returned if the tree is malformed, and
+// ErrUnusedPartialTreeData if its traversal does not consume exactly the
+// passed hashes and flag bytes.
+func ExtractPartialTree(numLeaves uint32, hashes []chainhash.Hash,
+	flags []byte) (chainhash.Hash, []PartialTreeMatch, error) {
+
+	// There must be at least one bit per node in the partial tree and at
+	// least one node per hash, and no more hashes than leaves.
+	numHashes := uint32(len(hashes))
+	if numLeaves == 0 || numHashes > numLeaves ||
+		uint32(len(flags))*8 < numHashes {
+
+		return chainhash.Hash{}, nil, ErrInvalidPartialTree
+	}
+
+	e := partialTreeExtractor{numLeaves: numLeaves, hashes: hashes,
+		flags: flags}
+	root := e.traverseAndExtract(calcTreeHeight(numLeaves), 0)
+	if e.bad {
+		return chainhash.Hash{}, nil, ErrInvalidPartialTree
+	}
+
+	// Every flag byte and every hash must have been consumed.
+	if (e.bitsUsed+7)/8 != uint32(len(flags)) || e.hashesUsed != numHashes {
+		return chainhash.Hash{}, nil, ErrUnusedPartialTreeData
+	}
+	return root, e.matches, nil
+}
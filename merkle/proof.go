@@ -0,0 +1,369 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+)
+
+// ElectrumProof is a merkle branch in the form returned by the Electrum
+// protocol method blockchain.transaction.get_merkle.
+type ElectrumProof struct {
+	// BlockHeight is the height of the block containing the transaction.
+	BlockHeight int32
+
+	// Branch is the merkle branch of the transaction as returned by
+	// Branch.
+	Branch []chainhash.Hash
+
+	// Pos is the position of the transaction in the block.
+	Pos int
+}
+
+// electrumProofJSON is the JSON representation of an ElectrumProof.  Branch
+// hashes are encoded as byte-reversed hex like txids.
+type electrumProofJSON struct {
+	BlockHeight int32    `json:"block_height"`
+	Merkle      []string `json:"merkle"`
+	Pos         int      `json:"pos"`
+}
+
+// MarshalJSON returns the Electrum JSON encoding of the proof.
+func (p *ElectrumProof) MarshalJSON() ([]byte, error) {
+	merkle := make([]string, len(p.Branch))
+	for i := range p.Branch {
+		merkle[i] = p.Branch[i].String()
+	}
+	return json.Marshal(&electrumProofJSON{
+		BlockHeight: p.BlockHeight,
+		Merkle:      merkle,
+		Pos:         p.Pos,
+	})
+}
+
+// UnmarshalJSON decodes the Electrum JSON encoding of a proof.
+func (p *ElectrumProof) UnmarshalJSON(data []byte) error {
+	var proof electrumProofJSON
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return err
+	}
+
+	branch := make([]chainhash.Hash, len(proof.Merkle))
+	for i, s := range proof.Merkle {
+		if len(s) != chainhash.MaxHashStringSize {
+			return fmt.Errorf("merkle branch hash %d has invalid "+
+				"length %d", i, len(s))
+		}
+		if err := chainhash.Decode(&branch[i], s); err != nil {
+			return err
+		}
+	}
+	p.BlockHeight = proof.BlockHeight
+	p.Branch = branch
+	p.Pos = proof.Pos
+	return nil
+}
+
+// Verify returns whether the proof shows txid is included in the block with
+// the passed merkle root.
+func (p *ElectrumProof) Verify(txid, root chainhash.Hash) bool {
+	return VerifyBranch(txid, p.Pos, p.Branch, root)
+}
+
+// TSCTargetType identifies what a TSCProof commits to.
+type TSCTargetType byte
+
+// These constants define the target types of a TSCProof.  They are the values
+// of the target type bits in the proof flags.
+const (
+	// TSCTargetBlockHash indicates the target is the hash of the block.
+	TSCTargetBlockHash TSCTargetType = 0x00
+
+	// TSCTargetHeader indicates the target is the block header.
+	TSCTargetHeader TSCTargetType = 0x02
+
+	// TSCTargetMerkleRoot indicates the target is the merkle root.
+	TSCTargetMerkleRoot TSCTargetType = 0x04
+)
+
+// Flags and node types of the TSC merkle proof format.
+const (
+	tscFlagTx         = 0x01
+	tscFlagTargetMask = 0x06
+	tscFlagProofTree  = 0x08
+	tscFlagComposite  = 0x10
+
+	tscNodeHash      = 0x00
+	tscNodeDuplicate = 0x01
+)
+
+// maxTSCNodes is the maximum number of nodes accepted when decoding a TSC
+// proof, which is far deeper than any merkle tree of a valid block.
+const maxTSCNodes = 64
+
+// ErrUnsupportedTSCProof describes an error where a TSC proof uses a feature
+// of the format which is not supported: merkle tree proofs, composite proofs
+// and index node references.
+var ErrUnsupportedTSCProof = errors.New("unsupported TSC proof")
+
+// TSCProof is a merkle proof in the binary format standardised by the
+// Technical Standards Committee.  Only single merkle branch proofs are
+// supported.
+type TSCProof struct {
+	// Index is the position of the transaction in the block.
+	Index uint64
+
+	// Tx is the serialized transaction, or nil if the proof only holds
+	// the txid.
+	Tx []byte
+
+	// TxID is the txid of the transaction.  When a proof carrying Tx is
+	// decoded it is set to the hash of Tx.
+	TxID chainhash.Hash
+
+	// TargetType identifies whether Target or Header is the target.
+	TargetType TSCTargetType
+
+	// Target is the block hash or merkle root the proof commits to.  It
+	// is unused when TargetType is TSCTargetHeader.
+	Target chainhash.Hash
+
+	// Header is the block header the proof commits to when TargetType is
+	// TSCTargetHeader.
+	Header *wire.BlockHeader
+
+	// Nodes is the merkle branch of the transaction from the bottom of
+	// the tree up.  A nil node marks a node paired with itself, which the
+	// format encodes without repeating the hash.
+	Nodes []*chainhash.Hash
+}
+
+// NewTSCProof returns a TSC proof of the inclusion of txid at index in the
+// block with the passed merkle root, given its merkle branch as returned by
+// Branch.  Nodes paired with themselves are encoded as duplicates.
+func NewTSCProof(txid chainhash.Hash, index int, branch []chainhash.Hash,
+	root chainhash.Hash) *TSCProof {
+
+	nodes := make([]*chainhash.Hash, len(branch))
+	node := txid
+	pos := index
+	for i := range branch {
+		sibling := branch[i]
+		if sibling != node {
+			nodes[i] = &sibling
+		}
+		if pos&1 == 0 {
+			node = HashMerkleBranches(&node, &sibling)
+		} else {
+			node = HashMerkleBranches(&sibling, &node)
+		}
+		pos >>= 1
+	}
+
+	return &TSCProof{
+		Index:      uint64(index),
+		TxID:       txid,
+		TargetType: TSCTargetMerkleRoot,
+		Target:     root,
+		Nodes:      nodes,
+	}
+}
+
+// Root returns the merkle root implied by the transaction and the nodes of the
+// proof.
+func (p *TSCProof) Root() chainhash.Hash {
+	node := p.TxID
+	index := p.Index
+	for _, sibling := range p.Nodes {
+		if sibling == nil {
+			sibling = &node
+		}
+		if index&1 == 0 {
+			node = HashMerkleBranches(&node, sibling)
+		} else {
+			node = HashMerkleBranches(sibling, &node)
+		}
+		index >>= 1
+	}
+	return node
+}
+
+// Verify returns whether the proof shows the transaction is included in the
+// block it targets.  A proof targeting a block hash cannot be verified on its
+// own and results in an error; callers holding the header for the hash should
+// compare its merkle root with Root instead.
+func (p *TSCProof) Verify() (bool, error) {
+	if len(p.Nodes) < 64 && p.Index>>uint(len(p.Nodes)) != 0 {
+		return false, nil
+	}
+
+	switch p.TargetType {
+	case TSCTargetMerkleRoot:
+		return p.Root() == p.Target, nil
+
+	case TSCTargetHeader:
+		if p.Header == nil {
+			return false, errors.New("proof targets a header but " +
+				"has none")
+		}
+		return p.Root() == p.Header.MerkleRoot, nil
+	}
+
+	return false, errors.New("proof targets a block hash which does not " +
+		"commit to the merkle root on its own")
+}
+
+// Serialize encodes the proof to w in the TSC binary format.
+func (p *TSCProof) Serialize(w io.Writer) error {
+	flags := byte(p.TargetType)
+	if flags&^tscFlagTargetMask != 0 || flags == tscFlagTargetMask {
+		return fmt.Errorf("invalid target type %#x", flags)
+	}
+	if p.Tx != nil {
+		flags |= tscFlagTx
+	}
+	if _, err := w.Write([]byte{flags}); err != nil {
+		return err
+	}
+	if err := wire.WriteVarInt(w, 0, p.Index); err != nil {
+		return err
+	}
+
+	if p.Tx != nil {
+		if err := wire.WriteVarBytes(w, 0, p.Tx); err != nil {
+			return err
+		}
+	} else if _, err := w.Write(p.TxID[:]); err != nil {
+		return err
+	}
+
+	if p.TargetType == TSCTargetHeader {
+		if p.Header == nil {
+			return errors.New("proof targets a header but has none")
+		}
+		if err := p.Header.Serialize(w); err != nil {
+			return err
+		}
+	} else if _, err := w.Write(p.Target[:]); err != nil {
+		return err
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(p.Nodes))); err != nil {
+		return err
+	}
+	for _, node := range p.Nodes {
+		if node == nil {
+			if _, err := w.Write([]byte{tscNodeDuplicate}); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := w.Write([]byte{tscNodeHash}); err != nil {
+			return err
+		}
+		if _, err := w.Write(node[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bytes returns the proof encoded in the TSC binary format.
+func (p *TSCProof) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Deserialize decodes a proof in the TSC binary format from r into p.
+// ErrUnsupportedTSCProof is returned for merkle tree and composite proofs.
+func (p *TSCProof) Deserialize(r io.Reader) error {
+	var flags [1]byte
+	if _, err := io.ReadFull(r, flags[:]); err != nil {
+		return err
+	}
+	if flags[0]&(tscFlagProofTree|tscFlagComposite) != 0 {
+		return ErrUnsupportedTSCProof
+	}
+	targetType := TSCTargetType(flags[0] & tscFlagTargetMask)
+	if targetType == tscFlagTargetMask {
+		return fmt.Errorf("invalid target type %#x", byte(targetType))
+	}
+
+	index, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+
+	var tx []byte
+	var txid chainhash.Hash
+	if flags[0]&tscFlagTx != 0 {
+		tx, err = wire.ReadVarBytes(r, 0, wire.MaxBlockPayload(),
+			"transaction")
+		if err != nil {
+			return err
+		}
+		txid = chainhash.DoubleHashH(tx)
+	} else if _, err := io.ReadFull(r, txid[:]); err != nil {
+		return err
+	}
+
+	var target chainhash.Hash
+	var header *wire.BlockHeader
+	if targetType == TSCTargetHeader {
+		header = new(wire.BlockHeader)
+		if err := header.Deserialize(r); err != nil {
+			return err
+		}
+	} else if _, err := io.ReadFull(r, target[:]); err != nil {
+		return err
+	}
+
+	numNodes, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	if numNodes > maxTSCNodes {
+		return fmt.Errorf("too many proof nodes %d - max %d", numNodes,
+			maxTSCNodes)
+	}
+	nodes := make([]*chainhash.Hash, numNodes)
+	for i := range nodes {
+		var nodeType [1]byte
+		if _, err := io.ReadFull(r, nodeType[:]); err != nil {
+			return err
+		}
+		switch nodeType[0] {
+		case tscNodeHash:
+			nodes[i] = new(chainhash.Hash)
+			if _, err := io.ReadFull(r, nodes[i][:]); err != nil {
+				return err
+			}
+		case tscNodeDuplicate:
+		default:
+			return ErrUnsupportedTSCProof
+		}
+	}
+
+	*p = TSCProof{
+		Index:      index,
+		Tx:         tx,
+		TxID:       txid,
+		TargetType: targetType,
+		Target:     target,
+		Header:     header,
+		Nodes:      nodes,
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/merkle"
+)
+
+// TestElectrumProof ensures Electrum proofs encode to and decode from the
+// get_merkle JSON format.
+func TestElectrumProof(t *testing.T) {
+	branch, err := merkle.Branch(block100000TxIDs, 2)
+	if err != nil {
+		t.Fatalf("Branch: unexpected error: %v", err)
+	}
+	proof := &merkle.ElectrumProof{BlockHeight: 100000, Branch: branch, Pos: 2}
+
+	encoded, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("MarshalJSON: unexpected error: %v", err)
+	}
+	want := `{"block_height":100000,"merkle":["` +
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d" +
+		`","` + branch[1].String() + `"],"pos":2}`
+	if string(encoded) != want {
+		t.Errorf("MarshalJSON: got %s, want %s", encoded, want)
+	}
+
+	var decoded merkle.ElectrumProof
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, proof) {
+		t.Errorf("UnmarshalJSON: got %v, want %v", decoded, proof)
+	}
+	if !decoded.Verify(block100000TxIDs[2], block100000Root) {
+		t.Errorf("Verify: proof does not verify")
+	}
+	if decoded.Verify(block100000TxIDs[1], block100000Root) {
+		t.Errorf("Verify: proof verifies the wrong txid")
+	}
+
+	err = json.Unmarshal([]byte(`{"merkle":["00"],"pos":0}`), &decoded)
+	if err == nil {
+		t.Errorf("UnmarshalJSON: expected error for short hash")
+	}
+}
+
+// TestTSCProof ensures TSC proofs round trip through the binary format and
+// verify against their targets.
+func TestTSCProof(t *testing.T) {
+	leaves := newLeaves(5)
+	root, _ := merkle.CalcRoot(leaves)
+	header := &wire.BlockHeader{
+		Version:    1,
+		MerkleRoot: root,
+		Timestamp:  time.Unix(1231006505, 0),
+	}
+
+	// The last leaf of a five leaf tree is paired with itself on every
+	// level below the root.
+	branch, _ := merkle.Branch(leaves, 4)
+	proof := merkle.NewTSCProof(leaves[4], 4, branch, root)
+	if proof.Nodes[0] != nil || proof.Nodes[1] != nil || proof.Nodes[2] == nil {
+		t.Errorf("NewTSCProof: unexpected duplicate nodes %v", proof.Nodes)
+	}
+
+	tx := []byte{0x01, 0x02, 0x03}
+	txBranch, _ := merkle.Branch(append(leaves[:1:1],
+		chainhash.DoubleHashH(tx)), 1)
+	txRoot, _ := merkle.CalcRoot(append(leaves[:1:1], chainhash.DoubleHashH(tx)))
+	txProof := merkle.NewTSCProof(chainhash.DoubleHashH(tx), 1, txBranch,
+		txRoot)
+	txProof.Tx = tx
+
+	headerProof := merkle.NewTSCProof(leaves[1], 1, mustBranch(t, leaves, 1),
+		root)
+	headerProof.TargetType = merkle.TSCTargetHeader
+	headerProof.Target = chainhash.Hash{}
+	headerProof.Header = header
+
+	tests := []struct {
+		name  string
+		proof *merkle.TSCProof
+	}{
+		{"merkle root target with duplicates", proof},
+		{"full transaction", txProof},
+		{"header target", headerProof},
+	}
+
+	for _, test := range tests {
+		encoded, err := test.proof.Bytes()
+		if err != nil {
+			t.Errorf("%s: Bytes: unexpected error: %v", test.name, err)
+			continue
+		}
+		var decoded merkle.TSCProof
+		if err := decoded.Deserialize(bytes.NewReader(encoded)); err != nil {
+			t.Errorf("%s: Deserialize: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(&decoded, test.proof) {
+			t.Errorf("%s: round trip mismatch - got %+v, want %+v",
+				test.name, decoded, test.proof)
+		}
+		ok, err := decoded.Verify()
+		if err != nil || !ok {
+			t.Errorf("%s: Verify: got %v (%v), want true", test.name,
+				ok, err)
+		}
+	}
+
+	// A proof for the wrong index does not verify.
+	proof.Index = 3
+	if ok, _ := proof.Verify(); ok {
+		t.Errorf("Verify: proof with wrong index verifies")
+	}
+
+	// Composite proofs are not supported.
+	var decoded merkle.TSCProof
+	err := decoded.Deserialize(bytes.NewReader([]byte{0x10, 0x00}))
+	if err != merkle.ErrUnsupportedTSCProof {
+		t.Errorf("Deserialize: unexpected error - got %v, want %v", err,
+			merkle.ErrUnsupportedTSCProof)
+	}
+}
+
+// mustBranch returns the merkle branch of the leaf at index.
+func mustBranch(t *testing.T, leaves []chainhash.Hash, index int) []chainhash.Hash {
+	branch, err := merkle.Branch(leaves, index)
+	if err != nil {
+		t.Fatalf("Branch: unexpected error: %v", err)
+	}
+	return branch
+}
@@ -6,9 +6,9 @@
 package merkleblock
 
 import (
-	"github.com/gcash/bchd/blockchain"
 	"github.com/gcash/bchd/chaincfg/chainhash"
 	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/merkle"
 )
 
 // MaxTxnCount defines the maximum number of transactions we will process before
@@ -30,11 +30,9 @@ var MaxTxnCount = wire.MaxBlockPayload() / 61
 type PartialBlock struct {
 	numTx       uint32
 	finalHashes []*chainhash.Hash
-	bits        []byte
+	flags       []byte
 	// variables below used for traversal and extraction
 	bad           bool
-	bitsUsed      uint32
-	hashesUsed    uint32
 	matchedHashes []*chainhash.Hash
 	matchedItems  []uint32
 }
@@ -48,43 +46,24 @@ type PartialBlock struct {
 // with protocol reference documentation at
 // https://bitcoin.org/en/developer-examples#parsing-a-merkleblock
 func NewMerkleBlockFromMsg(msg wire.MsgMerkleBlock) *PartialBlock {
-
-	// get number of hashes in message
-	numTx := msg.Transactions
-
-	// from the wire message Flags decode the bits
-	bits := make([]byte, len(msg.Flags)*8)
-
-	for i := uint32(0); i < uint32(len(bits)); i++ {
-		if msg.Flags[i/8]&(1<<(i%8)) == 0 {
-			bits[i] = byte(0)
-		} else {
-			bits[i] = byte(1)
-		}
-	}
-
 	// Create merkle block using data from msg
-	mBlock := &PartialBlock{
+	return &PartialBlock{
 		// total number of transactions in block
-		numTx: numTx,
+		numTx: msg.Transactions,
 		// hashes used for partial merkle tree
 		finalHashes: msg.Hashes,
 		// bit flags for our included hashes
-		bits: bits,
-		// initialse traversal variables
-		bad:           false,
-		bitsUsed:      0,
-		hashesUsed:    0,
+		flags:         msg.Flags,
 		matchedHashes: make([]*chainhash.Hash, 0),
 		matchedItems:  make([]uint32, 0),
 	}
-
-	return mBlock
 }
 
 // ExtractMatches traverses the partial merkle tree and returns the merkle root
 // on successful traversal or nil if an error occured during traversal due to
-// an invalid block being parsed
+// an invalid block being parsed.  BadTree only reports errors found during the
+// traversal, so a tree which is rejected before it is traversed, or which does
+// not use every hash and flag byte, returns nil without being marked bad.
 func (m *PartialBlock) ExtractMatches() *chainhash.Hash {
 
 	// if block is empty then no extraction can be made
@@ -97,100 +76,38 @@ func (m *PartialBlock) ExtractMatches() *chainhash.Hash {
 		return nil
 	}
 
+	// check there are not more hashes than total number of transactions in
+	// a block, and that there is at least one bit per node in the partial
+	// merkle tree and at least one node per hash
 	totalHashes := uint32(len(m.finalHashes))
-
-	// check there are not more hashes than total number of transactions in a block
-	if totalHashes > m.numTx {
+	if totalHashes > m.numTx || uint32(len(m.flags))*8 < totalHashes {
 		return nil
 	}
 
-	// there must be atleast one bit per node in the partial merkle tree and
-	// atleast one node per hash
-	if uint32(len(m.bits)) < totalHashes {
-		return nil
-	}
-
-	// calculate the height of the merkle tree
-	height := uint32(0)
-	for m.calcTreeWidth(height) > 1 {
-		height++
+	hashes := make([]chainhash.Hash, len(m.finalHashes))
+	for i, hash := range m.finalHashes {
+		hashes[i] = *hash
 	}
 
 	// traverse the partial merkle tree
-	merkleRootHash := m.traverseAndExtract(height, 0)
-
-	// check no problems occured during tree traversal
-	if m.bad {
+	merkleRootHash, matches, err := merkle.ExtractPartialTree(m.numTx,
+		hashes, m.flags)
+	if err == merkle.ErrInvalidPartialTree {
+		m.bad = true
 		return nil
 	}
-
-	// verify that all bits were consumed (except for the padding caused by
-	// serialisation it as a byte sequence)
-	if (m.bitsUsed+7)/8 != (uint32(len(m.bits))+7)/8 {
+	if err != nil {
 		return nil
 	}
 
-	// verify that all hashes were consumed
-	if m.hashesUsed != uint32(len(m.finalHashes)) {
-		return nil
+	for _, match := range matches {
+		hash := match.Hash
+		m.matchedHashes = append(m.matchedHashes, &hash)
+		m.matchedItems = append(m.matchedItems, match.Index)
 	}
 
 	// return merkle root
-	return merkleRootHash
-}
-
-// traverseAndExtract traverses over a partial merkle tree and finds matched
-// transaction hashes and their item position in the block
-func (m *PartialBlock) traverseAndExtract(height, pos uint32) *chainhash.Hash {
-
-	if m.bitsUsed >= uint32(len(m.bits)) {
-		// bits array has overflowed
-		m.bad = true
-		return &chainhash.Hash{}
-	}
-
-	parent := m.bits[m.bitsUsed]
-	m.bitsUsed++
-
-	if height == 0 || parent == byte(0) {
-		// at height 0 or bit not set of node, then do not descend tree,
-		// just return hash
-		if m.hashesUsed >= uint32(len(m.finalHashes)) {
-			m.bad = true
-			return &chainhash.Hash{}
-		}
-
-		hash := m.finalHashes[m.hashesUsed]
-		m.hashesUsed++
-
-		if height == 0 && parent == byte(1) {
-			// at height 0 and we have a matched transaction
-			m.matchedHashes = append(m.matchedHashes, hash)
-			m.matchedItems = append(m.matchedItems, pos)
-		}
-
-		return hash
-	}
-
-	// descend into subtree to extract matched transactions
-	left := m.traverseAndExtract(height-1, pos*2)
-
-	var right *chainhash.Hash
-
-	if pos*2+1 < m.calcTreeWidth(height-1) {
-		right = m.traverseAndExtract(height-1, pos*2+1)
-
-		if right.IsEqual(left) {
-			// left and right branches should not be identical, as the
-			// transaction hashes covered by them must each be unique
-			m.bad = true
-		}
-	} else {
-		right = left
-	}
-
-	// hash with double sha256 and return
-	return blockchain.HashMerkleBranches(left, right)
+	return &merkleRootHash
 }
 
 // GetMatches returns the transaction hashes matched in the partial merkle tree
@@ -208,9 +125,3 @@ func (m *PartialBlock) GetItems() []uint32 {
 func (m *PartialBlock) BadTree() bool {
 	return m.bad
 }
-
-// calcTreeWidth calculates and returns the the number of nodes (width) or a
-// merkle tree at the given depth-first height.
-func (m *PartialBlock) calcTreeWidth(height uint32) uint32 {
-	return (m.numTx + (1 << height) - 1) >> height
-}
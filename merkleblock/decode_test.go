@@ -12,6 +12,7 @@ import (
 
 	"github.com/gcash/bchd/chaincfg/chainhash"
 	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/chaingen"
 	"github.com/gcash/bchutil/merkleblock"
 )
 
@@ -137,3 +138,134 @@ func hashFromStr(s string) *chainhash.Hash {
 
 	return hash
 }
+
+// TestMerkleBlockGeneratedChain ensures proofs for transactions of generated
+// blocks decode to the merkle root of each block and the proven transactions.
+func TestMerkleBlockGeneratedChain(t *testing.T) {
+	g, err := chaingen.New(&chaingen.Config{SpendsPerBlock: 8})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	blocks, err := g.Generate(106)
+	if err != nil {
+		t.Fatalf("Generate: unexpected error: %v", err)
+	}
+
+	for _, blk := range blocks[99:] {
+		var txnSet []*chainhash.Hash
+		for i, tx := range blk.Transactions() {
+			if i%2 == 0 {
+				txnSet = append(txnSet, tx.Hash())
+			}
+		}
+
+		mBlock, _ := merkleblock.NewMerkleBlockWithTxnSet(blk, txnSet)
+		partial := merkleblock.NewMerkleBlockFromMsg(*mBlock)
+		root := partial.ExtractMatches()
+		if root == nil || *root != blk.MsgBlock().Header.MerkleRoot {
+			t.Errorf("block %v: got merkle root %v, want %v",
+				blk.Hash(), root, blk.MsgBlock().Header.MerkleRoot)
+			continue
+		}
+		matches := partial.GetMatches()
+		if len(matches) != len(txnSet) {
+			t.Errorf("block %v: got %d matches, want %d", blk.Hash(),
+				len(matches), len(txnSet))
+			continue
+		}
+		for i := range matches {
+			if *matches[i] != *txnSet[i] {
+				t.Errorf("block %v: match %d is %v, want %v",
+					blk.Hash(), i, matches[i], txnSet[i])
+			}
+		}
+	}
+}
+
+// TestExtractMatchesMalformed ensures malformed partial merkle trees are
+// rejected and only errors found while traversing the tree mark it as bad.
+func TestExtractMatchesMalformed(t *testing.T) {
+	leaves := make([]*chainhash.Hash, 5)
+	for i := range leaves {
+		hash := chainhash.DoubleHashH([]byte{byte(i)})
+		leaves[i] = &hash
+	}
+	valid := func() wire.MsgMerkleBlock {
+		// The tree of five leaves proving the first one is made up of
+		// four hashes and seven flag bits.
+		return wire.MsgMerkleBlock{
+			Transactions: 5,
+			Hashes: []*chainhash.Hash{leaves[0], leaves[1],
+				leaves[2], leaves[3]},
+			Flags: []byte{0x0f},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(msg *wire.MsgMerkleBlock)
+		bad    bool
+	}{
+		{
+			name:   "no transactions",
+			modify: func(msg *wire.MsgMerkleBlock) { msg.Transactions = 0 },
+		},
+		{
+			name: "more hashes than transactions",
+			modify: func(msg *wire.MsgMerkleBlock) {
+				msg.Transactions = 3
+			},
+		},
+		{
+			name: "fewer flag bits than hashes",
+			modify: func(msg *wire.MsgMerkleBlock) {
+				msg.Flags = nil
+			},
+		},
+		{
+			name: "unused hash",
+			modify: func(msg *wire.MsgMerkleBlock) {
+				msg.Hashes = append(msg.Hashes, leaves[4])
+			},
+		},
+		{
+			name: "unused flag byte",
+			modify: func(msg *wire.MsgMerkleBlock) {
+				msg.Flags = append(msg.Flags, 0)
+			},
+		},
+		{
+			name: "missing hash",
+			modify: func(msg *wire.MsgMerkleBlock) {
+				msg.Hashes = msg.Hashes[:3]
+			},
+			bad: true,
+		},
+		{
+			name: "identical siblings",
+			modify: func(msg *wire.MsgMerkleBlock) {
+				msg.Transactions = 2
+				msg.Hashes = []*chainhash.Hash{leaves[0], leaves[0]}
+				msg.Flags = []byte{0x07}
+			},
+			bad: true,
+		},
+	}
+
+	msg := valid()
+	if merkleblock.NewMerkleBlockFromMsg(msg).ExtractMatches() == nil {
+		t.Fatalf("ExtractMatches: valid tree rejected")
+	}
+	for _, test := range tests {
+		msg := valid()
+		test.modify(&msg)
+		mBlock := merkleblock.NewMerkleBlockFromMsg(msg)
+		if root := mBlock.ExtractMatches(); root != nil {
+			t.Errorf("%s: got merkle root %v, want nil", test.name, root)
+		}
+		if mBlock.BadTree() != test.bad {
+			t.Errorf("%s: got bad tree %v, want %v", test.name,
+				mBlock.BadTree(), test.bad)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gcash/bchd/bchec"
+	"github.com/gcash/bchd/chaincfg"
+)
+
+var (
+	// ErrMalformedMiniKey describes an error where a mini private key is
+	// not a 22 or 30 character string of base58 characters starting with
+	// 'S'.
+	ErrMalformedMiniKey = errors.New("malformed mini private key")
+
+	// ErrInvalidMiniKey describes an error where a well formed mini
+	// private key fails the SHA256 validity check, which usually means it
+	// was mistyped.
+	ErrInvalidMiniKey = errors.New("mini private key failed validity check")
+)
+
+const (
+	// MiniKeyLen is the length of a mini private key as generated by
+	// NewMiniKey.
+	MiniKeyLen = 30
+
+	// shortMiniKeyLen is the length of the mini private keys found on the
+	// earliest Casascius coins.
+	shortMiniKeyLen = 22
+
+	// miniKeyAlphabet is the set of characters allowed in a mini private
+	// key, which is the base58 alphabet.
+	miniKeyAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// DecodeMiniKey creates a new WIF structure for the private key encoded by a
+// Casascius mini private key.
+//
+// A mini private key is a 22 or 30 character string of base58 characters
+// starting with 'S'.  The private key is the SHA256 of the string, and the
+// string is only valid if the SHA256 of the string followed by a '?' begins
+// with a zero byte.  Mini private keys always control the address of the
+// uncompressed public key, so the returned WIF is uncompressed.
+//
+// ErrMalformedMiniKey is returned when the string is not of the correct form
+// and ErrInvalidMiniKey is returned when the validity check fails.
+func DecodeMiniKey(miniKey string, net *chaincfg.Params) (*WIF, error) {
+	if net == nil {
+		return nil, errors.New("no network")
+	}
+	if len(miniKey) != MiniKeyLen && len(miniKey) != shortMiniKeyLen {
+		return nil, ErrMalformedMiniKey
+	}
+	if miniKey[0] != 'S' {
+		return nil, ErrMalformedMiniKey
+	}
+	for _, c := range miniKey {
+		if !strings.ContainsRune(miniKeyAlphabet, c) {
+			return nil, ErrMalformedMiniKey
+		}
+	}
+	if !isValidMiniKey(miniKey) {
+		return nil, ErrInvalidMiniKey
+	}
+
+	hash := sha256.Sum256([]byte(miniKey))
+	privKey, _ := bchec.PrivKeyFromBytes(bchec.S256(), hash[:])
+	return NewWIF(privKey, net, false)
+}
+
+// NewMiniKey generates a random 30 character mini private key and returns it
+// along with the uncompressed WIF for the private key it encodes.
+func NewMiniKey(net *chaincfg.Params) (string, *WIF, error) {
+	if net == nil {
+		return "", nil, errors.New("no network")
+	}
+
+	// Random candidates are generated until one passes the validity
+	// check, which takes 256 attempts on average.  The modulo bias of
+	// reducing a random byte to the 58 character alphabet does not matter
+	// as the 29 random characters hold far more than 128 bits of entropy.
+	var buf [MiniKeyLen - 1]byte
+	candidate := make([]byte, MiniKeyLen)
+	candidate[0] = 'S'
+	for {
+		if _, err := io.ReadFull(rand.Reader, buf[:]); err != nil {
+			return "", nil, err
+		}
+		for i, b := range buf {
+			candidate[i+1] = miniKeyAlphabet[int(b)%len(miniKeyAlphabet)]
+		}
+
+		miniKey := string(candidate)
+		if !isValidMiniKey(miniKey) {
+			continue
+		}
+		hash := sha256.Sum256(candidate)
+		privKey, _ := bchec.PrivKeyFromBytes(bchec.S256(), hash[:])
+		if privKey.D.Sign() == 0 || privKey.D.Cmp(bchec.S256().N) >= 0 {
+			continue
+		}
+		wif, err := NewWIF(privKey, net, false)
+		if err != nil {
+			return "", nil, err
+		}
+		return miniKey, wif, nil
+	}
+}
+
+// isValidMiniKey returns whether the SHA256 of the mini private key followed
+// by a '?' begins with a zero byte.
+func isValidMiniKey(miniKey string) bool {
+	hash := sha256.Sum256([]byte(miniKey + "?"))
+	return hash[0] == 0
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bchutil_test
+
+import (
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	. "github.com/gcash/bchutil"
+)
+
+func TestDecodeMiniKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		miniKey string
+		wif     string
+		err     error
+	}{
+		{
+			name:    "30 character key",
+			miniKey: "S6c56bnXQiBjk9mqSYE7ykVQ7NzrRy",
+			wif:     "5JPy8Zg7z4P7RSLsiqcqyeAF1935zjNUdMxcDeVrtU1oarrgnB7",
+		},
+		{
+			name:    "22 character key",
+			miniKey: "SzavMBLoXU6kDrqtUVmffv",
+			wif:     "5Kb8kLf9zgWQnogidDA76MzPL6TsZZY36hWXMssSzNydYXYB9KF",
+		},
+		{
+			name:    "mistyped key",
+			miniKey: "S6c56bnXQiBjk9mqSYE7ykVQ7NzrRz",
+			err:     ErrInvalidMiniKey,
+		},
+		{
+			name:    "wrong length",
+			miniKey: "S6c56bnXQiBjk9mqSYE7ykVQ7Nzr",
+			err:     ErrMalformedMiniKey,
+		},
+		{
+			name:    "wrong prefix",
+			miniKey: "T6c56bnXQiBjk9mqSYE7ykVQ7NzrRy",
+			err:     ErrMalformedMiniKey,
+		},
+		{
+			name:    "non-base58 character",
+			miniKey: "S6c56bnXQiBjk9mqSYE7ykVQ7Nzr0y",
+			err:     ErrMalformedMiniKey,
+		},
+	}
+
+	for _, test := range tests {
+		wif, err := DecodeMiniKey(test.miniKey, &chaincfg.MainNetParams)
+		if err != test.err {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if wif.CompressPubKey {
+			t.Errorf("%s: decoded WIF is compressed", test.name)
+		}
+		if got := wif.String(); got != test.wif {
+			t.Errorf("%s: unexpected WIF - got %s, want %s",
+				test.name, got, test.wif)
+		}
+	}
+}
+
+func TestNewMiniKey(t *testing.T) {
+	miniKey, wif, err := NewMiniKey(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewMiniKey: unexpected error: %v", err)
+	}
+	if len(miniKey) != MiniKeyLen {
+		t.Errorf("unexpected mini key length %d", len(miniKey))
+	}
+	if !wif.IsForNet(&chaincfg.TestNet3Params) {
+		t.Errorf("generated WIF is for the wrong network")
+	}
+
+	decoded, err := DecodeMiniKey(miniKey, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("DecodeMiniKey(%s): unexpected error: %v", miniKey, err)
+	}
+	if decoded.String() != wif.String() {
+		t.Errorf("decoded WIF %s does not match generated WIF %s",
+			decoded, wif)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package pbconv converts between bchutil types and the bchrpc protobuf messages
+served by bchd.
+
+# Overview
+
+NewTransaction, NewBlockInfo and NewBlock build the Transaction, BlockInfo and
+Block messages for a bchutil.Tx or bchutil.Block, filling in the fields bchd
+derives from the chain parameters, such as output addresses, script classes
+and the block difficulty.  Hashes are carried in internal byte order as bchd
+sends them:
+
+	pbBlock := pbconv.NewBlock(block, &chaincfg.MainNetParams, true)
+
+Tx, BlockHeader and Block rebuild the bchutil types from the messages and
+check the result against the hash the message carries, so a message which does
+not describe its transaction or block is rejected with ErrHashMismatch:
+
+	block, err := pbconv.Block(pbBlock)
+
+Fields which depend on the state of the chain, such as confirmations and the
+values spent by inputs, are not set by the conversions.  The messages have no
+fields for CashTokens, so transactions carrying tokens do not survive the
+round trip.
+*/
+package pbconv
@@ -0,0 +1,319 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pbconv
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/header"
+	"github.com/gcash/bchutil/jsonpb/testpb"
+)
+
+var (
+	// ErrHashMismatch describes an error where the hash carried by a
+	// message does not match the transaction or block built from it.
+	ErrHashMismatch = errors.New("hash does not match message contents")
+
+	// ErrMissingTransactions describes an error where a block message
+	// only carries transaction hashes, so the block cannot be rebuilt.
+	ErrMissingTransactions = errors.New("block message does not carry " +
+		"transactions")
+
+	// ErrMissingField describes an error where a message lacks a field
+	// needed for the conversion.
+	ErrMissingField = errors.New("missing message field")
+)
+
+// NewTransaction returns the bchrpc Transaction message for tx.  Outputs carry
+// their address, script class and disassembly for the network described by
+// params.  As with bchd, the address is the first address paid by the script,
+// formatted by its String method, so pay-to-pubkey outputs carry the hex
+// encoded public key.  Metadata such as confirmations, the containing block
+// and the values spent by inputs is left for the caller to fill in.
+//
+// The message has no field for CashTokens, so token data attached to outputs
+// is not represented.
+func NewTransaction(tx *bchutil.Tx, params *chaincfg.Params) *testpb.Transaction {
+	msgTx := tx.MsgTx()
+	pbTx := &testpb.Transaction{
+		Hash:     tx.Hash().CloneBytes(),
+		Version:  msgTx.Version,
+		LockTime: msgTx.LockTime,
+		Size:     int32(msgTx.SerializeSize()),
+		Inputs:   make([]*testpb.Transaction_Input, len(msgTx.TxIn)),
+		Outputs:  make([]*testpb.Transaction_Output, len(msgTx.TxOut)),
+	}
+
+	isCoinbase := isCoinbaseTx(msgTx)
+	for i, txIn := range msgTx.TxIn {
+		pbTx.Inputs[i] = &testpb.Transaction_Input{
+			Index:           uint32(i),
+			Coinbase:        isCoinbase,
+			Outpoint:        NewOutpoint(&txIn.PreviousOutPoint),
+			SignatureScript: txIn.SignatureScript,
+			Sequence:        txIn.Sequence,
+		}
+	}
+	for i, txOut := range msgTx.TxOut {
+		out := &testpb.Transaction_Output{
+			Index:        uint32(i),
+			Value:        txOut.Value,
+			PubkeyScript: txOut.PkScript,
+		}
+		class, addrs, _, _ := txscript.ExtractPkScriptAddrs(txOut.PkScript,
+			params)
+		out.ScriptClass = class.String()
+		if len(addrs) > 0 {
+			out.Address = addrs[0].String()
+		}
+		out.DisassembledScript, _ = txscript.DisasmString(txOut.PkScript)
+		pbTx.Outputs[i] = out
+	}
+	return pbTx
+}
+
+// Tx returns the transaction described by a bchrpc Transaction message.
+// Derived fields, such as addresses and script classes, are ignored.  When the
+// message carries a hash, ErrHashMismatch is returned if it differs from the
+// hash of the rebuilt transaction.
+func Tx(pbTx *testpb.Transaction) (*bchutil.Tx, error) {
+	msgTx := &wire.MsgTx{
+		Version:  pbTx.Version,
+		LockTime: pbTx.LockTime,
+		TxIn:     make([]*wire.TxIn, len(pbTx.Inputs)),
+		TxOut:    make([]*wire.TxOut, len(pbTx.Outputs)),
+	}
+	for i, in := range pbTx.Inputs {
+		if in.Outpoint == nil {
+			return nil, fmt.Errorf("%v: input %d has no outpoint",
+				ErrMissingField, i)
+		}
+		prevOut, err := OutPoint(in.Outpoint)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %v", i, err)
+		}
+		msgTx.TxIn[i] = &wire.TxIn{
+			PreviousOutPoint: *prevOut,
+			SignatureScript:  in.SignatureScript,
+			Sequence:         in.Sequence,
+		}
+	}
+	for i, out := range pbTx.Outputs {
+		msgTx.TxOut[i] = wire.NewTxOut(out.Value, out.PubkeyScript,
+			wire.TokenData{})
+	}
+
+	tx := bchutil.NewTx(msgTx)
+	if len(pbTx.Hash) != 0 {
+		if err := checkHash(pbTx.Hash, tx.Hash()); err != nil {
+			return nil, fmt.Errorf("transaction: %v", err)
+		}
+	}
+	return tx, nil
+}
+
+// NewOutpoint returns the bchrpc message for an outpoint.
+func NewOutpoint(op *wire.OutPoint) *testpb.Transaction_Input_Outpoint {
+	return &testpb.Transaction_Input_Outpoint{
+		Hash:  op.Hash.CloneBytes(),
+		Index: op.Index,
+	}
+}
+
+// OutPoint returns the outpoint described by a bchrpc outpoint message.
+func OutPoint(pbOp *testpb.Transaction_Input_Outpoint) (*wire.OutPoint, error) {
+	hash, err := chainhash.NewHash(pbOp.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewOutPoint(hash, pbOp.Index), nil
+}
+
+// OutputAddress decodes the address of a bchrpc output message for the
+// network described by params.  It returns nil without an error for outputs
+// which do not carry an address.
+func OutputAddress(out *testpb.Transaction_Output, params *chaincfg.Params) (bchutil.Address, error) {
+	if out.Address == "" {
+		return nil, nil
+	}
+	return bchutil.DecodeAddress(out.Address, params)
+}
+
+// NewUnspentOutput returns the bchrpc UnspentOutput message for the output at
+// op, created in a block at height.
+func NewUnspentOutput(op *wire.OutPoint, txOut *wire.TxOut, height int32,
+	isCoinbase bool) *testpb.UnspentOutput {
+
+	return &testpb.UnspentOutput{
+		Outpoint:     NewOutpoint(op),
+		PubkeyScript: txOut.PkScript,
+		Value:        txOut.Value,
+		IsCoinbase:   isCoinbase,
+		BlockHeight:  height,
+	}
+}
+
+// UnspentOutput returns the outpoint and output described by a bchrpc
+// UnspentOutput message.
+func UnspentOutput(pbUtxo *testpb.UnspentOutput) (*wire.OutPoint, *wire.TxOut, error) {
+	if pbUtxo.Outpoint == nil {
+		return nil, nil, fmt.Errorf("%v: unspent output has no "+
+			"outpoint", ErrMissingField)
+	}
+	op, err := OutPoint(pbUtxo.Outpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	txOut := wire.NewTxOut(pbUtxo.Value, pbUtxo.PubkeyScript,
+		wire.TokenData{})
+	return op, txOut, nil
+}
+
+// NewBlockInfo returns the bchrpc BlockInfo message for block.  The
+// difficulty is relative to the proof of work limit of params.  Confirmations
+// and the next block hash are left for the caller to fill in.
+func NewBlockInfo(block *bchutil.Block, params *chaincfg.Params) *testpb.BlockInfo {
+	hdr := &block.MsgBlock().Header
+	return &testpb.BlockInfo{
+		Hash:          block.Hash().CloneBytes(),
+		Height:        block.Height(),
+		Version:       hdr.Version,
+		PreviousBlock: hdr.PrevBlock.CloneBytes(),
+		MerkleRoot:    hdr.MerkleRoot.CloneBytes(),
+		Timestamp:     hdr.Timestamp.Unix(),
+		Bits:          hdr.Bits,
+		Nonce:         hdr.Nonce,
+		Difficulty:    difficulty(hdr.Bits, params),
+	}
+}
+
+// BlockHeader returns the block header described by a bchrpc BlockInfo
+// message.  When the message carries a hash, ErrHashMismatch is returned if it
+// differs from the hash of the rebuilt header.
+func BlockHeader(info *testpb.BlockInfo) (*wire.BlockHeader, error) {
+	hdr := &wire.BlockHeader{
+		Version:   info.Version,
+		Timestamp: time.Unix(info.Timestamp, 0),
+		Bits:      info.Bits,
+		Nonce:     info.Nonce,
+	}
+	if err := hdr.PrevBlock.SetBytes(info.PreviousBlock); err != nil {
+		return nil, fmt.Errorf("previous block: %v", err)
+	}
+	if err := hdr.MerkleRoot.SetBytes(info.MerkleRoot); err != nil {
+		return nil, fmt.Errorf("merkle root: %v", err)
+	}
+	if len(info.Hash) != 0 {
+		hash := hdr.BlockHash()
+		if err := checkHash(info.Hash, &hash); err != nil {
+			return nil, fmt.Errorf("block: %v", err)
+		}
+	}
+	return hdr, nil
+}
+
+// NewBlock returns the bchrpc Block message for block.  When fullTxs is set
+// the message carries every transaction, otherwise only their hashes.
+func NewBlock(block *bchutil.Block, params *chaincfg.Params, fullTxs bool) *testpb.Block {
+	txs := block.Transactions()
+	pbBlock := &testpb.Block{
+		Info:            NewBlockInfo(block, params),
+		TransactionData: make([]*testpb.Block_TransactionData, len(txs)),
+	}
+	for i, tx := range txs {
+		data := &testpb.Block_TransactionData{}
+		if fullTxs {
+			data.TxidsOrTxs = &testpb.Block_TransactionData_Transaction{
+				Transaction: NewTransaction(tx, params),
+			}
+		} else {
+			data.TxidsOrTxs = &testpb.Block_TransactionData_TransactionHash{
+				TransactionHash: tx.Hash().CloneBytes(),
+			}
+		}
+		pbBlock.TransactionData[i] = data
+	}
+	return pbBlock
+}
+
+// Block returns the block described by a bchrpc Block message, which must
+// carry full transactions.  The height of the block is set from the message.
+func Block(pbBlock *testpb.Block) (*bchutil.Block, error) {
+	if pbBlock.Info == nil {
+		return nil, fmt.Errorf("%v: block has no info", ErrMissingField)
+	}
+	hdr, err := BlockHeader(pbBlock.Info)
+	if err != nil {
+		return nil, err
+	}
+	msgBlock := wire.NewMsgBlock(hdr)
+	for i, data := range pbBlock.TransactionData {
+		pbTx := data.GetTransaction()
+		if pbTx == nil {
+			return nil, fmt.Errorf("%v: transaction %d",
+				ErrMissingTransactions, i)
+		}
+		tx, err := Tx(pbTx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		msgBlock.AddTransaction(tx.MsgTx())
+	}
+
+	block := bchutil.NewBlock(msgBlock)
+	block.SetHeight(pbBlock.Info.Height)
+	return block, nil
+}
+
+// difficulty returns the difficulty of bits relative to the proof of work
+// limit of params, rounded to eight decimal places as full nodes report it.
+func difficulty(bits uint32, params *chaincfg.Params) float64 {
+	powLimit := header.CompactToBig(params.PowLimitBits)
+	target := header.CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return 0
+	}
+	ratio := new(big.Rat).SetFrac(powLimit, target)
+	diff, _ := strconv.ParseFloat(ratio.FloatString(8), 64)
+	return diff
+}
+
+// checkHash returns ErrHashMismatch if the hash carried by a message differs
+// from the computed hash.
+func checkHash(msgHash []byte, hash *chainhash.Hash) error {
+	if !hash.IsEqual(bytesToHash(msgHash)) {
+		return fmt.Errorf("%v: got %x, want %v", ErrHashMismatch,
+			msgHash, hash)
+	}
+	return nil
+}
+
+// bytesToHash returns the hash held by b, or nil if b is not a hash.
+func bytesToHash(b []byte) *chainhash.Hash {
+	hash, err := chainhash.NewHash(b)
+	if err != nil {
+		return nil
+	}
+	return hash
+}
+
+// isCoinbaseTx returns whether tx is a coinbase transaction.
+func isCoinbaseTx(tx *wire.MsgTx) bool {
+	if len(tx.TxIn) != 1 {
+		return false
+	}
+	prevOut := &tx.TxIn[0].PreviousOutPoint
+	return prevOut.Index == wire.MaxPrevOutIndex &&
+		prevOut.Hash == chainhash.Hash{}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pbconv_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/pbconv"
+	"github.com/golang/protobuf/proto"
+)
+
+// readBlock returns the block serialized as hex in the named testdata file.
+func readBlock(t *testing.T, name string) *bchutil.Block {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadFile: unexpected error: %v", err)
+	}
+	serialized, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("DecodeString: unexpected error: %v", err)
+	}
+	block, err := bchutil.NewBlockFromBytes(serialized)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes: unexpected error: %v", err)
+	}
+	return block
+}
+
+// TestBlockRoundTrip ensures real blocks survive conversion to bchrpc messages
+// and back, and that the derived fields match those reported by full nodes.
+func TestBlockRoundTrip(t *testing.T) {
+	block100000 := readBlock(t, "block100000.hex")
+	block100000.SetHeight(100000)
+	genesis := bchutil.NewBlock(chaincfg.MainNetParams.GenesisBlock)
+	genesis.SetHeight(0)
+
+	tests := []struct {
+		name       string
+		block      *bchutil.Block
+		params     *chaincfg.Params
+		hash       string
+		difficulty float64
+		address    string
+		class      string
+	}{
+		{
+			name:       "mainnet block 100000",
+			block:      block100000,
+			params:     &chaincfg.MainNetParams,
+			hash:       "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506",
+			difficulty: 14484.16236123,
+			address:    "041b0e8c2567c12536aa13357b79a073dc4444acb83c4ec7a0e2f99dd7457516c5817242da796924ca4e99947d087fedf9ce467cb9f7c6287078f801df276fdf84",
+			class:      "pubkey",
+		},
+		{
+			name:       "mainnet genesis",
+			block:      genesis,
+			params:     &chaincfg.MainNetParams,
+			hash:       "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+			difficulty: 1,
+			class:      "pubkey",
+		},
+	}
+
+	for _, test := range tests {
+		pbBlock := pbconv.NewBlock(test.block, test.params, true)
+
+		info := pbBlock.Info
+		if got := chainhash.Hash(info.Hash); got.String() != test.hash {
+			t.Errorf("%s: got hash %v, want %v", test.name, got,
+				test.hash)
+		}
+		if info.Height != test.block.Height() {
+			t.Errorf("%s: got height %d, want %d", test.name,
+				info.Height, test.block.Height())
+		}
+		if info.Difficulty != test.difficulty {
+			t.Errorf("%s: got difficulty %v, want %v", test.name,
+				info.Difficulty, test.difficulty)
+		}
+
+		coinbase := pbBlock.TransactionData[0].GetTransaction()
+		if !coinbase.Inputs[0].Coinbase {
+			t.Errorf("%s: coinbase input not flagged", test.name)
+		}
+		out := coinbase.Outputs[0]
+		if out.ScriptClass != test.class {
+			t.Errorf("%s: got script class %q, want %q", test.name,
+				out.ScriptClass, test.class)
+		}
+		if test.address != "" && out.Address != test.address {
+			t.Errorf("%s: got address %q, want %q", test.name,
+				out.Address, test.address)
+		}
+		if out.Address != "" {
+			addr, err := pbconv.OutputAddress(out, test.params)
+			if err != nil {
+				t.Errorf("%s: OutputAddress: unexpected error: %v",
+					test.name, err)
+			} else if addr.String() != out.Address {
+				t.Errorf("%s: OutputAddress: got %v, want %v",
+					test.name, addr, out.Address)
+			}
+		}
+
+		// Round trip through the wire encoding of the message as well
+		// as the conversion.
+		serialized, err := proto.Marshal(pbBlock)
+		if err != nil {
+			t.Errorf("%s: Marshal: unexpected error: %v", test.name, err)
+			continue
+		}
+		pbBlock.Reset()
+		if err := proto.Unmarshal(serialized, pbBlock); err != nil {
+			t.Errorf("%s: Unmarshal: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		block, err := pbconv.Block(pbBlock)
+		if err != nil {
+			t.Errorf("%s: Block: unexpected error: %v", test.name, err)
+			continue
+		}
+		got, _ := block.Bytes()
+		want, _ := test.block.Bytes()
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: round trip changed the block", test.name)
+		}
+		if block.Height() != test.block.Height() {
+			t.Errorf("%s: got height %d, want %d", test.name,
+				block.Height(), test.block.Height())
+		}
+
+		// Blocks carrying only hashes cannot be rebuilt.
+		hashesOnly := pbconv.NewBlock(test.block, test.params, false)
+		txHash := hashesOnly.TransactionData[0].GetTransactionHash()
+		if !bytes.Equal(txHash, test.block.Transactions()[0].Hash()[:]) {
+			t.Errorf("%s: got transaction hash %x, want %v", test.name,
+				txHash, test.block.Transactions()[0].Hash())
+		}
+		_, err = pbconv.Block(hashesOnly)
+		if err == nil || !strings.HasPrefix(err.Error(),
+			pbconv.ErrMissingTransactions.Error()) {
+
+			t.Errorf("%s: Block: got error %v, want %v", test.name,
+				err, pbconv.ErrMissingTransactions)
+		}
+	}
+}
+
+// TestTxErrors ensures malformed transaction messages are rejected.
+func TestTxErrors(t *testing.T) {
+	block := readBlock(t, "block100000.hex")
+	tx := block.Transactions()[1]
+
+	tests := []struct {
+		name   string
+		modify func(*bchutil.Tx) error
+		err    error
+	}{
+		{
+			name: "modified lock time",
+			modify: func(tx *bchutil.Tx) error {
+				pbTx := pbconv.NewTransaction(tx, &chaincfg.MainNetParams)
+				pbTx.LockTime++
+				_, err := pbconv.Tx(pbTx)
+				return err
+			},
+			err: pbconv.ErrHashMismatch,
+		},
+		{
+			name: "missing outpoint",
+			modify: func(tx *bchutil.Tx) error {
+				pbTx := pbconv.NewTransaction(tx, &chaincfg.MainNetParams)
+				pbTx.Inputs[0].Outpoint = nil
+				_, err := pbconv.Tx(pbTx)
+				return err
+			},
+			err: pbconv.ErrMissingField,
+		},
+		{
+			name: "token data",
+			modify: func(tx *bchutil.Tx) error {
+				msgTx := tx.MsgTx().Copy()
+				msgTx.TxOut[0].TokenData = wire.TokenData{
+					CategoryID: chainhash.Hash{0x01},
+					Amount:     1,
+				}
+				pbTx := pbconv.NewTransaction(bchutil.NewTx(msgTx),
+					&chaincfg.MainNetParams)
+				_, err := pbconv.Tx(pbTx)
+				return err
+			},
+			err: pbconv.ErrHashMismatch,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.modify(tx)
+		if err == nil || !strings.Contains(err.Error(), test.err.Error()) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+// TestUnspentOutput ensures unspent outputs survive conversion to bchrpc
+// messages and back.
+func TestUnspentOutput(t *testing.T) {
+	block := readBlock(t, "block100000.hex")
+	tx := block.Transactions()[2]
+	op := wire.NewOutPoint(tx.Hash(), 1)
+	txOut := tx.MsgTx().TxOut[1]
+
+	pbUtxo := pbconv.NewUnspentOutput(op, txOut, 100000, false)
+	gotOp, gotOut, err := pbconv.UnspentOutput(pbUtxo)
+	if err != nil {
+		t.Fatalf("UnspentOutput: unexpected error: %v", err)
+	}
+	if *gotOp != *op {
+		t.Errorf("UnspentOutput: got outpoint %v, want %v", gotOp, op)
+	}
+	if gotOut.Value != txOut.Value || !bytes.Equal(gotOut.PkScript,
+		txOut.PkScript) {
+
+		t.Errorf("UnspentOutput: got output %v, want %v", gotOut, txOut)
+	}
+	if pbUtxo.BlockHeight != 100000 || pbUtxo.IsCoinbase {
+		t.Errorf("NewUnspentOutput: got height %d coinbase %v",
+			pbUtxo.BlockHeight, pbUtxo.IsCoinbase)
+	}
+
+	pbUtxo.Outpoint.Hash = pbUtxo.Outpoint.Hash[:31]
+	if _, _, err := pbconv.UnspentOutput(pbUtxo); err == nil {
+		t.Errorf("UnspentOutput: expected error for short hash")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package pbschema generates JSON Schema and OpenAPI documents describing
+protobuf messages as rendered by the jsonpb package.
+
+# Overview
+
+Generic protobuf tools describe bytes fields as the base64 strings of the
+standard protobuf JSON mapping, which is not what the jsonpb marshalers write.
+A Generator walks message descriptors and describes bytes fields as hex
+strings and hash fields, as decided by jsonpb.HashField or a custom rule, as
+hashes in reversed byte order, which are plain hex when not 32 bytes long.
+64-bit integers may be numbers or strings, and well-known types keep their
+standard JSON forms:
+
+	gen := new(pbschema.Generator)
+	schema := gen.Schema(blockInfo.ProtoReflect().Descriptor())
+	data, err := json.MarshalIndent(schema, "", "  ")
+
+The options of the Generator must match those of the marshaler whose output is
+being described.
+
+# OpenAPI
+
+OpenAPI describes the methods of gRPC services as exposed by the gateway
+package, with each method at the path of its gRPC method name, taking its
+request as a POST body or a GET query parameter, and server streams as
+server-sent events:
+
+	doc := gen.OpenAPI(pbschema.Info{Title: "bchrpc", Version: "1.0.0"},
+		bchrpcService)
+
+Documents use OpenAPI 3.1, whose schemas are JSON Schema, so the same schemas
+describe the messages in both kinds of document.
+*/
+package pbschema
@@ -0,0 +1,204 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pbschema
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OpenAPIVersion is the OpenAPI version of the documents returned by
+// Generator.OpenAPI.  It is the first version whose schemas are JSON Schema,
+// so the schemas of Generator.Schema may be used unchanged.
+const OpenAPIVersion = "3.1.0"
+
+// ErrorSchemaName is the name of the schema describing the body of an error
+// response in the documents returned by Generator.OpenAPI.
+const ErrorSchemaName = "Error"
+
+// schemaRefPrefix is the prefix of references to the schemas of an OpenAPI
+// document.
+const schemaRefPrefix = "#/components/schemas/"
+
+// OpenAPI is an OpenAPI document, limited to the objects needed to describe an
+// HTTP/JSON gateway to gRPC services.
+type OpenAPI struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info holds the metadata of an OpenAPI document.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem describes the operations available on a path.
+type PathItem struct {
+	Get  *Operation `json:"get,omitempty"`
+	Post *Operation `json:"post,omitempty"`
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the content of a request or response body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas of an OpenAPI document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// OpenAPI returns an OpenAPI document describing the methods of the services
+// described by sds as exposed by the gateway package.  Each unary and server
+// streaming method has a POST operation at the path of its gRPC method name,
+// taking the JSON request message as its body, and a GET operation taking the
+// request from the request query parameter.  Server streaming methods respond
+// with server-sent events each holding one message.  Methods streaming from
+// the client are left out, as the gateway does not expose them.
+func (g *Generator) OpenAPI(info Info, sds ...protoreflect.ServiceDescriptor) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				ErrorSchemaName: errorSchema(),
+			},
+		},
+	}
+	defs := doc.Components.Schemas
+
+	for _, sd := range sds {
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			md := methods.Get(i)
+			if md.IsStreamingClient() {
+				continue
+			}
+
+			path := fmt.Sprintf("/%s/%s", sd.FullName(), md.Name())
+			input := g.messageRef(md.Input(), defs, schemaRefPrefix)
+			output := g.messageRef(md.Output(), defs, schemaRefPrefix)
+
+			op := &Operation{
+				OperationID: string(sd.Name()) + "_" + string(md.Name()),
+				Tags:        []string{string(sd.FullName())},
+				RequestBody: &RequestBody{
+					Content: map[string]*MediaType{
+						"application/json": {Schema: input},
+					},
+				},
+				Responses: responses(output, md.IsStreamingServer()),
+			}
+			if md.IsStreamingServer() {
+				op.Summary = "Streams " + string(md.Output().Name()) +
+					" messages as server-sent events."
+			}
+
+			get := *op
+			get.OperationID += "_get"
+			get.RequestBody = nil
+			get.Parameters = []*Parameter{{
+				Name: "request",
+				In:   "query",
+				Description: "The JSON request message, which is " +
+					"empty when left out.",
+				Schema: &Schema{Type: "string"},
+			}}
+			doc.Paths[path] = &PathItem{Get: &get, Post: op}
+		}
+	}
+	return doc
+}
+
+// responses returns the responses of an operation whose response message is
+// described by output.
+func responses(output *Schema, serverStreams bool) map[string]*Response {
+	ok := &Response{
+		Description: "The response message.",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: output},
+		},
+	}
+	if serverStreams {
+		ok = &Response{
+			Description: "A stream of server-sent events.  Each data " +
+				"event holds one response message on a single " +
+				"line.  An end event follows the last message and " +
+				"an error event, holding an error, ends a stream " +
+				"which fails.",
+			Content: map[string]*MediaType{
+				"text/event-stream": {Schema: output},
+			},
+		}
+	}
+	return map[string]*Response{
+		"200": ok,
+		"default": {
+			Description: "The gRPC status of a failed call.",
+			Content: map[string]*MediaType{
+				"application/json": {
+					Schema: &Schema{Ref: schemaRefPrefix +
+						ErrorSchemaName},
+				},
+			},
+		},
+	}
+}
+
+// errorSchema returns the schema of the body of an error response.
+func errorSchema() *Schema {
+	return &Schema{
+		Title: ErrorSchemaName,
+		Type:  "object",
+		Properties: map[string]*Schema{
+			"code": {
+				Type:        "integer",
+				Description: "The gRPC status code.",
+			},
+			"message": {
+				Type:        "string",
+				Description: "The gRPC status message.",
+			},
+		},
+		Required: []string{"code", "message"},
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pbschema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchutil/chaingen"
+	"github.com/gcash/bchutil/jsonpb"
+	pb "github.com/gcash/bchutil/jsonpb/testpb"
+	"github.com/gcash/bchutil/pbconv"
+	"github.com/gcash/bchutil/pbschema"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // testpb is generated with github.com/golang/protobuf
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// validator checks JSON values against the subset of JSON Schema produced by
+// the generator.
+type validator struct {
+	defs      map[string]*pbschema.Schema
+	refPrefix string
+}
+
+// validate returns an error describing the first way v does not match s.
+func (val *validator) validate(s *pbschema.Schema, v interface{}, path string) error {
+	if s.Ref != "" {
+		def, ok := val.defs[strings.TrimPrefix(s.Ref, val.refPrefix)]
+		if !ok || !strings.HasPrefix(s.Ref, val.refPrefix) {
+			return fmt.Errorf("%s: unresolved reference %s", path, s.Ref)
+		}
+		return val.validate(def, v, path)
+	}
+
+	if s.Type != nil {
+		types, ok := s.Type.([]string)
+		if !ok {
+			types = []string{s.Type.(string)}
+		}
+		var match bool
+		for _, typ := range types {
+			if hasType(v, typ) {
+				match = true
+			}
+		}
+		if !match {
+			return fmt.Errorf("%s: %v is not of type %v", path, v, types)
+		}
+	}
+	if str, ok := v.(string); ok && s.Pattern != "" {
+		if !regexp.MustCompile(s.Pattern).MatchString(str) {
+			return fmt.Errorf("%s: %q does not match %s", path, str,
+				s.Pattern)
+		}
+	}
+	if s.Enum != nil {
+		var match bool
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				match = true
+			}
+		}
+		if !match {
+			return fmt.Errorf("%s: %v is not one of %v", path, v, s.Enum)
+		}
+	}
+
+	switch tv := v.(type) {
+	case []interface{}:
+		if s.Items == nil {
+			return nil
+		}
+		for i, ev := range tv {
+			err := val.validate(s.Items, ev, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := tv[name]; !ok {
+				return fmt.Errorf("%s: missing %s", path, name)
+			}
+		}
+		for k, ev := range tv {
+			prop, ok := s.Properties[k]
+			if !ok {
+				switch additional := s.AdditionalProperties.(type) {
+				case bool:
+					if !additional {
+						return fmt.Errorf("%s: unexpected "+
+							"property %s", path, k)
+					}
+					continue
+				case *pbschema.Schema:
+					prop = additional
+				default:
+					continue
+				}
+			}
+			if err := val.validate(prop, ev, path+"."+k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasType returns whether the JSON value v, decoded using json.Number, is of
+// the named JSON Schema type.
+func hasType(v interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		return ok && !strings.ContainsAny(string(n), ".eE")
+	}
+	return false
+}
+
+// decode decodes a JSON document using json.Number for numbers.
+func decode(t *testing.T, s string) interface{} {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("Decode: unexpected error: %v", err)
+	}
+	return v
+}
+
+// testMessages returns bchrpc messages built from a generated chain, which
+// carry every kind of field found in the bchrpc blocks and transactions.
+func testMessages(t *testing.T) []proto.Message {
+	params := &chaincfg.RegressionNetParams
+	gen, err := chaingen.New(&chaingen.Config{Params: params,
+		SpendsPerBlock: 1})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	blocks, err := gen.Generate(int(params.CoinbaseMaturity) + 2)
+	if err != nil {
+		t.Fatalf("Generate: unexpected error: %v", err)
+	}
+	block := blocks[len(blocks)-1]
+	if len(block.Transactions()) < 2 {
+		t.Fatalf("generated block has no spends")
+	}
+
+	tx := pbconv.NewTransaction(block.Transactions()[1], params)
+	tx.BlockHash = block.Hash().CloneBytes()
+	tx.BlockHeight = block.Height()
+	tx.Confirmations = 1
+	tx.Inputs[0].Value = 5000000000
+
+	var hashes [][]byte
+	for _, tx := range block.Transactions() {
+		hashes = append(hashes, tx.Hash().CloneBytes())
+	}
+
+	return []proto.Message{
+		pbconv.NewBlock(block, params, true),
+		pbconv.NewBlock(block, params, false),
+		&pb.GetBlockInfoResponse{Info: pbconv.NewBlockInfo(block, params)},
+		&pb.BlockNotification{
+			Type:  pb.BlockNotification_DISCONNECTED,
+			Block: pbconv.NewBlockInfo(block, params),
+		},
+		&pb.TransactionNotification{
+			Type: pb.TransactionNotification_CONFIRMED,
+			Transaction: &pb.TransactionNotification_ConfirmedTransaction{
+				ConfirmedTransaction: tx,
+			},
+		},
+		&pb.TransactionNotification{
+			Type: pb.TransactionNotification_UNCONFIRMED,
+			Transaction: &pb.TransactionNotification_UnconfirmedTransaction{
+				UnconfirmedTransaction: &pb.MempoolTransaction{
+					Transaction:      tx,
+					AddedTime:        1555442269,
+					AddedHeight:      578547,
+					Fee:              1 << 60,
+					StartingPriority: 233.78512396694214,
+				},
+			},
+		},
+		&pb.GetMerkleProofResponse{
+			Block:  pbconv.NewBlockInfo(block, params),
+			Hashes: hashes,
+			Flags:  []byte{0x1d},
+		},
+		&pb.GetAddressUnspentOutputsResponse{
+			Outputs: []*pb.UnspentOutput{
+				pbconv.NewUnspentOutput(
+					&block.Transactions()[1].MsgTx().TxIn[0].PreviousOutPoint,
+					block.Transactions()[1].MsgTx().TxOut[0],
+					block.Height(), true),
+			},
+		},
+	}
+}
+
+// TestSchemaValidatesMarshaledMessages ensures the JSON of bchrpc messages
+// written by the jsonpb marshalers validates against the generated schemas
+// for each combination of options.
+func TestSchemaValidatesMarshaledMessages(t *testing.T) {
+	msgs := testMessages(t)
+
+	tests := []struct {
+		name         string
+		enumsAsInts  bool
+		emitDefaults bool
+		origName     bool
+	}{
+		{name: "default options"},
+		{name: "enums as ints", enumsAsInts: true},
+		{name: "emit defaults", emitDefaults: true},
+		{name: "original names", origName: true},
+	}
+
+	for _, test := range tests {
+		gen := &pbschema.Generator{
+			EnumsAsInts: test.enumsAsInts,
+			OrigName:    test.origName,
+		}
+		m := &jsonpb.Marshaler{
+			EnumsAsInts:  test.enumsAsInts,
+			EmitDefaults: test.emitDefaults,
+			OrigName:     test.origName,
+		}
+		mV2 := &jsonpb.MarshalerV2{
+			EnumsAsInts:  test.enumsAsInts,
+			EmitDefaults: test.emitDefaults,
+			OrigName:     test.origName,
+		}
+
+		for _, msg := range msgs {
+			md := proto.MessageReflect(msg).Descriptor()
+			schema := gen.Schema(md)
+			val := &validator{defs: schema.Defs, refPrefix: "#/$defs/"}
+
+			s, err := m.MarshalToString(msg)
+			if err != nil {
+				t.Fatalf("%s: MarshalToString: unexpected error: %v",
+					test.name, err)
+			}
+			err = val.validate(schema, decode(t, s), string(md.Name()))
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+
+			s, err = mV2.MarshalToString(proto.MessageV2(msg))
+			if err != nil {
+				t.Fatalf("%s: MarshalToString: unexpected error: %v",
+					test.name, err)
+			}
+			err = val.validate(schema, decode(t, s), string(md.Name()))
+			if err != nil {
+				t.Errorf("%s: v2: %v", test.name, err)
+			}
+		}
+	}
+}
+
+// TestSchemaRejectsStandardMapping ensures the schemas describe the hex
+// conventions of jsonpb rather than the standard protobuf JSON mapping.
+func TestSchemaRejectsStandardMapping(t *testing.T) {
+	gen := new(pbschema.Generator)
+	schema := gen.Schema(proto.MessageReflect(new(pb.BlockInfo)).Descriptor())
+	val := &validator{defs: schema.Defs, refPrefix: "#/$defs/"}
+
+	hash := strings.Repeat("ab", 32)
+	tests := []struct {
+		name  string
+		json  string
+		valid bool
+	}{
+		{
+			name:  "hex hash",
+			json:  `{"hash":"` + hash + `","height":5,"timestamp":1555442269}`,
+			valid: true,
+		},
+		{
+			name: "base64 hash",
+			json: `{"hash":"q6urq6urq6urq6urq6urq6urq6urq6urq6urq6urq6s="}`,
+		},
+		{
+			name:  "hash of another length as plain hex",
+			json:  `{"hash":"abab"}`,
+			valid: true,
+		},
+		{
+			name: "odd length hash",
+			json: `{"hash":"aba"}`,
+		},
+		{
+			name: "uppercase hash",
+			json: `{"hash":"` + strings.ToUpper(hash) + `"}`,
+		},
+		{
+			name:  "64-bit integer as string",
+			json:  `{"timestamp":"1555442269"}`,
+			valid: true,
+		},
+		{
+			name: "unknown field",
+			json: `{"size":1}`,
+		},
+		{
+			name: "height as string",
+			json: `{"height":"5"}`,
+		},
+	}
+
+	for _, test := range tests {
+		err := val.validate(schema, decode(t, test.json), "BlockInfo")
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected validation error", test.name)
+		}
+	}
+
+	// Values of hash fields which are not hashes are written as plain hex
+	// and still validate.
+	m := new(jsonpb.MarshalerV2)
+	s, err := m.MarshalToString(proto.MessageV2(&pb.BlockInfo{
+		Hash: []byte{0xab, 0xcd},
+	}))
+	if err != nil {
+		t.Fatalf("MarshalToString: unexpected error: %v", err)
+	}
+	if err := val.validate(schema, decode(t, s), "BlockInfo"); err != nil {
+		t.Errorf("plain hex hash: unexpected error: %v", err)
+	}
+
+	// Hash fields and other bytes fields are told apart.
+	def := schema.Defs["pb.BlockInfo"]
+	if f := def.Properties["merkleRoot"].Format; f != "hash" {
+		t.Errorf("merkleRoot: got format %q, want hash", f)
+	}
+	outputs := gen.Schema(proto.MessageReflect(new(pb.Transaction_Output)).Descriptor())
+	def = outputs.Defs["pb.Transaction.Output"]
+	if f := def.Properties["pubkeyScript"].Format; f != "hex" {
+		t.Errorf("pubkeyScript: got format %q, want hex", f)
+	}
+}
+
+// TestOpenAPI ensures the OpenAPI document describes the methods exposed by
+// the gateway and that its schemas validate marshaled messages.
+func TestOpenAPI(t *testing.T) {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName("pb.bchrpc")
+	if err != nil {
+		t.Fatalf("FindDescriptorByName: unexpected error: %v", err)
+	}
+	sd := d.(protoreflect.ServiceDescriptor)
+
+	gen := new(pbschema.Generator)
+	doc := gen.OpenAPI(pbschema.Info{Title: "bchrpc", Version: "1.0.0"}, sd)
+	if doc.OpenAPI != pbschema.OpenAPIVersion {
+		t.Errorf("got version %q, want %q", doc.OpenAPI,
+			pbschema.OpenAPIVersion)
+	}
+
+	// Every method but the client streaming one is described.
+	if len(doc.Paths) != sd.Methods().Len()-1 {
+		t.Errorf("got %d paths, want %d", len(doc.Paths),
+			sd.Methods().Len()-1)
+	}
+	if _, ok := doc.Paths["/pb.bchrpc/SubscribeTransactionStream"]; ok {
+		t.Errorf("client streaming method described")
+	}
+	unary := doc.Paths["/pb.bchrpc/GetBlockInfo"]
+	if unary == nil || unary.Post == nil || unary.Get == nil {
+		t.Fatalf("GetBlockInfo: unexpected operations %+v", unary)
+	}
+	ref := unary.Post.RequestBody.Content["application/json"].Schema.Ref
+	if ref != "#/components/schemas/pb.GetBlockInfoRequest" {
+		t.Errorf("GetBlockInfo: got request %q", ref)
+	}
+	if len(unary.Get.Parameters) != 1 ||
+		unary.Get.Parameters[0].Name != "request" {
+
+		t.Errorf("GetBlockInfo: unexpected GET parameters %+v",
+			unary.Get.Parameters)
+	}
+	if _, ok := unary.Get.Responses["200"].Content["application/json"]; !ok {
+		t.Errorf("GetBlockInfo: GET response is not JSON")
+	}
+	stream := doc.Paths["/pb.bchrpc/SubscribeBlocks"]
+	if stream == nil || stream.Post == nil || stream.Get == nil {
+		t.Fatalf("SubscribeBlocks: unexpected operations %+v", stream)
+	}
+	if _, ok := stream.Get.Responses["200"].Content["text/event-stream"]; !ok {
+		t.Errorf("SubscribeBlocks: response is not an event stream")
+	}
+
+	// Every reference in the document resolves.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+	for _, match := range regexp.MustCompile(`"\$ref":"([^"]*)"`).FindAllStringSubmatch(string(data), -1) {
+		name := strings.TrimPrefix(match[1], "#/components/schemas/")
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("unresolved reference %s", match[1])
+		}
+	}
+
+	val := &validator{defs: doc.Components.Schemas,
+		refPrefix: "#/components/schemas/"}
+	m := new(jsonpb.Marshaler)
+	for _, msg := range testMessages(t) {
+		name := string(proto.MessageReflect(msg).Descriptor().FullName())
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			continue
+		}
+		s, err := m.MarshalToString(msg)
+		if err != nil {
+			t.Fatalf("MarshalToString: unexpected error: %v", err)
+		}
+		schema := &pbschema.Schema{Ref: "#/components/schemas/" + name}
+		if err := val.validate(schema, decode(t, s), name); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pbschema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcash/bchutil/jsonpb"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SchemaVersion is the JSON Schema dialect of the documents returned by
+// Generator.Schema.
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+const (
+	// hexPattern matches the hex encoding of any bytes value.  It also
+	// matches every value of a hash field, which holds a hash in reversed
+	// byte order, plain hex for values which are not 32 bytes long or the
+	// empty string written for an unset hash when defaults are emitted.
+	hexPattern = "^([0-9a-f]{2})*$"
+
+	// intPattern matches a 64-bit integer rendered as a string.
+	intPattern = "^-?[0-9]+$"
+)
+
+// Schema is a JSON Schema, limited to the keywords needed to describe the JSON
+// form of protobuf messages.  Type is either a single type name or a slice of
+// type names, and AdditionalProperties is either a bool or a *Schema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Generator builds JSON Schema and OpenAPI documents describing protobuf
+// messages as rendered by the marshalers of the jsonpb package.  Bytes fields
+// are described as hex strings and hash fields as 64 character hashes in
+// reversed byte order, rather than the base64 of the standard protobuf JSON
+// mapping.
+//
+// The options match those of jsonpb.Marshaler and must be set the same way as
+// the marshaler producing the documents being described.
+type Generator struct {
+	// Whether enum values are rendered as integers, as opposed to string
+	// values.
+	EnumsAsInts bool
+
+	// Whether the original (.proto) names are used for fields.
+	OrigName bool
+
+	// IsHash reports whether a bytes field holds hashes.  When nil,
+	// jsonpb.HashField is used.
+	IsHash func(fd protoreflect.FieldDescriptor) bool
+}
+
+// Schema returns a JSON Schema document describing the JSON form of the
+// message described by md.  The message and every message it refers to are
+// defined under $defs, keyed by their full name.
+func (g *Generator) Schema(md protoreflect.MessageDescriptor) *Schema {
+	defs := make(map[string]*Schema)
+	root := g.messageRef(md, defs, "#/$defs/")
+	if root.Ref == "" {
+		// Well-known types are described inline.
+		root.Schema = SchemaVersion
+		return root
+	}
+	return &Schema{
+		Schema: SchemaVersion,
+		Ref:    root.Ref,
+		Defs:   defs,
+	}
+}
+
+// messageRef returns the schema of a value of the message described by md.
+// Well-known types are described inline and other messages by a reference to
+// their definition, which is added to defs along with the definitions of the
+// messages it refers to.
+func (g *Generator) messageRef(md protoreflect.MessageDescriptor,
+	defs map[string]*Schema, refPrefix string) *Schema {
+
+	if s := wellKnown(md); s != nil {
+		return s
+	}
+
+	name := string(md.FullName())
+	ref := &Schema{Ref: refPrefix + name}
+	if _, ok := defs[name]; ok {
+		return ref
+	}
+
+	// Add the definition before walking the fields, so recursive
+	// messages refer back to it.
+	def := &Schema{
+		Title:                name,
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	defs[name] = def
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		prop := g.field(fd, defs, refPrefix)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			prop.Description = joinDescription(prop.Description,
+				fmt.Sprintf("Member of oneof %s, of which at most "+
+					"one field is set.", oneof.Name()))
+		}
+		def.Properties[g.fieldName(fd)] = prop
+	}
+	return ref
+}
+
+// fieldName returns the JSON name of a field.
+func (g *Generator) fieldName(fd protoreflect.FieldDescriptor) string {
+	if g.OrigName {
+		return string(fd.Name())
+	}
+	return fd.JSONName()
+}
+
+// field returns the schema of the value of the field described by fd.
+func (g *Generator) field(fd protoreflect.FieldDescriptor,
+	defs map[string]*Schema, refPrefix string) *Schema {
+
+	switch {
+	case fd.IsMap():
+		// Map keys are always strings in JSON, and bytes values follow
+		// the hash rule of the map field itself.
+		valueFd := fd.MapValue()
+		value := g.value(valueFd, defs, refPrefix)
+		if valueFd.Kind() == protoreflect.BytesKind {
+			value = g.bytes(fd)
+		}
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: value,
+		}
+
+	case fd.IsList():
+		return &Schema{
+			Type:  "array",
+			Items: g.value(fd, defs, refPrefix),
+		}
+	}
+	return g.value(fd, defs, refPrefix)
+}
+
+// value returns the schema of a single value of the field described by fd,
+// such as an element of a repeated field.
+func (g *Generator) value(fd protoreflect.FieldDescriptor,
+	defs map[string]*Schema, refPrefix string) *Schema {
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+
+	case protoreflect.BytesKind:
+		return g.bytes(fd)
+
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind,
+		protoreflect.Sfixed32Kind:
+
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "uint32"}
+
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind:
+
+		return int64Schema("int64")
+
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64Schema("uint64")
+
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+
+	case protoreflect.EnumKind:
+		return g.enum(fd.Enum())
+
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageRef(fd.Message(), defs, refPrefix)
+	}
+	return &Schema{}
+}
+
+// bytes returns the schema of a bytes value of the field described by fd.
+func (g *Generator) bytes(fd protoreflect.FieldDescriptor) *Schema {
+	isHash := g.IsHash
+	if isHash == nil {
+		isHash = jsonpb.HashField
+	}
+	if isHash(fd) {
+		return &Schema{
+			Type:    "string",
+			Format:  "hash",
+			Pattern: hexPattern,
+			Description: "A 32 byte hash as hex in reversed byte " +
+				"order, as displayed by block explorers.  Values " +
+				"of other lengths are plain hex.",
+		}
+	}
+	return &Schema{
+		Type:        "string",
+		Format:      "hex",
+		Pattern:     hexPattern,
+		Description: "Bytes as lowercase hex.",
+	}
+}
+
+// enum returns the schema of a value of the enum described by ed.
+func (g *Generator) enum(ed protoreflect.EnumDescriptor) *Schema {
+	if ed.FullName() == "google.protobuf.NullValue" {
+		return &Schema{Type: "null"}
+	}
+
+	values := ed.Values()
+	s := &Schema{Title: string(ed.FullName())}
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if g.EnumsAsInts {
+			s.Enum = append(s.Enum, int32(v.Number()))
+		} else {
+			s.Enum = append(s.Enum, string(v.Name()))
+		}
+	}
+	if g.EnumsAsInts {
+		s.Type = "integer"
+	} else {
+		s.Type = "string"
+	}
+	return s
+}
+
+// int64Schema returns the schema of a 64-bit integer, which is rendered as a
+// number when a javascript number holds it exactly and as a string otherwise.
+func int64Schema(format string) *Schema {
+	return &Schema{
+		Type:    []string{"integer", "string"},
+		Format:  format,
+		Pattern: intPattern,
+	}
+}
+
+// wellKnown returns the schema of a value of a well-known type, which have
+// their own JSON forms, or nil if md does not describe a well-known type.
+func wellKnown(md protoreflect.MessageDescriptor) *Schema {
+	name := string(md.FullName())
+	if !strings.HasPrefix(name, "google.protobuf.") {
+		return nil
+	}
+
+	switch strings.TrimPrefix(name, "google.protobuf.") {
+	case "Any":
+		return &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"@type": {Type: "string"},
+			},
+			Required: []string{"@type"},
+		}
+	case "Timestamp":
+		return &Schema{Type: "string", Format: "date-time"}
+	case "Duration":
+		return &Schema{Type: "string", Pattern: `^-?[0-9]+(\.[0-9]+)?s$`}
+	case "FieldMask":
+		return &Schema{Type: "string"}
+	case "Struct", "Empty":
+		return &Schema{Type: "object"}
+	case "ListValue":
+		return &Schema{Type: "array"}
+	case "Value":
+		return &Schema{}
+	case "BoolValue":
+		return &Schema{Type: "boolean"}
+	case "StringValue":
+		return &Schema{Type: "string"}
+	case "BytesValue":
+		return &Schema{Type: "string", Format: "hex", Pattern: hexPattern}
+	case "Int32Value":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "UInt32Value":
+		return &Schema{Type: "integer", Format: "uint32"}
+	case "Int64Value":
+		return int64Schema("int64")
+	case "UInt64Value":
+		return int64Schema("uint64")
+	case "FloatValue":
+		return &Schema{Type: "number", Format: "float"}
+	case "DoubleValue":
+		return &Schema{Type: "number", Format: "double"}
+	}
+	return nil
+}
+
+// joinDescription joins two descriptions with a space.
+func joinDescription(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package policy provides local checks of the relay policy applied by bitcoin
+cash nodes.
+
+# Overview
+
+Nodes refuse to relay transactions which are valid by consensus but do not
+meet a number of additional "standardness" rules.  Discovering this only when
+a node rejects a broadcast is awkward, so this package allows a transaction to
+be checked locally first.
+
+CheckTransactionStandard returns a RuleError for every rule the transaction
+violates rather than stopping at the first one.  Each RuleError carries an
+ErrorCode along with the index of the offending input or output so callers can
+react programmatically:
+
+	for _, v := range policy.CheckTransactionStandard(tx, policy.DefaultPolicy()) {
+		if v.ErrorCode == policy.ErrDust {
+			fmt.Printf("output %d is dust\n", v.Output)
+		}
+	}
+
+# Dust
+
+An output is dust when spending it would cost more than a third of its value
+at the minimum relay fee rate.  IsDust and DustThreshold evaluate this for a
+single wire.TxOut at any fee rate.
+*/
+package policy
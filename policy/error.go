@@ -0,0 +1,143 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"fmt"
+)
+
+// ErrorCode identifies a kind of standardness violation.
+type ErrorCode int
+
+// These constants are used to identify a specific RuleError.
+const (
+	// ErrTxVersion indicates the transaction version is outside of the
+	// range accepted for relay.
+	ErrTxVersion ErrorCode = iota
+
+	// ErrTxTooSmall indicates the serialized transaction is smaller than
+	// the minimum allowed transaction size.
+	ErrTxTooSmall
+
+	// ErrTxTooLarge indicates the serialized transaction is larger than
+	// the maximum standard transaction size.
+	ErrTxTooLarge
+
+	// ErrSigScriptTooLarge indicates an input signature script exceeds
+	// the maximum standard signature script size.
+	ErrSigScriptTooLarge
+
+	// ErrSigScriptNotPushOnly indicates an input signature script
+	// contains opcodes other than data pushes.
+	ErrSigScriptNotPushOnly
+
+	// ErrNonStandardScript indicates an output public key script does not
+	// match any of the standard script templates.
+	ErrNonStandardScript
+
+	// ErrBareMultiSig indicates a bare multi-signature output script has
+	// an invalid or non-standard number of keys or signatures.
+	ErrBareMultiSig
+
+	// ErrDust indicates an output pays an amount that costs more to
+	// spend than it is worth at the configured relay fee.
+	ErrDust
+
+	// ErrUnspendable indicates a non data-carrier output is provably
+	// unspendable.
+	ErrUnspendable
+
+	// ErrDataCarrierTooLarge indicates the combined size of all OP_RETURN
+	// outputs exceeds the maximum standard data carrier size.
+	ErrDataCarrierTooLarge
+
+	// ErrTooManySigOps indicates the transaction contains more signature
+	// operations than allowed for a standard transaction.
+	ErrTooManySigOps
+
+	// ErrTokensNotActive indicates an output carries token data but token
+	// outputs are not allowed by the policy.
+	ErrTokensNotActive
+
+	// ErrInvalidTokenData indicates an output carries malformed token
+	// data, such as an invalid bitfield, an oversized commitment or an
+	// out of range fungible amount.
+	ErrInvalidTokenData
+
+	// ErrTokenPrefix indicates an output public key script starts with the
+	// token prefix byte and would therefore be misinterpreted as token
+	// data once serialized.
+	ErrTokenPrefix
+)
+
+// Map of ErrorCode values back to their constant names for pretty printing.
+var errorCodeStrings = map[ErrorCode]string{
+	ErrTxVersion:            "ErrTxVersion",
+	ErrTxTooSmall:           "ErrTxTooSmall",
+	ErrTxTooLarge:           "ErrTxTooLarge",
+	ErrSigScriptTooLarge:    "ErrSigScriptTooLarge",
+	ErrSigScriptNotPushOnly: "ErrSigScriptNotPushOnly",
+	ErrNonStandardScript:    "ErrNonStandardScript",
+	ErrBareMultiSig:         "ErrBareMultiSig",
+	ErrDust:                 "ErrDust",
+	ErrUnspendable:          "ErrUnspendable",
+	ErrDataCarrierTooLarge:  "ErrDataCarrierTooLarge",
+	ErrTooManySigOps:        "ErrTooManySigOps",
+	ErrTokensNotActive:      "ErrTokensNotActive",
+	ErrInvalidTokenData:     "ErrInvalidTokenData",
+	ErrTokenPrefix:          "ErrTokenPrefix",
+}
+
+// String returns the ErrorCode as a human-readable name.
+func (e ErrorCode) String() string {
+	if s := errorCodeStrings[e]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown ErrorCode (%d)", int(e))
+}
+
+// RuleError describes a single reason a transaction is not standard.  The
+// caller can use type assertions and the ErrorCode field to determine the
+// specific rule that was violated.
+type RuleError struct {
+	// ErrorCode identifies the rule that was violated.
+	ErrorCode ErrorCode
+
+	// Input is the index of the offending input, or -1 if the violation
+	// is not specific to an input.
+	Input int
+
+	// Output is the index of the offending output, or -1 if the violation
+	// is not specific to an output.
+	Output int
+
+	// Description is a human-readable explanation of the violation.
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e RuleError) Error() string {
+	return e.Description
+}
+
+// txRuleError creates a RuleError that applies to the transaction as a
+// whole.
+func txRuleError(c ErrorCode, desc string) RuleError {
+	return RuleError{ErrorCode: c, Input: -1, Output: -1, Description: desc}
+}
+
+// inputRuleError creates a RuleError that applies to the input at the given
+// index.
+func inputRuleError(c ErrorCode, idx int, desc string) RuleError {
+	return RuleError{ErrorCode: c, Input: idx, Output: -1,
+		Description: fmt.Sprintf("transaction input %d: %s", idx, desc)}
+}
+
+// outputRuleError creates a RuleError that applies to the output at the given
+// index.
+func outputRuleError(c ErrorCode, idx int, desc string) RuleError {
+	return RuleError{ErrorCode: c, Input: -1, Output: idx,
+		Description: fmt.Sprintf("transaction output %d: %s", idx, desc)}
+}
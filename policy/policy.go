@@ -0,0 +1,326 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"fmt"
+
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+)
+
+const (
+	// DefaultMinRelayTxFee is the minimum fee in satoshi per 1000 bytes
+	// that nodes require to relay a transaction.  It is also used to
+	// determine whether an output is considered dust.
+	DefaultMinRelayTxFee = bchutil.Amount(1000)
+
+	// DefaultMaxTxVersion is the highest transaction version considered
+	// standard.
+	DefaultMaxTxVersion = 2
+
+	// MinTxSize is the minimum size of a serialized transaction allowed on
+	// the network.
+	MinTxSize = 65
+
+	// MaxStandardTxSize is the maximum size of a serialized transaction
+	// that is considered standard.
+	MaxStandardTxSize = 100000
+
+	// MaxStandardMultiSigKeys is the maximum number of public keys allowed
+	// in a bare multi-signature output script for it to be considered
+	// standard.
+	MaxStandardMultiSigKeys = 3
+
+	// MaxStandardTxSigOps is the maximum number of legacy signature
+	// operations allowed in a standard transaction.
+	MaxStandardTxSigOps = 4000
+
+	// MaxStandardNonStandardScriptSize is the maximum size of an output
+	// script that does not match any template but is still relayed once
+	// AllowShortNonStandardScripts is set.
+	MaxStandardNonStandardScriptSize = 201
+
+	// dustInputSize is the size of a typical input spending a
+	// pay-to-pubkey-hash output with a compressed public key: 36 bytes of
+	// previous outpoint, 1 byte script length, 107 bytes of signature
+	// script and 4 bytes of sequence.
+	dustInputSize = 41 + 107
+)
+
+// Policy defines the limits applied by CheckTransactionStandard.  The zero
+// value is not useful; start from DefaultPolicy and adjust the fields that
+// differ from the network the transaction is destined for.
+type Policy struct {
+	// MinRelayTxFee is the fee rate, in satoshi per 1000 bytes, used to
+	// decide whether an output is dust.
+	MinRelayTxFee bchutil.Amount
+
+	// MaxTxVersion is the highest standard transaction version.
+	MaxTxVersion int32
+
+	// MaxTxSize is the maximum standard serialized transaction size.
+	MaxTxSize int
+
+	// MaxSigScriptSize is the maximum standard signature script size.
+	// Since the May 2026 upgrade it equals the consensus limit of
+	// txscript.MaxScriptSize, while nodes enforced 1650 bytes before.
+	MaxSigScriptSize int
+
+	// MaxDataCarrierSize is the maximum combined size of all OP_RETURN
+	// output scripts.
+	MaxDataCarrierSize int
+
+	// MaxMultiSigKeys is the maximum number of keys in a standard bare
+	// multi-signature output.
+	MaxMultiSigKeys int
+
+	// MaxSigOps is the maximum number of legacy signature operations in
+	// the transaction.
+	MaxSigOps int
+
+	// TokensActive specifies whether outputs may carry CashToken data.
+	TokensActive bool
+
+	// AllowShortNonStandardScripts specifies whether output scripts that
+	// do not match a standard template are accepted as long as they are
+	// no larger than MaxStandardNonStandardScriptSize, as is the case
+	// after the May 2026 upgrade.
+	AllowShortNonStandardScripts bool
+}
+
+// DefaultPolicy returns the standardness policy currently enforced by nodes
+// on the main network.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinRelayTxFee:                DefaultMinRelayTxFee,
+		MaxTxVersion:                 DefaultMaxTxVersion,
+		MaxTxSize:                    MaxStandardTxSize,
+		MaxSigScriptSize:             txscript.MaxScriptSize,
+		MaxDataCarrierSize:           txscript.MaxDataCarrierSize,
+		MaxMultiSigKeys:              MaxStandardMultiSigKeys,
+		MaxSigOps:                    MaxStandardTxSigOps,
+		TokensActive:                 true,
+		AllowShortNonStandardScripts: true,
+	}
+}
+
+// DustThreshold returns the smallest value the passed output may carry
+// without being considered dust at the given relay fee rate, which is in
+// satoshi per 1000 bytes.
+//
+// Dust is defined in terms of the cost to the network of spending the output:
+// an output is dust if spending it costs more than a third of its value at the
+// minimum relay fee.  The size of the spending input is assumed to be that of a
+// typical pay-to-pubkey-hash input, which for the default relay fee yields the
+// familiar threshold of 546 satoshi for a pay-to-pubkey-hash output.
+func DustThreshold(txOut *wire.TxOut, minRelayTxFee bchutil.Amount) bchutil.Amount {
+	totalSize := int64(txOut.SerializeSize() + dustInputSize)
+	cost := 3 * totalSize * int64(minRelayTxFee)
+	return bchutil.Amount((cost + 999) / 1000)
+}
+
+// IsDust returns whether or not the passed output is considered dust at the
+// given relay fee rate, which is in satoshi per 1000 bytes.  See
+// DustThreshold for the definition of dust.
+func IsDust(txOut *wire.TxOut, minRelayTxFee bchutil.Amount) bool {
+	return bchutil.Amount(txOut.Value) < DustThreshold(txOut, minRelayTxFee)
+}
+
+// CheckTransactionStandard checks the passed transaction against the
+// standardness rules described by p and returns every violation found.  A nil
+// return value means the transaction is standard.
+//
+// The checks cover the transaction version, serialized size limits, signature
+// script size and push-only requirements, output script templates, dust,
+// OP_RETURN data carrier size, legacy signature operation counts and the
+// CashToken prefix rules.  Checks which require the outputs being spent, such
+// as the standardness of the spent scripts or precise pay-to-script-hash
+// signature operation counting, are not performed.
+func CheckTransactionStandard(tx *bchutil.Tx, p *Policy) []RuleError {
+	var violations []RuleError
+	msgTx := tx.MsgTx()
+
+	// The transaction must be a currently supported version.
+	if msgTx.Version > p.MaxTxVersion || msgTx.Version < 1 {
+		str := fmt.Sprintf("transaction version %d is not in the "+
+			"valid range of %d-%d", msgTx.Version, 1, p.MaxTxVersion)
+		violations = append(violations, txRuleError(ErrTxVersion, str))
+	}
+
+	// Limit the serialized size of the transaction in both directions.
+	txSize := msgTx.SerializeSize()
+	if txSize < MinTxSize {
+		str := fmt.Sprintf("size of transaction %d is smaller than "+
+			"min allowed size of %d", txSize, MinTxSize)
+		violations = append(violations, txRuleError(ErrTxTooSmall, str))
+	}
+	if txSize > p.MaxTxSize {
+		str := fmt.Sprintf("size of transaction %d is larger than max "+
+			"allowed size of %d", txSize, p.MaxTxSize)
+		violations = append(violations, txRuleError(ErrTxTooLarge, str))
+	}
+
+	numSigOps := 0
+	for i, txIn := range msgTx.TxIn {
+		sigScriptLen := len(txIn.SignatureScript)
+		if sigScriptLen > p.MaxSigScriptSize {
+			str := fmt.Sprintf("signature script size of %d bytes "+
+				"is larger than max allowed size of %d bytes",
+				sigScriptLen, p.MaxSigScriptSize)
+			violations = append(violations,
+				inputRuleError(ErrSigScriptTooLarge, i, str))
+		}
+
+		if !txscript.IsPushOnlyScript(txIn.SignatureScript) {
+			violations = append(violations,
+				inputRuleError(ErrSigScriptNotPushOnly, i,
+					"signature script is not push only"))
+		}
+
+		numSigOps += GetSigOpCount(txIn.SignatureScript)
+	}
+
+	dataCarrierSize := 0
+	for i, txOut := range msgTx.TxOut {
+		numSigOps += GetSigOpCount(txOut.PkScript)
+
+		if err := checkTokenData(txOut, p); err != nil {
+			violations = append(violations, outputRuleError(
+				err.ErrorCode, i, err.Description))
+		}
+
+		scriptClass := txscript.GetScriptClass(txOut.PkScript)
+		if err := checkPkScriptStandard(txOut.PkScript, scriptClass, p); err != nil {
+			violations = append(violations, outputRuleError(
+				err.ErrorCode, i, err.Description))
+		}
+
+		// Accumulate the size of data carrier outputs and ensure the
+		// value of all others is not dust.
+		switch {
+		case scriptClass == txscript.NullDataTy:
+			dataCarrierSize += len(txOut.PkScript)
+
+		case txscript.IsUnspendable(txOut.PkScript):
+			violations = append(violations, outputRuleError(
+				ErrUnspendable, i, "output script is unspendable"))
+
+		case IsDust(txOut, p.MinRelayTxFee):
+			str := fmt.Sprintf("payment of %d is dust, minimum is %d",
+				txOut.Value, DustThreshold(txOut, p.MinRelayTxFee))
+			violations = append(violations,
+				outputRuleError(ErrDust, i, str))
+		}
+	}
+
+	if dataCarrierSize > p.MaxDataCarrierSize {
+		str := fmt.Sprintf("transaction nulldata of %d bytes exceeds "+
+			"%d bytes", dataCarrierSize, p.MaxDataCarrierSize)
+		violations = append(violations,
+			txRuleError(ErrDataCarrierTooLarge, str))
+	}
+
+	if numSigOps > p.MaxSigOps {
+		str := fmt.Sprintf("transaction has %d signature operations "+
+			"which is more than the allowed max of %d", numSigOps,
+			p.MaxSigOps)
+		violations = append(violations, txRuleError(ErrTooManySigOps, str))
+	}
+
+	return violations
+}
+
+// checkPkScriptStandard ensures the output script matches a standard
+// template.  For bare multi-signature scripts, only scripts with between 1 and
+// MaxMultiSigKeys public keys and a sensible number of required signatures are
+// standard.
+func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass, p *Policy) *RuleError {
+	switch scriptClass {
+	case txscript.MultiSigTy:
+		numPubKeys, numSigs, err := txscript.CalcMultiSigStats(pkScript)
+		if err != nil {
+			str := fmt.Sprintf("multi-signature script parse "+
+				"failure: %v", err)
+			return &RuleError{ErrorCode: ErrBareMultiSig, Description: str}
+		}
+		if numPubKeys < 1 || numPubKeys > p.MaxMultiSigKeys {
+			str := fmt.Sprintf("multi-signature script with %d "+
+				"public keys is outside the allowed range of "+
+				"1-%d", numPubKeys, p.MaxMultiSigKeys)
+			return &RuleError{ErrorCode: ErrBareMultiSig, Description: str}
+		}
+		if numSigs < 1 || numSigs > numPubKeys {
+			str := fmt.Sprintf("multi-signature script requires "+
+				"%d of %d signatures", numSigs, numPubKeys)
+			return &RuleError{ErrorCode: ErrBareMultiSig, Description: str}
+		}
+
+	case txscript.NonStandardTy:
+		if !p.AllowShortNonStandardScripts ||
+			len(pkScript) > MaxStandardNonStandardScriptSize {
+
+			return &RuleError{ErrorCode: ErrNonStandardScript,
+				Description: "non-standard script form"}
+		}
+	}
+
+	return nil
+}
+
+// checkTokenData ensures any token data carried by the output is allowed by
+// the policy and well formed, and that a script without token data cannot be
+// mistaken for token data on the wire.
+func checkTokenData(txOut *wire.TxOut, p *Policy) *RuleError {
+	tokenData := &txOut.TokenData
+	if tokenData.IsEmpty() {
+		if len(txOut.PkScript) > 0 && txOut.PkScript[0] == wire.PREFIX_BYTE {
+			return &RuleError{ErrorCode: ErrTokenPrefix,
+				Description: "output script begins with the " +
+					"token prefix byte"}
+		}
+		return nil
+	}
+
+	if !p.TokensActive {
+		return &RuleError{ErrorCode: ErrTokensNotActive,
+			Description: "output carries token data"}
+	}
+
+	if !tokenData.IsValidBitfield() {
+		str := fmt.Sprintf("invalid token bitfield %#02x",
+			tokenData.BitField)
+		return &RuleError{ErrorCode: ErrInvalidTokenData, Description: str}
+	}
+	if tokenData.HasCommitmentLength() {
+		commitmentLen := len(tokenData.Commitment)
+		if commitmentLen < 1 || commitmentLen > wire.MAX_COMMITMENT_LENGTH {
+			str := fmt.Sprintf("token commitment length %d is "+
+				"outside the allowed range of 1-%d", commitmentLen,
+				wire.MAX_COMMITMENT_LENGTH)
+			return &RuleError{ErrorCode: ErrInvalidTokenData, Description: str}
+		}
+	} else if len(tokenData.Commitment) > 0 {
+		return &RuleError{ErrorCode: ErrInvalidTokenData,
+			Description: "token commitment present without the " +
+				"commitment bit set"}
+	}
+	if tokenData.HasAmount() {
+		if tokenData.Amount < 1 || tokenData.Amount > wire.MAX_FT_AMOUNT {
+			str := fmt.Sprintf("token amount %d is outside the "+
+				"allowed range of 1-%d", tokenData.Amount,
+				uint64(wire.MAX_FT_AMOUNT))
+			return &RuleError{ErrorCode: ErrInvalidTokenData, Description: str}
+		}
+	} else if tokenData.Amount != 0 {
+		return &RuleError{ErrorCode: ErrInvalidTokenData,
+			Description: "token amount present without the amount " +
+				"bit set"}
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/policy"
+)
+
+// p2pkhScript is a pay-to-pubkey-hash output script used throughout the
+// tests.
+var p2pkhScript = []byte{
+	txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20,
+	0x06, 0x6e, 0xbe, 0xe5, 0x90, 0x27, 0x8f, 0x32, 0xae, 0xdc,
+	0x8a, 0x48, 0x65, 0x70, 0x0c, 0x49, 0xe7, 0x17, 0xf1, 0xd7,
+	txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG,
+}
+
+// newStandardTx returns a transaction spending a single input to a single
+// pay-to-pubkey-hash output which passes all of the default checks.
+func newStandardTx() *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	sigScript := append([]byte{txscript.OP_DATA_72}, bytes.Repeat([]byte{0x30}, 72)...)
+	sigScript = append(sigScript, txscript.OP_DATA_33)
+	sigScript = append(sigScript, bytes.Repeat([]byte{0x02}, 33)...)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 0},
+		SignatureScript:  sigScript,
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(wire.NewTxOut(100000, p2pkhScript, wire.TokenData{}))
+	return tx
+}
+
+// TestDustThreshold ensures the dust threshold and IsDust agree with the
+// values enforced by nodes.
+func TestDustThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		txOut     wire.TxOut
+		relayFee  bchutil.Amount
+		threshold bchutil.Amount
+	}{
+		{
+			name:      "p2pkh at default relay fee",
+			txOut:     wire.TxOut{PkScript: p2pkhScript},
+			relayFee:  policy.DefaultMinRelayTxFee,
+			threshold: 546,
+		},
+		{
+			name:      "p2pkh at zero relay fee",
+			txOut:     wire.TxOut{PkScript: p2pkhScript},
+			relayFee:  0,
+			threshold: 0,
+		},
+		{
+			name:      "p2pkh at 5000 sat/kB",
+			txOut:     wire.TxOut{PkScript: p2pkhScript},
+			relayFee:  5000,
+			threshold: 2730,
+		},
+		{
+			name:      "empty script at default relay fee",
+			txOut:     wire.TxOut{},
+			relayFee:  policy.DefaultMinRelayTxFee,
+			threshold: 471,
+		},
+	}
+
+	for _, test := range tests {
+		got := policy.DustThreshold(&test.txOut, test.relayFee)
+		if got != test.threshold {
+			t.Errorf("%s: unexpected threshold - got %d, want %d",
+				test.name, got, test.threshold)
+			continue
+		}
+
+		test.txOut.Value = int64(test.threshold)
+		if policy.IsDust(&test.txOut, test.relayFee) {
+			t.Errorf("%s: value at threshold considered dust",
+				test.name)
+		}
+		if test.threshold > 0 {
+			test.txOut.Value--
+			if !policy.IsDust(&test.txOut, test.relayFee) {
+				t.Errorf("%s: value below threshold not "+
+					"considered dust", test.name)
+			}
+		}
+	}
+}
+
+// TestGetSigOpCount ensures signature operations are counted as expected.
+func TestGetSigOpCount(t *testing.T) {
+	multiSig := []byte{txscript.OP_2, txscript.OP_DATA_1, 0x01,
+		txscript.OP_DATA_1, 0x02, txscript.OP_2, txscript.OP_CHECKMULTISIG}
+
+	tests := []struct {
+		name    string
+		script  []byte
+		legacy  int
+		precise int
+	}{
+		{"p2pkh", p2pkhScript, 1, 1},
+		{"bare multisig", multiSig, 20, 2},
+		{"checksig in push data", []byte{txscript.OP_DATA_1,
+			txscript.OP_CHECKSIG}, 0, 0},
+		{"checkdatasig", []byte{txscript.OP_CHECKDATASIGVERIFY,
+			txscript.OP_CHECKDATASIG}, 2, 2},
+		{"stops at malformed push", []byte{txscript.OP_CHECKSIG,
+			txscript.OP_PUSHDATA2, 0xff}, 1, 1},
+	}
+
+	for _, test := range tests {
+		if got := policy.GetSigOpCount(test.script); got != test.legacy {
+			t.Errorf("%s: unexpected legacy count - got %d, want %d",
+				test.name, got, test.legacy)
+		}
+		if got := policy.GetPreciseSigOpCount(test.script); got != test.precise {
+			t.Errorf("%s: unexpected precise count - got %d, want %d",
+				test.name, got, test.precise)
+		}
+	}
+}
+
+// TestCheckTransactionStandard ensures the standardness checks report the
+// expected violations.
+func TestCheckTransactionStandard(t *testing.T) {
+	type violation struct {
+		code   policy.ErrorCode
+		input  int
+		output int
+	}
+
+	tests := []struct {
+		name   string
+		modify func(tx *wire.MsgTx, p *policy.Policy)
+		want   []violation
+	}{
+		{
+			name:   "standard p2pkh",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {},
+		},
+		{
+			name: "unsupported version",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.Version = 3
+			},
+			want: []violation{{policy.ErrTxVersion, -1, -1}},
+		},
+		{
+			name: "too small",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxIn[0].SignatureScript = nil
+				tx.TxOut[0].PkScript = []byte{txscript.OP_TRUE}
+			},
+			want: []violation{{policy.ErrTxTooSmall, -1, -1}},
+		},
+		{
+			name: "too large",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				p.MaxTxSize = 100
+			},
+			want: []violation{{policy.ErrTxTooLarge, -1, -1}},
+		},
+		{
+			name: "signature script not push only",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxIn[0].SignatureScript = append(
+					tx.TxIn[0].SignatureScript, txscript.OP_DUP)
+			},
+			want: []violation{{policy.ErrSigScriptNotPushOnly, 0, -1}},
+		},
+		{
+			name: "signature script too large",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				p.MaxSigScriptSize = 50
+			},
+			want: []violation{{policy.ErrSigScriptTooLarge, 0, -1}},
+		},
+		{
+			name: "dust output",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.AddTxOut(wire.NewTxOut(545, p2pkhScript,
+					wire.TokenData{}))
+			},
+			want: []violation{{policy.ErrDust, -1, 1}},
+		},
+		{
+			name: "long non-standard script",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxOut[0].PkScript = bytes.Repeat(
+					[]byte{txscript.OP_NOP}, 202)
+			},
+			want: []violation{{policy.ErrNonStandardScript, -1, 0}},
+		},
+		{
+			name: "short non-standard script before upgrade",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxOut[0].PkScript = []byte{txscript.OP_TRUE}
+				p.AllowShortNonStandardScripts = false
+			},
+			want: []violation{{policy.ErrNonStandardScript, -1, 0}},
+		},
+		{
+			name: "bare multisig with too many keys",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				script := []byte{txscript.OP_1}
+				for i := 0; i < 4; i++ {
+					script = append(script, txscript.OP_DATA_33)
+					script = append(script, bytes.Repeat(
+						[]byte{0x02}, 33)...)
+				}
+				script = append(script, txscript.OP_4,
+					txscript.OP_CHECKMULTISIG)
+				tx.TxOut[0].PkScript = script
+			},
+			want: []violation{{policy.ErrBareMultiSig, -1, 0}},
+		},
+		{
+			name: "oversized data carrier",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				for i := 0; i < 2; i++ {
+					script, _ := txscript.NullDataScript(
+						bytes.Repeat([]byte{0x01}, 200))
+					tx.AddTxOut(wire.NewTxOut(0, script,
+						wire.TokenData{}))
+				}
+			},
+			want: []violation{{policy.ErrDataCarrierTooLarge, -1, -1}},
+		},
+		{
+			name: "too many sigops",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				p.MaxSigOps = 0
+			},
+			want: []violation{{policy.ErrTooManySigOps, -1, -1}},
+		},
+		{
+			name: "tokens not active",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxOut[0].TokenData = wire.TokenData{
+					CategoryID: [32]byte{0x01},
+					Amount:     10,
+					BitField:   wire.HAS_AMOUNT,
+				}
+				p.TokensActive = false
+			},
+			want: []violation{{policy.ErrTokensNotActive, -1, 0}},
+		},
+		{
+			name: "invalid token bitfield",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxOut[0].TokenData = wire.TokenData{
+					CategoryID: [32]byte{0x01},
+					BitField:   wire.RESERVED_BIT,
+				}
+			},
+			want: []violation{{policy.ErrInvalidTokenData, -1, 0}},
+		},
+		{
+			name: "valid fungible token",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxOut[0].TokenData = wire.TokenData{
+					CategoryID: [32]byte{0x01},
+					Amount:     10,
+					BitField:   wire.HAS_AMOUNT,
+				}
+			},
+		},
+		{
+			name: "script with token prefix",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.TxOut[0].PkScript = append([]byte{wire.PREFIX_BYTE},
+					p2pkhScript...)
+			},
+			want: []violation{{policy.ErrTokenPrefix, -1, 0}},
+		},
+		{
+			name: "multiple violations",
+			modify: func(tx *wire.MsgTx, p *policy.Policy) {
+				tx.Version = 0
+				tx.TxOut[0].Value = 1
+			},
+			want: []violation{
+				{policy.ErrTxVersion, -1, -1},
+				{policy.ErrDust, -1, 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		tx := newStandardTx()
+		p := policy.DefaultPolicy()
+		test.modify(tx, p)
+
+		got := policy.CheckTransactionStandard(bchutil.NewTx(tx), p)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: unexpected number of violations - got %v, "+
+				"want %d", test.name, got, len(test.want))
+			continue
+		}
+		for i, v := range got {
+			want := test.want[i]
+			if v.ErrorCode != want.code || v.Input != want.input ||
+				v.Output != want.output {
+
+				t.Errorf("%s: unexpected violation #%d - got "+
+					"%v (input %d, output %d), want %v (input "+
+					"%d, output %d)", test.name, i, v.ErrorCode,
+					v.Input, v.Output, want.code, want.input,
+					want.output)
+			}
+		}
+	}
+}
+
+// TestErrorCodeStringer tests the stringized output for the ErrorCode type.
+func TestErrorCodeStringer(t *testing.T) {
+	tests := []struct {
+		in   policy.ErrorCode
+		want string
+	}{
+		{policy.ErrTxVersion, "ErrTxVersion"},
+		{policy.ErrDust, "ErrDust"},
+		{policy.ErrTokenPrefix, "ErrTokenPrefix"},
+		{0xffff, "Unknown ErrorCode (65535)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"encoding/binary"
+
+	"github.com/gcash/bchd/txscript"
+)
+
+// maxPubKeysPerMultiSig is the number of signature operations charged for an
+// OP_CHECKMULTISIG when the number of public keys cannot be determined from
+// the preceding opcode.
+const maxPubKeysPerMultiSig = 20
+
+// GetSigOpCount returns the number of signature operations in the passed
+// script using the legacy, imprecise counting rules.  Each OP_CHECKSIG,
+// OP_CHECKSIGVERIFY, OP_CHECKDATASIG and OP_CHECKDATASIGVERIFY counts as one
+// operation and each OP_CHECKMULTISIG or OP_CHECKMULTISIGVERIFY counts as
+// twenty.  Counting stops at the first malformed push.
+func GetSigOpCount(script []byte) int {
+	return countSigOps(script, false)
+}
+
+// GetPreciseSigOpCount returns the number of signature operations in the
+// passed script.  It is the same as GetSigOpCount except a multi-signature
+// operation preceded by a small integer opcode is charged that number of
+// operations rather than the maximum.
+func GetPreciseSigOpCount(script []byte) int {
+	return countSigOps(script, true)
+}
+
+// countSigOps walks the opcodes of the script and tallies the signature
+// operations in it.
+func countSigOps(script []byte, precise bool) int {
+	numSigOps := 0
+	lastOp := byte(txscript.OP_INVALIDOPCODE)
+	for i := 0; i < len(script); {
+		op := script[i]
+		next, ok := skipOpcode(script, i)
+		if !ok {
+			break
+		}
+		i = next
+
+		switch op {
+		case txscript.OP_CHECKSIG, txscript.OP_CHECKSIGVERIFY,
+			txscript.OP_CHECKDATASIG, txscript.OP_CHECKDATASIGVERIFY:
+			numSigOps++
+
+		case txscript.OP_CHECKMULTISIG, txscript.OP_CHECKMULTISIGVERIFY:
+			if precise && lastOp >= txscript.OP_1 && lastOp <= txscript.OP_16 {
+				numSigOps += int(lastOp-txscript.OP_1) + 1
+			} else {
+				numSigOps += maxPubKeysPerMultiSig
+			}
+		}
+		lastOp = op
+	}
+	return numSigOps
+}
+
+// skipOpcode returns the offset of the opcode following the one at offset i
+// along with whether or not the opcode at i is well formed.
+func skipOpcode(script []byte, i int) (int, bool) {
+	op := script[i]
+	i++
+
+	var dataLen int
+	switch {
+	case op >= txscript.OP_DATA_1 && op <= txscript.OP_DATA_75:
+		dataLen = int(op)
+
+	case op == txscript.OP_PUSHDATA1:
+		if len(script)-i < 1 {
+			return 0, false
+		}
+		dataLen = int(script[i])
+		i++
+
+	case op == txscript.OP_PUSHDATA2:
+		if len(script)-i < 2 {
+			return 0, false
+		}
+		dataLen = int(binary.LittleEndian.Uint16(script[i:]))
+		i += 2
+
+	case op == txscript.OP_PUSHDATA4:
+		if len(script)-i < 4 {
+			return 0, false
+		}
+		dataLen = int(binary.LittleEndian.Uint32(script[i:]))
+		i += 4
+	}
+
+	if dataLen < 0 || len(script)-i < dataLen {
+		return 0, false
+	}
+	return i + dataLen, true
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"math/big"
+
+	"github.com/gcash/bchutil/header"
+)
+
+// diff1Bits is the compact target of a share of difficulty 1, the highest
+// target of the original bitcoin network.
+const diff1Bits = 0x1d00ffff
+
+// diff1Target is the target of a share of difficulty 1.
+var diff1Target = header.CompactToBig(diff1Bits)
+
+// DifficultyToTarget returns the share target for a stratum difficulty, as
+// set with mining.set_difficulty.  Difficulties below one give targets above
+// the difficulty 1 target, which is common on test networks.  It returns nil
+// for difficulties which are not positive.
+func DifficultyToTarget(difficulty float64) *big.Int {
+	if !(difficulty > 0) {
+		return nil
+	}
+	target := new(big.Float).SetInt(diff1Target)
+	target.Quo(target, big.NewFloat(difficulty))
+	n, _ := target.Int(nil)
+	return n
+}
+
+// TargetToDifficulty returns the stratum difficulty of a share target.
+func TargetToDifficulty(target *big.Int) float64 {
+	if target.Sign() <= 0 {
+		return 0
+	}
+	difficulty := new(big.Float).SetInt(diff1Target)
+	difficulty.Quo(difficulty, new(big.Float).SetInt(target))
+	f, _ := difficulty.Float64()
+	return f
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gcash/bchutil/stratum"
+)
+
+// TestDifficulty ensures stratum difficulties convert to and from share
+// targets.
+func TestDifficulty(t *testing.T) {
+	diff1, _ := new(big.Int).SetString("00000000ffff0000000000000000000"+
+		"000000000000000000000000000000000", 16)
+
+	tests := []struct {
+		difficulty float64
+		target     *big.Int
+	}{
+		{1, diff1},
+		{2, new(big.Int).Rsh(diff1, 1)},
+		{1024, new(big.Int).Rsh(diff1, 10)},
+		{0.5, new(big.Int).Lsh(diff1, 1)},
+	}
+
+	for i, test := range tests {
+		target := stratum.DifficultyToTarget(test.difficulty)
+		if target.Cmp(test.target) != 0 {
+			t.Errorf("DifficultyToTarget #%d: got %064x, want %064x",
+				i, target, test.target)
+		}
+		difficulty := stratum.TargetToDifficulty(test.target)
+		if difficulty != test.difficulty {
+			t.Errorf("TargetToDifficulty #%d: got %v, want %v", i,
+				difficulty, test.difficulty)
+		}
+	}
+
+	if target := stratum.DifficultyToTarget(0); target != nil {
+		t.Errorf("DifficultyToTarget: got %v for zero difficulty", target)
+	}
+	if difficulty := stratum.TargetToDifficulty(new(big.Int)); difficulty != 0 {
+		t.Errorf("TargetToDifficulty: got %v for zero target", difficulty)
+	}
+}
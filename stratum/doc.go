@@ -0,0 +1,44 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package stratum provides helpers for building Stratum v1 mining jobs from
+block templates and checking the shares miners submit for them.
+
+# Overview
+
+NewJob splits the serialized coinbase of a blocktemplate.Template around its
+extra nonce into the coinbase1 and coinbase2 fields of a job and computes the
+merkle branch of the coinbase.  NotifyParams encodes the job as the parameters
+of a mining.notify message, with the previous block hash, version, bits and
+time encoded the way miners expect:
+
+	job, err := stratum.NewJob(jobID, tmpl, true)
+	if err != nil {
+		return err
+	}
+	notify := job.NotifyParams()
+
+The extra nonce of the coinbase is made up of the extra nonce 1, which the pool
+assigns to each connection, followed by the extra nonce 2 chosen by the miner.
+Together they must fill blocktemplate.ExtraNonceSize bytes.
+
+Shares are parsed from the parameters of mining.submit messages with
+ParseSubmit, including the version bits sent by miners using version rolling
+(BIP310), which may change the bits of the job version under its VersionMask.
+CheckShare rebuilds the coinbase and header of a share and checks its hash
+against the share target, which DifficultyToTarget derives from the difficulty
+sent with mining.set_difficulty.  Shares which also meet the target of the
+block are turned into blocks with Block:
+
+	result, err := job.CheckShare(extraNonce1, share, shareTarget)
+	if err != nil {
+		return err
+	}
+	if result.IsBlock {
+		block, err := job.Block(result)
+		...
+	}
+*/
+package stratum
@@ -0,0 +1,466 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blocktemplate"
+	"github.com/gcash/bchutil/header"
+	"github.com/gcash/bchutil/merkle"
+)
+
+// VersionRollingMask is the mask of the general purpose block version bits of
+// BIP320, which miners using version rolling (BIP310) may change.
+const VersionRollingMask uint32 = 0x1fffe000
+
+var (
+	// ErrExtraNonceSize describes an error where the sizes of the extra
+	// nonces of a share do not add up to the extra nonce size of the job.
+	ErrExtraNonceSize = errors.New("extra nonce size mismatch")
+
+	// ErrJobMismatch describes an error where a share is checked against
+	// a job other than the one it was submitted for.
+	ErrJobMismatch = errors.New("share job id does not match job")
+
+	// ErrHighHash describes an error where the hash of a share is above
+	// the share target.
+	ErrHighHash = errors.New("share hash is above the target")
+
+	// ErrBadParams describes an error where the parameters of a stratum
+	// message are malformed.
+	ErrBadParams = errors.New("malformed stratum parameters")
+
+	// ErrVersionBits describes an error where a share rolls block version
+	// bits outside the version rolling mask of the job.
+	ErrVersionBits = errors.New("share version bits outside the mask")
+)
+
+// Job is a stratum mining job, as sent to miners with mining.notify.  Miners
+// build the coinbase from Coinbase1, the extra nonces and Coinbase2, hash it
+// up the merkle branch to the merkle root and grind the nonce of the header.
+type Job struct {
+	// ID identifies the job.
+	ID string
+
+	// PrevBlock is the hash of the parent block.
+	PrevBlock chainhash.Hash
+
+	// Coinbase1 is the serialized coinbase preceding the extra nonce.
+	Coinbase1 []byte
+
+	// Coinbase2 is the serialized coinbase following the extra nonce.
+	Coinbase2 []byte
+
+	// MerkleBranch is the merkle branch of the coinbase.
+	MerkleBranch []chainhash.Hash
+
+	// Version is the block version.
+	Version int32
+
+	// Bits is the compact target difficulty of the block.
+	Bits uint32
+
+	// Time is the block timestamp.  Miners may roll it forward.
+	Time uint32
+
+	// CleanJobs signals miners to drop the jobs sent earlier.
+	CleanJobs bool
+
+	// VersionMask is the mask of the version bits that shares of miners
+	// using version rolling (BIP310) may change, as negotiated with
+	// mining.configure.  It is not part of mining.notify.  Shares with
+	// version bits outside of it are rejected.
+	VersionMask uint32
+
+	// tmpl is the template the job was built from, if any.
+	tmpl *blocktemplate.Template
+}
+
+// NewJob returns the stratum job with the passed id for a block template.  The
+// version mask of the job is VersionRollingMask.
+func NewJob(id string, tmpl *blocktemplate.Template, cleanJobs bool) (*Job, error) {
+	var buf bytes.Buffer
+	buf.Grow(tmpl.Coinbase.SerializeSize())
+	if err := tmpl.Coinbase.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	coinbase := buf.Bytes()
+	offset := tmpl.ExtraNonceOffset()
+
+	branch, err := merkle.Branch(tmpl.TxHashes(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		ID:           id,
+		PrevBlock:    tmpl.Header.PrevBlock,
+		Coinbase1:    coinbase[:offset],
+		Coinbase2:    coinbase[offset+blocktemplate.ExtraNonceSize:],
+		MerkleBranch: branch,
+		Version:      tmpl.Header.Version,
+		Bits:         tmpl.Header.Bits,
+		Time:         uint32(tmpl.Header.Timestamp.Unix()),
+		CleanJobs:    cleanJobs,
+		VersionMask:  VersionRollingMask,
+		tmpl:         tmpl,
+	}, nil
+}
+
+// NotifyParams returns the parameters of the mining.notify message for the
+// job.  Hashes and numbers are hex encoded as expected by stratum miners.
+func (j *Job) NotifyParams() []interface{} {
+	branch := make([]string, len(j.MerkleBranch))
+	for i := range j.MerkleBranch {
+		branch[i] = hex.EncodeToString(j.MerkleBranch[i][:])
+	}
+	return []interface{}{
+		j.ID,
+		encodePrevHash(&j.PrevBlock),
+		hex.EncodeToString(j.Coinbase1),
+		hex.EncodeToString(j.Coinbase2),
+		branch,
+		encodeUint32(uint32(j.Version)),
+		encodeUint32(j.Bits),
+		encodeUint32(j.Time),
+		j.CleanJobs,
+	}
+}
+
+// ParseNotify returns the job described by the parameters of a
+// mining.notify message.
+func ParseNotify(params []interface{}) (*Job, error) {
+	if len(params) != 9 {
+		return nil, fmt.Errorf("%v: mining.notify takes 9 parameters, "+
+			"got %d", ErrBadParams, len(params))
+	}
+	strs := make([]string, 8)
+	for _, i := range []int{0, 1, 2, 3, 5, 6, 7} {
+		s, ok := params[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("%v: parameter %d is not a string",
+				ErrBadParams, i)
+		}
+		strs[i] = s
+	}
+	rawBranch, ok := params[4].([]interface{})
+	if !ok {
+		if s, isStrings := params[4].([]string); isStrings {
+			for _, h := range s {
+				rawBranch = append(rawBranch, h)
+			}
+		} else {
+			return nil, fmt.Errorf("%v: parameter 4 is not a list",
+				ErrBadParams)
+		}
+	}
+	cleanJobs, ok := params[8].(bool)
+	if !ok {
+		return nil, fmt.Errorf("%v: parameter 8 is not a bool",
+			ErrBadParams)
+	}
+
+	j := &Job{ID: strs[0], CleanJobs: cleanJobs}
+	prevHash, err := decodePrevHash(strs[1])
+	if err != nil {
+		return nil, err
+	}
+	j.PrevBlock = *prevHash
+	if j.Coinbase1, err = decodeHex(strs[2]); err != nil {
+		return nil, err
+	}
+	if j.Coinbase2, err = decodeHex(strs[3]); err != nil {
+		return nil, err
+	}
+	for _, raw := range rawBranch {
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v: merkle branch entry is not "+
+				"a string", ErrBadParams)
+		}
+		b, err := decodeHex(s)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := chainhash.NewHash(b)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrBadParams, err)
+		}
+		j.MerkleBranch = append(j.MerkleBranch, *hash)
+	}
+	version, err := decodeUint32(strs[5])
+	if err != nil {
+		return nil, err
+	}
+	j.Version = int32(version)
+	if j.Bits, err = decodeUint32(strs[6]); err != nil {
+		return nil, err
+	}
+	if j.Time, err = decodeUint32(strs[7]); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Coinbase returns the serialized coinbase built from the extra nonces.
+func (j *Job) Coinbase(extraNonce1, extraNonce2 []byte) []byte {
+	coinbase := make([]byte, 0, len(j.Coinbase1)+len(extraNonce1)+
+		len(extraNonce2)+len(j.Coinbase2))
+	coinbase = append(coinbase, j.Coinbase1...)
+	coinbase = append(coinbase, extraNonce1...)
+	coinbase = append(coinbase, extraNonce2...)
+	return append(coinbase, j.Coinbase2...)
+}
+
+// Header returns the block header built from the extra nonces, timestamp and
+// nonce of a share.
+func (j *Job) Header(extraNonce1, extraNonce2 []byte, nTime, nonce uint32) *wire.BlockHeader {
+	coinbaseHash := chainhash.DoubleHashH(j.Coinbase(extraNonce1,
+		extraNonce2))
+	return &wire.BlockHeader{
+		Version:    j.Version,
+		PrevBlock:  j.PrevBlock,
+		MerkleRoot: merkle.BranchRoot(coinbaseHash, 0, j.MerkleBranch),
+		Timestamp:  time.Unix(int64(nTime), 0),
+		Bits:       j.Bits,
+		Nonce:      nonce,
+	}
+}
+
+// Share is a share submitted by a miner with mining.submit.
+type Share struct {
+	// Worker is the name of the worker which found the share.
+	Worker string
+
+	// JobID identifies the job the share was found for.
+	JobID string
+
+	// ExtraNonce2 is the extra nonce chosen by the miner.
+	ExtraNonce2 []byte
+
+	// Time is the block timestamp used by the miner.
+	Time uint32
+
+	// Nonce is the header nonce.
+	Nonce uint32
+
+	// VersionBits are the block version bits chosen by a miner using
+	// version rolling (BIP310), which are sent as an optional sixth
+	// parameter.  It is nil when the miner does not roll the version.
+	VersionBits *uint32
+}
+
+// ParseSubmit returns the share described by the parameters of a
+// mining.submit message.  The version bits parameter of miners using version
+// rolling (BIP310) is optional.
+func ParseSubmit(params []interface{}) (*Share, error) {
+	if len(params) != 5 && len(params) != 6 {
+		return nil, fmt.Errorf("%v: mining.submit takes 5 or 6 "+
+			"parameters, got %d", ErrBadParams, len(params))
+	}
+	strs := make([]string, len(params))
+	for i := range params {
+		s, ok := params[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("%v: parameter %d is not a string",
+				ErrBadParams, i)
+		}
+		strs[i] = s
+	}
+
+	extraNonce2, err := decodeHex(strs[2])
+	if err != nil {
+		return nil, err
+	}
+	nTime, err := decodeUint32(strs[3])
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := decodeUint32(strs[4])
+	if err != nil {
+		return nil, err
+	}
+	share := &Share{
+		Worker:      strs[0],
+		JobID:       strs[1],
+		ExtraNonce2: extraNonce2,
+		Time:        nTime,
+		Nonce:       nonce,
+	}
+	if len(strs) == 6 {
+		versionBits, err := decodeUint32(strs[5])
+		if err != nil {
+			return nil, err
+		}
+		share.VersionBits = &versionBits
+	}
+	return share, nil
+}
+
+// SubmitParams returns the parameters of the mining.submit message for the
+// share.  The version bits are only included when they are set.
+func (s *Share) SubmitParams() []interface{} {
+	params := []interface{}{
+		s.Worker,
+		s.JobID,
+		hex.EncodeToString(s.ExtraNonce2),
+		encodeUint32(s.Time),
+		encodeUint32(s.Nonce),
+	}
+	if s.VersionBits != nil {
+		params = append(params, encodeUint32(*s.VersionBits))
+	}
+	return params
+}
+
+// ShareResult is the outcome of checking a share which meets its target.
+type ShareResult struct {
+	// Header is the block header rebuilt from the share.
+	Header *wire.BlockHeader
+
+	// Hash is the hash of the header.
+	Hash chainhash.Hash
+
+	// Coinbase is the serialized coinbase rebuilt from the share.
+	Coinbase []byte
+
+	// IsBlock is whether the share also meets the target difficulty of
+	// the block and so solves it.
+	IsBlock bool
+}
+
+// CheckShare rebuilds the header of a share submitted by the connection with
+// the passed extra nonce and checks its hash against the share target.  An
+// error wrapping ErrHighHash is returned when the hash is above the target.
+// For jobs created with NewJob, the extra nonces must add up to
+// blocktemplate.ExtraNonceSize bytes.  The version bits of a share, if any,
+// must be within the version mask of the job.
+func (j *Job) CheckShare(extraNonce1 []byte, share *Share,
+	shareTarget *big.Int) (*ShareResult, error) {
+
+	if share.JobID != j.ID {
+		return nil, fmt.Errorf("%v: got %q, want %q", ErrJobMismatch,
+			share.JobID, j.ID)
+	}
+	if j.tmpl != nil && len(extraNonce1)+len(share.ExtraNonce2) !=
+		blocktemplate.ExtraNonceSize {
+
+		return nil, fmt.Errorf("%v: got %d bytes, want %d",
+			ErrExtraNonceSize, len(extraNonce1)+
+				len(share.ExtraNonce2), blocktemplate.ExtraNonceSize)
+	}
+
+	hdr := j.Header(extraNonce1, share.ExtraNonce2, share.Time, share.Nonce)
+	if share.VersionBits != nil {
+		// The version bits replace the bits of the job version under
+		// the mask, as described by BIP310.
+		if *share.VersionBits&^j.VersionMask != 0 {
+			return nil, fmt.Errorf("%v: version bits %08x, mask %08x",
+				ErrVersionBits, *share.VersionBits, j.VersionMask)
+		}
+		hdr.Version = int32(uint32(j.Version)&^j.VersionMask |
+			*share.VersionBits)
+	}
+	hash := hdr.BlockHash()
+	hashNum := header.HashToBig(&hash)
+	if hashNum.Cmp(shareTarget) > 0 {
+		return nil, fmt.Errorf("%v: hash %v, target %064x", ErrHighHash,
+			hash, shareTarget)
+	}
+	return &ShareResult{
+		Header:   hdr,
+		Hash:     hash,
+		Coinbase: j.Coinbase(extraNonce1, share.ExtraNonce2),
+		IsBlock:  hashNum.Cmp(header.CompactToBig(j.Bits)) <= 0,
+	}, nil
+}
+
+// Block returns the block solved by a share.  The job must have been created
+// with NewJob, which keeps the transactions of the template.
+func (j *Job) Block(result *ShareResult) (*bchutil.Block, error) {
+	if j.tmpl == nil {
+		return nil, errors.New("job has no block template")
+	}
+	var coinbase wire.MsgTx
+	if err := coinbase.Deserialize(bytes.NewReader(result.Coinbase)); err != nil {
+		return nil, err
+	}
+	msgBlock := wire.NewMsgBlock(result.Header)
+	msgBlock.AddTransaction(&coinbase)
+	for _, tx := range j.tmpl.Transactions {
+		msgBlock.AddTransaction(tx.MsgTx())
+	}
+	block := bchutil.NewBlock(msgBlock)
+	block.SetHeight(j.tmpl.Height)
+	return block, nil
+}
+
+// encodePrevHash returns the stratum encoding of the hash of a parent block,
+// which swaps the byte order of each 32-bit word of the hash.
+func encodePrevHash(hash *chainhash.Hash) string {
+	var swapped chainhash.Hash
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		binary.BigEndian.PutUint32(swapped[i:],
+			binary.LittleEndian.Uint32(hash[i:]))
+	}
+	return hex.EncodeToString(swapped[:])
+}
+
+// decodePrevHash decodes the hash of a parent block from its stratum
+// encoding.
+func decodePrevHash(s string) (*chainhash.Hash, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != chainhash.HashSize {
+		return nil, fmt.Errorf("%v: previous block hash is %d bytes",
+			ErrBadParams, len(b))
+	}
+	var hash chainhash.Hash
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		binary.LittleEndian.PutUint32(hash[i:],
+			binary.BigEndian.Uint32(b[i:]))
+	}
+	return &hash, nil
+}
+
+// encodeUint32 returns the big endian hex encoding of n.
+func encodeUint32(n uint32) string {
+	return fmt.Sprintf("%08x", n)
+}
+
+// decodeUint32 decodes a big endian hex encoded 32-bit number.
+func decodeUint32(s string) (uint32, error) {
+	if len(s) != 8 {
+		return 0, fmt.Errorf("%v: %q is not an 8 digit hex number",
+			ErrBadParams, s)
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", ErrBadParams, err)
+	}
+	return uint32(n), nil
+}
+
+// decodeHex decodes a hex string.
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrBadParams, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/blocktemplate"
+	"github.com/gcash/bchutil/stratum"
+)
+
+// newTemplate returns a regtest block template with several transactions.
+func newTemplate(t *testing.T) *blocktemplate.Template {
+	params := &chaincfg.RegressionNetParams
+	var txs []*bchutil.Tx
+	for i := uint32(0); i < 5; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(params.GenesisHash, i),
+			bytes.Repeat([]byte{0x51}, 8)))
+		tx.AddTxOut(wire.NewTxOut(1000, bytes.Repeat([]byte{0x51}, 25),
+			wire.TokenData{}))
+		txs = append(txs, bchutil.NewTx(tx))
+	}
+	builder := &blocktemplate.Builder{
+		Params:     params,
+		ExtraNonce: 0x0807060504030201,
+		Tag:        []byte("/pool/"),
+	}
+	tmpl, err := builder.NewTemplate(params.GenesisHash, 1,
+		time.Unix(1700000000, 0), txs, 0)
+	if err != nil {
+		t.Fatalf("NewTemplate: unexpected error: %v", err)
+	}
+	return tmpl
+}
+
+// TestJob ensures jobs rebuild the coinbase and header of their template and
+// shares solving a job produce a valid block.
+func TestJob(t *testing.T) {
+	tmpl := newTemplate(t)
+	job, err := stratum.NewJob("1f", tmpl, true)
+	if err != nil {
+		t.Fatalf("NewJob: unexpected error: %v", err)
+	}
+
+	// The coinbase and header built from the extra nonce of the template
+	// must match the template.
+	var buf bytes.Buffer
+	if err := tmpl.Coinbase.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	extraNonce := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	if got := job.Coinbase(extraNonce[:4], extraNonce[4:]); !bytes.Equal(got, buf.Bytes()) {
+		t.Errorf("Coinbase: got %x, want %x", got, buf.Bytes())
+	}
+	hdr := job.Header(extraNonce[:4], extraNonce[4:],
+		uint32(tmpl.Header.Timestamp.Unix()), 0)
+	if hdr.BlockHash() != tmpl.Header.BlockHash() {
+		t.Errorf("Header: got hash %v, want %v", hdr.BlockHash(),
+			tmpl.Header.BlockHash())
+	}
+
+	// Grind a share with other extra nonces until it solves the block.
+	extraNonce1 := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+	share := &stratum.Share{
+		Worker:      "worker",
+		JobID:       job.ID,
+		ExtraNonce2: []byte{0x00, 0x00, 0x00, 0x01},
+		Time:        job.Time + 1,
+	}
+	params := &chaincfg.RegressionNetParams
+	var result *stratum.ShareResult
+	for ; ; share.Nonce++ {
+		result, err = job.CheckShare(extraNonce1, share, params.PowLimit)
+		if err == nil {
+			break
+		}
+		if !strings.HasPrefix(err.Error(), stratum.ErrHighHash.Error()) {
+			t.Fatalf("CheckShare: unexpected error: %v", err)
+		}
+	}
+	if !result.IsBlock {
+		t.Errorf("CheckShare: share does not solve the block")
+	}
+	block, err := job.Block(result)
+	if err != nil {
+		t.Fatalf("Block: unexpected error: %v", err)
+	}
+	if err := block.CheckSanity(nil); err != nil {
+		t.Errorf("CheckSanity: unexpected error: %v", err)
+	}
+	if *block.Hash() != result.Hash {
+		t.Errorf("Block: got hash %v, want %v", block.Hash(), result.Hash)
+	}
+	if block.MsgBlock().Header.MerkleRoot == tmpl.Header.MerkleRoot {
+		t.Errorf("Block: merkle root does not commit to extra nonces")
+	}
+	if block.Height() != tmpl.Height {
+		t.Errorf("Block: got height %d, want %d", block.Height(),
+			tmpl.Height)
+	}
+}
+
+// TestVersionRolling ensures the version bits of a share replace the bits of
+// the job version under the version mask.
+func TestVersionRolling(t *testing.T) {
+	job, err := stratum.NewJob("1", newTemplate(t), false)
+	if err != nil {
+		t.Fatalf("NewJob: unexpected error: %v", err)
+	}
+	job.Version |= 0x00004000
+	versionBits := uint32(0x1fff0000)
+	share := &stratum.Share{
+		JobID:       job.ID,
+		ExtraNonce2: []byte{0x00, 0x00, 0x00, 0x01},
+		Time:        job.Time,
+		VersionBits: &versionBits,
+	}
+	result, err := job.CheckShare([]byte{0xaa, 0xbb, 0xcc, 0xdd}, share,
+		new(big.Int).Lsh(big.NewInt(1), 256))
+	if err != nil {
+		t.Fatalf("CheckShare: unexpected error: %v", err)
+	}
+	want := uint32(job.Version)&^stratum.VersionRollingMask | versionBits
+	if uint32(result.Header.Version) != want {
+		t.Errorf("CheckShare: got version %08x, want %08x",
+			uint32(result.Header.Version), want)
+	}
+	if result.Hash != result.Header.BlockHash() {
+		t.Errorf("CheckShare: hash does not commit to rolled version")
+	}
+}
+
+// TestCheckShareErrors ensures invalid shares are rejected.
+func TestCheckShareErrors(t *testing.T) {
+	job, err := stratum.NewJob("1", newTemplate(t), false)
+	if err != nil {
+		t.Fatalf("NewJob: unexpected error: %v", err)
+	}
+	params := &chaincfg.RegressionNetParams
+	extraNonce2 := []byte{0x00, 0x00, 0x00, 0x00}
+	outsideMask := uint32(0x20000000)
+
+	tests := []struct {
+		name   string
+		share  stratum.Share
+		target *big.Int
+		err    error
+	}{
+		{
+			name:   "job mismatch",
+			share:  stratum.Share{JobID: "2", ExtraNonce2: extraNonce2},
+			target: params.PowLimit,
+			err:    stratum.ErrJobMismatch,
+		},
+		{
+			name:   "extra nonce size",
+			share:  stratum.Share{JobID: "1", ExtraNonce2: extraNonce2[:2]},
+			target: params.PowLimit,
+			err:    stratum.ErrExtraNonceSize,
+		},
+		{
+			name: "version bits outside mask",
+			share: stratum.Share{JobID: "1", ExtraNonce2: extraNonce2,
+				VersionBits: &outsideMask},
+			target: params.PowLimit,
+			err:    stratum.ErrVersionBits,
+		},
+		{
+			name:   "high hash",
+			share:  stratum.Share{JobID: "1", ExtraNonce2: extraNonce2},
+			target: big.NewInt(1),
+			err:    stratum.ErrHighHash,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := job.CheckShare([]byte{0x01, 0x02, 0x03, 0x04},
+			&test.share, test.target)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
+
+// TestNotifyParams ensures mining.notify parameters are encoded as expected
+// by miners and decode back to the job.
+func TestNotifyParams(t *testing.T) {
+	var prevHash chainhash.Hash
+	for i := range prevHash {
+		prevHash[i] = byte(i)
+	}
+	job := &stratum.Job{
+		ID:           "bf",
+		PrevBlock:    prevHash,
+		Coinbase1:    []byte{0x01, 0x00, 0x00, 0x00},
+		Coinbase2:    []byte{0xff, 0xff, 0xff, 0xff},
+		MerkleBranch: []chainhash.Hash{prevHash, {0x01}},
+		Version:      0x20000000,
+		Bits:         0x1d00ffff,
+		Time:         0x504e86b9,
+		CleanJobs:    true,
+	}
+
+	params := job.NotifyParams()
+	want := []interface{}{
+		"bf",
+		"03020100070605040b0a09080f0e0d0c13121110171615141b1a19181f1e1d1c",
+		"01000000",
+		"ffffffff",
+		[]string{
+			"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"0100000000000000000000000000000000000000000000000000000000000000",
+		},
+		"20000000",
+		"1d00ffff",
+		"504e86b9",
+		true,
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Fatalf("NotifyParams: got %v, want %v", params, want)
+	}
+
+	// Decode the parameters as received in a JSON message.
+	b, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+	var decoded []interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: unexpected error: %v", err)
+	}
+	got, err := stratum.ParseNotify(decoded)
+	if err != nil {
+		t.Fatalf("ParseNotify: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, job) {
+		t.Errorf("ParseNotify: got %+v, want %+v", got, job)
+	}
+
+	decoded[6] = "1d00ff"
+	if _, err := stratum.ParseNotify(decoded); err == nil {
+		t.Errorf("ParseNotify: expected error for short bits")
+	}
+	if _, err := stratum.ParseNotify(decoded[:8]); err == nil {
+		t.Errorf("ParseNotify: expected error for missing parameter")
+	}
+}
+
+// TestSubmitParams ensures mining.submit parameters round trip, with and
+// without version rolling.
+func TestSubmitParams(t *testing.T) {
+	versionBits := uint32(0x00002000)
+	tests := []struct {
+		share *stratum.Share
+		want  []interface{}
+	}{
+		{
+			share: &stratum.Share{
+				Worker:      "user.worker",
+				JobID:       "bf",
+				ExtraNonce2: []byte{0x00, 0x00, 0x00, 0x01},
+				Time:        0x504e86ed,
+				Nonce:       0xb2957c02,
+			},
+			want: []interface{}{"user.worker", "bf", "00000001",
+				"504e86ed", "b2957c02"},
+		},
+		{
+			share: &stratum.Share{
+				Worker:      "user.worker",
+				JobID:       "bf",
+				ExtraNonce2: []byte{0x00, 0x00, 0x00, 0x01},
+				Time:        0x504e86ed,
+				Nonce:       0xb2957c02,
+				VersionBits: &versionBits,
+			},
+			want: []interface{}{"user.worker", "bf", "00000001",
+				"504e86ed", "b2957c02", "00002000"},
+		},
+	}
+	for i, test := range tests {
+		params := test.share.SubmitParams()
+		if !reflect.DeepEqual(params, test.want) {
+			t.Errorf("SubmitParams #%d: got %v, want %v", i, params,
+				test.want)
+			continue
+		}
+
+		// Decode the parameters as received in a JSON message.
+		b, err := json.Marshal(params)
+		if err != nil {
+			t.Fatalf("Marshal: unexpected error: %v", err)
+		}
+		var decoded []interface{}
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("Unmarshal: unexpected error: %v", err)
+		}
+		got, err := stratum.ParseSubmit(decoded)
+		if err != nil {
+			t.Errorf("ParseSubmit #%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.share) {
+			t.Errorf("ParseSubmit #%d: got %+v, want %+v", i, got,
+				test.share)
+		}
+	}
+
+	bad := [][]interface{}{
+		{"user.worker", "bf", "00000001", "504e86ed"},
+		{"user.worker", "bf", "0000000g", "504e86ed", "b2957c02"},
+		{"user.worker", "bf", "00000001", "504e86", "b2957c02"},
+		{"user.worker", "bf", "00000001", "504e86ed", "xx957c02"},
+		{"user.worker", "bf", "00000001", "504e86ed", "b2957c02", "2000"},
+		{"user.worker", "bf", "00000001", "504e86ed", "b2957c02",
+			"00002000", "00000000"},
+		{"user.worker", 191.0, "00000001", "504e86ed", "b2957c02"},
+		{"user.worker", "bf", "00000001", "504e86ed", "b2957c02", nil},
+	}
+	for i, test := range bad {
+		if _, err := stratum.ParseSubmit(test); err == nil {
+			t.Errorf("ParseSubmit #%d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package sweep provides construction of transactions which move all funds held
+by a set of private keys, such as those printed on paper wallets, to a single
+address.
+
+# Overview
+
+The caller supplies the keys as WIFs along with the unspent outputs paying to
+them, typically looked up from an indexer or block explorer.  Sweep checks that
+every output pays to one of the keys, spends them all to the destination less
+a fee at the requested rate, and returns the fully signed transaction.
+
+	wif, err := bchutil.DecodeWIF(paperWalletKey)
+	if err != nil {
+		return err
+	}
+	tx, fee, err := sweep.Sweep([]*bchutil.WIF{wif}, utxos, dest, 1000)
+
+Both compressed and uncompressed keys are supported.  A WIF only sweeps the
+outputs paying to the address derived from the public key serialization its
+compression flag selects.
+
+Casascius mini private keys are swept by first decoding them with
+bchutil.DecodeMiniKey, which returns an uncompressed WIF.
+*/
+package sweep
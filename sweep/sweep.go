@@ -0,0 +1,169 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package sweep
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/policy"
+	"github.com/gcash/bchutil/txbuilder"
+	"github.com/gcash/bchutil/txsign"
+)
+
+var (
+	// ErrNoKeys describes an error where a sweep was requested without any
+	// keys.
+	ErrNoKeys = errors.New("no keys to sweep")
+
+	// ErrNoUTXOs describes an error where a sweep was requested without any
+	// unspent outputs.
+	ErrNoUTXOs = errors.New("no unspent outputs to sweep")
+
+	// ErrDuplicateUTXO describes an error where the same unspent output is
+	// passed to a sweep more than once.
+	ErrDuplicateUTXO = errors.New("duplicate unspent output")
+
+	// ErrInsufficientFunds describes an error where the swept value is too
+	// small to pay the fee and leave an output which is not dust.
+	ErrInsufficientFunds = errors.New("swept value does not cover the fee")
+)
+
+// UTXO describes an unspent output paying to one of the keys being swept.
+type UTXO struct {
+	// OutPoint identifies the output.
+	OutPoint wire.OutPoint
+
+	// Value is the value of the output.
+	Value bchutil.Amount
+
+	// PkScript is the output script.  Only pay-to-pubkey-hash and
+	// pay-to-pubkey scripts can be swept.
+	PkScript []byte
+}
+
+// Sweep returns a fully signed transaction spending every one of utxos to a
+// single output paying dest, less a fee at feeRate satoshi per 1000 bytes.
+// The fee paid is returned along with the transaction.  Each outpoint may only
+// appear once in utxos.
+//
+// Every unspent output must pay to the public key, or the hash of the public
+// key, of one of keys.  Pay-to-pubkey-hash outputs must pay to the public key
+// serialization selected by the compression flag of the WIF, which is how
+// wallets derive addresses from both compressed and uncompressed keys.
+func Sweep(keys []*bchutil.WIF, utxos []UTXO, dest bchutil.Address,
+	feeRate bchutil.Amount) (*wire.MsgTx, bchutil.Amount, error) {
+
+	if len(keys) == 0 {
+		return nil, 0, ErrNoKeys
+	}
+	if len(utxos) == 0 {
+		return nil, 0, ErrNoUTXOs
+	}
+
+	pkScript, err := txscript.PayToAddrScript(dest)
+	if err != nil {
+		return nil, 0, fmt.Errorf("destination address: %v", err)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	prevOuts := make([]*wire.TxOut, len(utxos))
+	seen := make(map[wire.OutPoint]struct{}, len(utxos))
+	var total bchutil.Amount
+	for i := range utxos {
+		utxo := &utxos[i]
+		if _, ok := seen[utxo.OutPoint]; ok {
+			return nil, 0, fmt.Errorf("%w: utxo %d (%v)",
+				ErrDuplicateUTXO, i, utxo.OutPoint)
+		}
+		seen[utxo.OutPoint] = struct{}{}
+
+		sigScriptSize, err := sigScriptSize(keys, utxo.PkScript)
+		if err != nil {
+			return nil, 0, fmt.Errorf("utxo %d (%v): %v", i,
+				utxo.OutPoint, err)
+		}
+
+		// Each input is sized with a placeholder signature script of
+		// the worst case size so the fee is computed from the size of
+		// the final transaction.
+		op := utxo.OutPoint
+		tx.AddTxIn(wire.NewTxIn(&op, make([]byte, sigScriptSize)))
+		prevOuts[i] = wire.NewTxOut(int64(utxo.Value), utxo.PkScript,
+			wire.TokenData{})
+		total += utxo.Value
+	}
+	txOut := wire.NewTxOut(0, pkScript, wire.TokenData{})
+	tx.AddTxOut(txOut)
+
+	fee := txbuilder.FeeForSize(feeRate, tx.SerializeSize())
+	txOut.Value = int64(total - fee)
+	if txOut.Value <= 0 || policy.IsDust(txOut, policy.DefaultMinRelayTxFee) {
+		return nil, 0, ErrInsufficientFunds
+	}
+
+	signer := txsign.NewSigner()
+	for _, key := range keys {
+		signer.AddWIF(key)
+	}
+	for _, txIn := range tx.TxIn {
+		txIn.SignatureScript = nil
+	}
+	complete, err := signer.Sign(tx, prevOuts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !complete {
+		return nil, 0, errors.New("unable to sign every input")
+	}
+	if err := txsign.Verify(tx, prevOuts); err != nil {
+		return nil, 0, err
+	}
+	return tx, fee, nil
+}
+
+// sigScriptSize returns the worst case size of the signature script spending
+// pkScript with one of keys, or an error if pkScript does not pay to any of
+// them.
+func sigScriptSize(keys []*bchutil.WIF, pkScript []byte) (int, error) {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		hash := pkScript[3:23]
+		for _, key := range keys {
+			pubKey := key.SerializePubKey()
+			if !bytes.Equal(bchutil.Hash160(pubKey), hash) {
+				continue
+			}
+			if key.CompressPubKey {
+				return txbuilder.RedeemP2PKHSigScriptSize, nil
+			}
+			return txbuilder.RedeemP2PKHUncompressedSigScriptSize, nil
+		}
+
+	case txscript.PubKeyTy:
+		pushes, err := txscript.PushedData(pkScript)
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range keys {
+			pub := key.PrivKey.PubKey()
+			if bytes.Equal(pub.SerializeCompressed(), pushes[0]) ||
+				bytes.Equal(pub.SerializeUncompressed(), pushes[0]) {
+
+				return txbuilder.RedeemP2PKSigScriptSize, nil
+			}
+		}
+
+	default:
+		return 0, errors.New("only pay-to-pubkey-hash and " +
+			"pay-to-pubkey outputs can be swept")
+	}
+
+	return 0, errors.New("output does not pay to any of the keys")
+}
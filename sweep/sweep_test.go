@@ -0,0 +1,178 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package sweep_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gcash/bchd/bchec"
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/sweep"
+	"github.com/gcash/bchutil/txsign"
+)
+
+// newWIF returns a deterministic WIF for tests.
+func newWIF(t *testing.T, b byte, compress bool) *bchutil.WIF {
+	key, _ := bchec.PrivKeyFromBytes(bchec.S256(), bytes.Repeat([]byte{b}, 32))
+	wif, err := bchutil.NewWIF(key, &chaincfg.MainNetParams, compress)
+	if err != nil {
+		t.Fatalf("NewWIF: unexpected error: %v", err)
+	}
+	return wif
+}
+
+// p2pkhScript returns the pay-to-pubkey-hash script for the public key of
+// wif.
+func p2pkhScript(t *testing.T, wif *bchutil.WIF) []byte {
+	addr, err := bchutil.NewAddressPubKeyHash(bchutil.Hash160(
+		wif.SerializePubKey()), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	return script
+}
+
+// newUTXO returns an unspent output with a unique outpoint.
+func newUTXO(i byte, value bchutil.Amount, pkScript []byte) sweep.UTXO {
+	return sweep.UTXO{
+		OutPoint: wire.OutPoint{Hash: chainhash.Hash{i}, Index: uint32(i)},
+		Value:    value,
+		PkScript: pkScript,
+	}
+}
+
+// TestSweep ensures outputs paying to compressed and uncompressed keys are
+// swept to the destination in a single valid transaction.
+func TestSweep(t *testing.T) {
+	compressed := newWIF(t, 0x01, true)
+	uncompressed := newWIF(t, 0x02, false)
+	p2pk := []byte{txscript.OP_DATA_33}
+	p2pk = append(p2pk, compressed.PrivKey.PubKey().SerializeCompressed()...)
+	p2pk = append(p2pk, txscript.OP_CHECKSIG)
+
+	utxos := []sweep.UTXO{
+		newUTXO(1, 100000, p2pkhScript(t, compressed)),
+		newUTXO(2, 200000, p2pkhScript(t, uncompressed)),
+		newUTXO(3, 300000, p2pk),
+	}
+	dest, _ := bchutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0xaa}, 20),
+		&chaincfg.MainNetParams)
+
+	tx, fee, err := sweep.Sweep([]*bchutil.WIF{compressed, uncompressed},
+		utxos, dest, 1000)
+	if err != nil {
+		t.Fatalf("Sweep: unexpected error: %v", err)
+	}
+
+	// 10 bytes overhead, 148 and 180 byte p2pkh inputs, a 114 byte p2pk
+	// input and a 34 byte output.
+	if fee != 486 {
+		t.Errorf("unexpected fee - got %d, want %d", fee, 486)
+	}
+	if len(tx.TxIn) != len(utxos) || len(tx.TxOut) != 1 {
+		t.Fatalf("unexpected transaction shape - got %d inputs and "+
+			"%d outputs", len(tx.TxIn), len(tx.TxOut))
+	}
+	if tx.TxOut[0].Value != 600000-int64(fee) {
+		t.Errorf("unexpected output value %d", tx.TxOut[0].Value)
+	}
+	if tx.SerializeSize() > 486 {
+		t.Errorf("signed size %d exceeds estimate", tx.SerializeSize())
+	}
+
+	prevOuts := make([]*wire.TxOut, len(utxos))
+	for i, utxo := range utxos {
+		prevOuts[i] = wire.NewTxOut(int64(utxo.Value), utxo.PkScript,
+			wire.TokenData{})
+	}
+	if err := txsign.Verify(tx, prevOuts); err != nil {
+		t.Errorf("swept transaction does not verify: %v", err)
+	}
+}
+
+// TestSweepErrors ensures invalid sweeps are rejected.
+func TestSweepErrors(t *testing.T) {
+	compressed := newWIF(t, 0x01, true)
+	dest, _ := bchutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0xaa}, 20),
+		&chaincfg.MainNetParams)
+	keys := []*bchutil.WIF{compressed}
+
+	tests := []struct {
+		name  string
+		keys  []*bchutil.WIF
+		utxos []sweep.UTXO
+		err   error
+	}{
+		{
+			name:  "no keys",
+			utxos: []sweep.UTXO{newUTXO(1, 100000, p2pkhScript(t, compressed))},
+			err:   sweep.ErrNoKeys,
+		},
+		{
+			name: "no utxos",
+			keys: keys,
+			err:  sweep.ErrNoUTXOs,
+		},
+		{
+			name: "duplicate utxo",
+			keys: keys,
+			utxos: []sweep.UTXO{
+				newUTXO(1, 100000, p2pkhScript(t, compressed)),
+				newUTXO(2, 100000, p2pkhScript(t, compressed)),
+				newUTXO(1, 100000, p2pkhScript(t, compressed)),
+			},
+			err: sweep.ErrDuplicateUTXO,
+		},
+		{
+			name:  "dust after fee",
+			keys:  keys,
+			utxos: []sweep.UTXO{newUTXO(1, 700, p2pkhScript(t, compressed))},
+			err:   sweep.ErrInsufficientFunds,
+		},
+		{
+			// The uncompressed form of the key pays to a different
+			// address than the compressed WIF.
+			name: "utxo paying to the other key serialization",
+			keys: keys,
+			utxos: []sweep.UTXO{newUTXO(1, 100000,
+				p2pkhScript(t, newWIF(t, 0x01, false)))},
+		},
+		{
+			name: "utxo paying to another key",
+			keys: keys,
+			utxos: []sweep.UTXO{
+				newUTXO(1, 100000, p2pkhScript(t, compressed)),
+				newUTXO(2, 100000, p2pkhScript(t, newWIF(t, 0x02, true))),
+			},
+		},
+		{
+			name:  "unsupported script",
+			keys:  keys,
+			utxos: []sweep.UTXO{newUTXO(1, 100000, []byte{txscript.OP_TRUE})},
+		},
+	}
+
+	for _, test := range tests {
+		_, _, err := sweep.Sweep(test.keys, test.utxos, dest, 1000)
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+			continue
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, test.err)
+		}
+	}
+}
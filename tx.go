@@ -20,9 +20,11 @@ const TxIndexUnknown = -1
 // Tx defines a bitcoin transaction that provides easier and more efficient
 // manipulation of raw transactions.  It also memoizes the hash for the
 // transaction on its first access so subsequent accesses don't have to repeat
-// the relatively expensive hashing operations.
+// the relatively expensive hashing operations.  The cached hash is safe for
+// concurrent access.
 type Tx struct {
 	msgTx   *wire.MsgTx     // Underlying MsgTx
+	hashMtx Mutex           // Protects txHash
 	txHash  *chainhash.Hash // Cached transaction hash
 	txIndex int             // Position within a block or TxIndexUnknown
 }
@@ -37,6 +39,9 @@ func (t *Tx) MsgTx() *wire.MsgTx {
 // calling TxHash on the underlying wire.MsgTx, however it caches the
 // result so subsequent calls are more efficient.
 func (t *Tx) Hash() *chainhash.Hash {
+	t.hashMtx.Lock()
+	defer t.hashMtx.Unlock()
+
 	// Return the cached hash if it has already been generated.
 	if t.txHash != nil {
 		return t.txHash
@@ -0,0 +1,249 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/coinset"
+	"github.com/gcash/bchutil/policy"
+	"github.com/gcash/bchutil/txsort"
+)
+
+var (
+	// ErrNoPayees describes an error where a transaction was requested
+	// without any outputs to pay.
+	ErrNoPayees = errors.New("no payees")
+
+	// ErrInsufficientFunds describes an error where the available coins
+	// cannot cover the payees and the fee.
+	ErrInsufficientFunds = errors.New("insufficient funds available to " +
+		"construct transaction")
+
+	// ErrNoChangeAddress describes an error where the selected coins
+	// leave non-dust change but the Builder has no change address.
+	ErrNoChangeAddress = errors.New("no change address")
+)
+
+// Payee describes a payment of Amount to Address.
+type Payee struct {
+	Address bchutil.Address
+	Amount  bchutil.Amount
+}
+
+// Builder constructs unsigned transactions paying a set of payees from a set
+// of coins.  Coins are chosen with Selector, a fee is paid at FeeRate and any
+// leftover value which is not dust is returned to ChangeAddress.
+type Builder struct {
+	// Selector chooses which of the available coins are spent.
+	Selector coinset.CoinSelector
+
+	// FeeRate is the fee rate to pay, in satoshi per 1000 bytes.
+	FeeRate bchutil.Amount
+
+	// ChangeAddress receives any change.  It may be nil only if no
+	// transaction built needs change.
+	ChangeAddress bchutil.Address
+
+	// DustRelayFee is the relay fee rate, in satoshi per 1000 bytes, used
+	// to decide whether outputs are dust.  When zero,
+	// policy.DefaultMinRelayTxFee is used.
+	DustRelayFee bchutil.Amount
+
+	// Version is the transaction version.  When zero, wire.TxVersion is
+	// used.
+	Version int32
+
+	// LockTime is the transaction lock time.
+	LockTime uint32
+
+	// SortBIP69 specifies whether inputs and outputs are sorted according
+	// to BIP 69 with the txsort package.
+	SortBIP69 bool
+
+	// InputSize, when set, returns the worst case serialized size of the
+	// signed input spending the passed coin.  When nil, EstimateInputSize
+	// is used.
+	InputSize func(coin coinset.Coin) (int, error)
+}
+
+// AuthoredTx holds an unsigned transaction created by a Builder along with
+// the information needed to sign it.
+type AuthoredTx struct {
+	// Tx is the unsigned transaction.
+	Tx *wire.MsgTx
+
+	// PrevCoins holds the coin spent by each input of Tx, in input order.
+	PrevCoins []coinset.Coin
+
+	// TotalInput is the total value of PrevCoins.
+	TotalInput bchutil.Amount
+
+	// Fee is the fee paid by the transaction.
+	Fee bchutil.Amount
+
+	// EstimatedSize is the worst case serialized size of the transaction
+	// once signed.  Bitcoin cash has no witness data so this is also the
+	// virtual size of the transaction.
+	EstimatedSize int
+
+	// ChangeIndex is the index of the change output in Tx, or -1 if the
+	// transaction has no change.
+	ChangeIndex int
+}
+
+// Build returns an unsigned transaction paying every payee from a selection of
+// the passed coins.
+//
+// The fee is computed from the worst case size of the signed transaction, so
+// the actual fee rate once signed is never lower than FeeRate.  Change which
+// would be dust is not created and is instead added to the fee.
+// ErrInsufficientFunds is returned when no selection of coins covers the
+// payees and the fee.
+func (b *Builder) Build(payees []Payee, coins []coinset.Coin) (*AuthoredTx, error) {
+	if len(payees) == 0 {
+		return nil, ErrNoPayees
+	}
+
+	dustRelayFee := b.DustRelayFee
+	if dustRelayFee == 0 {
+		dustRelayFee = policy.DefaultMinRelayTxFee
+	}
+	inputSize := b.InputSize
+	if inputSize == nil {
+		inputSize = EstimateInputSize
+	}
+
+	outputs := make([]*wire.TxOut, 0, len(payees)+1)
+	var targetAmount bchutil.Amount
+	for i, payee := range payees {
+		pkScript, err := txscript.PayToAddrScript(payee.Address)
+		if err != nil {
+			return nil, fmt.Errorf("payee %d: %v", i, err)
+		}
+		txOut := wire.NewTxOut(int64(payee.Amount), pkScript,
+			wire.TokenData{})
+		if policy.IsDust(txOut, dustRelayFee) {
+			return nil, fmt.Errorf("payee %d: payment of %v is dust",
+				i, payee.Amount)
+		}
+		outputs = append(outputs, txOut)
+		targetAmount += payee.Amount
+	}
+
+	// The change output is sized up front so the fee of a transaction
+	// with change can be computed before knowing whether change is needed.
+	changeOut := wire.NewTxOut(0, nil, wire.TokenData{})
+	if b.ChangeAddress != nil {
+		pkScript, err := txscript.PayToAddrScript(b.ChangeAddress)
+		if err != nil {
+			return nil, fmt.Errorf("change address: %v", err)
+		}
+		changeOut.PkScript = pkScript
+	}
+
+	// Select coins for the payees plus an increasing fee estimate until
+	// the selection covers the fee of the transaction it produces.
+	targetFee := FeeForSize(b.FeeRate, estimateTxSize(nil, outputs))
+	for {
+		selected, err := b.Selector.CoinSelect(targetAmount+targetFee, coins)
+		if err != nil {
+			if err == coinset.ErrCoinsNoSelectionAvailable {
+				return nil, ErrInsufficientFunds
+			}
+			return nil, err
+		}
+
+		selectedCoins := selected.Coins()
+		inputSizes := make([]int, len(selectedCoins))
+		var totalInput bchutil.Amount
+		for i, coin := range selectedCoins {
+			inputSizes[i], err = inputSize(coin)
+			if err != nil {
+				return nil, err
+			}
+			totalInput += coin.Value()
+		}
+
+		sizeNoChange := estimateTxSize(inputSizes, outputs)
+		feeNoChange := FeeForSize(b.FeeRate, sizeNoChange)
+		if totalInput < targetAmount+feeNoChange {
+			targetFee = feeNoChange
+			continue
+		}
+
+		tx := coinset.NewMsgTxWithInputCoins(b.version(), selected)
+		tx.LockTime = b.LockTime
+		tx.TxOut = outputs
+
+		authored := &AuthoredTx{
+			Tx:            tx,
+			PrevCoins:     selectedCoins,
+			TotalInput:    totalInput,
+			Fee:           totalInput - targetAmount,
+			EstimatedSize: sizeNoChange,
+			ChangeIndex:   -1,
+		}
+
+		// Add a change output when the leftover value after paying
+		// the larger fee of a transaction with change is not dust.
+		sizeChange := estimateTxSize(inputSizes,
+			append(outputs[:len(outputs):len(outputs)], changeOut))
+		feeChange := FeeForSize(b.FeeRate, sizeChange)
+		changeOut.Value = int64(totalInput - targetAmount - feeChange)
+		if changeOut.Value > 0 && !policy.IsDust(changeOut, dustRelayFee) {
+			if b.ChangeAddress == nil {
+				return nil, ErrNoChangeAddress
+			}
+			tx.TxOut = append(tx.TxOut, changeOut)
+			authored.ChangeIndex = len(tx.TxOut) - 1
+			authored.Fee = feeChange
+			authored.EstimatedSize = sizeChange
+		}
+
+		if b.SortBIP69 {
+			authored.sortBIP69()
+		}
+		return authored, nil
+	}
+}
+
+// version returns the transaction version to use.
+func (b *Builder) version() int32 {
+	if b.Version == 0 {
+		return wire.TxVersion
+	}
+	return b.Version
+}
+
+// sortBIP69 sorts the inputs and outputs of the transaction according to BIP
+// 69 while keeping PrevCoins and ChangeIndex in step with the new order.
+func (tx *AuthoredTx) sortBIP69() {
+	var changeOut *wire.TxOut
+	if tx.ChangeIndex >= 0 {
+		changeOut = tx.Tx.TxOut[tx.ChangeIndex]
+	}
+
+	coinsByOutPoint := make(map[wire.OutPoint]coinset.Coin, len(tx.PrevCoins))
+	for _, coin := range tx.PrevCoins {
+		op := wire.OutPoint{Hash: *coin.Hash(), Index: coin.Index()}
+		coinsByOutPoint[op] = coin
+	}
+
+	txsort.InPlaceSort(tx.Tx)
+
+	for i, txIn := range tx.Tx.TxIn {
+		tx.PrevCoins[i] = coinsByOutPoint[txIn.PreviousOutPoint]
+	}
+	for i, txOut := range tx.Tx.TxOut {
+		if txOut == changeOut {
+			tx.ChangeIndex = i
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/coinset"
+	"github.com/gcash/bchutil/txbuilder"
+	"github.com/gcash/bchutil/txsort"
+)
+
+type testCoin struct {
+	hash     chainhash.Hash
+	index    uint32
+	value    bchutil.Amount
+	pkScript []byte
+}
+
+func (c *testCoin) Hash() *chainhash.Hash { return &c.hash }
+func (c *testCoin) Index() uint32         { return c.index }
+func (c *testCoin) Value() bchutil.Amount { return c.value }
+func (c *testCoin) PkScript() []byte      { return c.pkScript }
+func (c *testCoin) NumConfs() int64       { return 1 }
+func (c *testCoin) ValueAge() int64       { return int64(c.value) }
+
+func newAddress(t *testing.T, b byte) bchutil.Address {
+	addr, err := bchutil.NewAddressPubKeyHash(bytes.Repeat([]byte{b}, 20),
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	return addr
+}
+
+func newCoins(t *testing.T, values ...bchutil.Amount) []coinset.Coin {
+	pkScript, err := txscript.PayToAddrScript(newAddress(t, 0xaa))
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	coins := make([]coinset.Coin, len(values))
+	for i, value := range values {
+		coins[i] = &testCoin{
+			hash:     chainhash.Hash{byte(len(values) - i)},
+			index:    uint32(i),
+			value:    value,
+			pkScript: pkScript,
+		}
+	}
+	return coins
+}
+
+// TestBuild ensures the builder selects coins, pays fees and creates change
+// as expected.
+func TestBuild(t *testing.T) {
+	payee := newAddress(t, 0x01)
+	change := newAddress(t, 0x02)
+	selector := &coinset.MinIndexCoinSelector{MaxInputs: 10}
+
+	tests := []struct {
+		name       string
+		coins      []bchutil.Amount
+		amount     bchutil.Amount
+		feeRate    bchutil.Amount
+		numInputs  int
+		fee        bchutil.Amount
+		change     bchutil.Amount
+		hasChange  bool
+		err        error
+		changeAddr bchutil.Address
+	}{
+		{
+			name:      "single input with change",
+			coins:     []bchutil.Amount{100000},
+			amount:    50000,
+			feeRate:   1000,
+			numInputs: 1,
+			// 10 bytes overhead + 148 input + 2*34 outputs
+			fee:        226,
+			change:     100000 - 50000 - 226,
+			hasChange:  true,
+			changeAddr: change,
+		},
+		{
+			name:       "dust change is dropped",
+			coins:      []bchutil.Amount{50500},
+			amount:     50000,
+			feeRate:    1000,
+			numInputs:  1,
+			fee:        500,
+			changeAddr: change,
+		},
+		{
+			name:      "second input selected to cover fee",
+			coins:     []bchutil.Amount{50000, 100000},
+			amount:    50000,
+			feeRate:   1000,
+			numInputs: 2,
+			// 10 bytes overhead + 2*148 inputs + 2*34 outputs
+			fee:        374,
+			change:     150000 - 50000 - 374,
+			hasChange:  true,
+			changeAddr: change,
+		},
+		{
+			name:    "insufficient funds",
+			coins:   []bchutil.Amount{50000},
+			amount:  50000,
+			feeRate: 1000,
+			err:     txbuilder.ErrInsufficientFunds,
+		},
+		{
+			name:    "change without change address",
+			coins:   []bchutil.Amount{100000},
+			amount:  50000,
+			feeRate: 1000,
+			err:     txbuilder.ErrNoChangeAddress,
+		},
+	}
+
+	for _, test := range tests {
+		b := txbuilder.Builder{
+			Selector:      selector,
+			FeeRate:       test.feeRate,
+			ChangeAddress: test.changeAddr,
+		}
+		authored, err := b.Build([]txbuilder.Payee{{payee, test.amount}},
+			newCoins(t, test.coins...))
+		if err != test.err {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		tx := authored.Tx
+		if len(tx.TxIn) != test.numInputs {
+			t.Errorf("%s: unexpected number of inputs - got %d, "+
+				"want %d", test.name, len(tx.TxIn), test.numInputs)
+		}
+		if authored.Fee != test.fee {
+			t.Errorf("%s: unexpected fee - got %d, want %d",
+				test.name, authored.Fee, test.fee)
+		}
+		if (authored.ChangeIndex >= 0) != test.hasChange {
+			t.Errorf("%s: unexpected change index %d", test.name,
+				authored.ChangeIndex)
+			continue
+		}
+
+		var totalOut bchutil.Amount
+		for _, txOut := range tx.TxOut {
+			totalOut += bchutil.Amount(txOut.Value)
+		}
+		if authored.TotalInput-totalOut != authored.Fee {
+			t.Errorf("%s: fee %d does not match inputs %d minus "+
+				"outputs %d", test.name, authored.Fee,
+				authored.TotalInput, totalOut)
+		}
+		if test.hasChange {
+			got := bchutil.Amount(tx.TxOut[authored.ChangeIndex].Value)
+			if got != test.change {
+				t.Errorf("%s: unexpected change - got %d, want %d",
+					test.name, got, test.change)
+			}
+		}
+	}
+}
+
+// TestBuildSorted ensures BIP 69 sorting keeps the previous coins and change
+// index consistent with the sorted transaction.
+func TestBuildSorted(t *testing.T) {
+	b := txbuilder.Builder{
+		Selector:      &coinset.MinIndexCoinSelector{MaxInputs: 10},
+		FeeRate:       1000,
+		ChangeAddress: newAddress(t, 0x02),
+		SortBIP69:     true,
+	}
+	coins := newCoins(t, 30000, 40000, 50000)
+	payees := []txbuilder.Payee{
+		{newAddress(t, 0x01), 90000},
+		{newAddress(t, 0x03), 1000},
+	}
+	authored, err := b.Build(payees, coins)
+	if err != nil {
+		t.Fatalf("Build: unexpected error: %v", err)
+	}
+
+	if !txsort.IsSorted(authored.Tx) {
+		t.Fatalf("transaction is not sorted")
+	}
+	for i, txIn := range authored.Tx.TxIn {
+		coin := authored.PrevCoins[i]
+		if txIn.PreviousOutPoint.Hash != *coin.Hash() ||
+			txIn.PreviousOutPoint.Index != coin.Index() {
+
+			t.Errorf("input %d does not spend its previous coin", i)
+		}
+	}
+	changeOut := authored.Tx.TxOut[authored.ChangeIndex]
+	wantScript, _ := txscript.PayToAddrScript(b.ChangeAddress)
+	if !bytes.Equal(changeOut.PkScript, wantScript) {
+		t.Errorf("change index %d does not point at the change output",
+			authored.ChangeIndex)
+	}
+}
+
+// TestEstimateInputSize ensures the input size estimates match the worst case
+// sizes of the corresponding signed inputs.
+func TestEstimateInputSize(t *testing.T) {
+	p2pkh, _ := txscript.PayToAddrScript(newAddress(t, 0x01))
+	p2sh := []byte{txscript.OP_HASH160, txscript.OP_DATA_20}
+	p2sh = append(p2sh, bytes.Repeat([]byte{0x01}, 20)...)
+	p2sh = append(p2sh, txscript.OP_EQUAL)
+
+	size, err := txbuilder.EstimateInputSize(&testCoin{pkScript: p2pkh})
+	if err != nil {
+		t.Fatalf("EstimateInputSize: unexpected error: %v", err)
+	}
+	if size != 148 {
+		t.Errorf("unexpected p2pkh input size %d", size)
+	}
+
+	if _, err := txbuilder.EstimateInputSize(&testCoin{pkScript: p2sh}); err == nil {
+		t.Errorf("expected error estimating p2sh input size")
+	}
+
+	txIn := wire.TxIn{SignatureScript: make([]byte,
+		txbuilder.RedeemP2PKHSigScriptSize)}
+	if txIn.SerializeSize() != txbuilder.RedeemP2PKHInputSize {
+		t.Errorf("input size mismatch - got %d, want %d",
+			txIn.SerializeSize(), txbuilder.RedeemP2PKHInputSize)
+	}
+}
+
+// TestFeeForSize ensures fees are rounded up to the next satoshi.
+func TestFeeForSize(t *testing.T) {
+	tests := []struct {
+		feeRate bchutil.Amount
+		size    int
+		fee     bchutil.Amount
+	}{
+		{1000, 0, 0},
+		{1000, 226, 226},
+		{1500, 250, 375},
+		{1500, 225, 338},
+		{1, 1, 1},
+		{999, 1001, 1000},
+	}
+
+	for _, test := range tests {
+		fee := txbuilder.FeeForSize(test.feeRate, test.size)
+		if fee != test.fee {
+			t.Errorf("FeeForSize(%v, %d): got %v, want %v",
+				int64(test.feeRate), test.size, int64(fee),
+				int64(test.fee))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txbuilder provides construction of unsigned bitcoin cash transactions
+from a set of payees and spendable coins.
+
+# Overview
+
+The coinset package selects coins and turns them into transaction inputs, but
+leaves outputs, fees and change to the caller.  A Builder combines those steps:
+it selects coins with any coinset.CoinSelector until the selection covers the
+payees plus the fee for the worst case size of the signed transaction, adds a
+change output unless the change would be dust, and optionally sorts the result
+according to BIP 69.
+
+	b := txbuilder.Builder{
+		Selector:      &coinset.MinNumberCoinSelector{MaxInputs: 50},
+		FeeRate:       1000, // satoshi per 1000 bytes
+		ChangeAddress: changeAddr,
+		SortBIP69:     true,
+	}
+	authored, err := b.Build([]txbuilder.Payee{{Address: addr, Amount: amt}}, coins)
+	if err != nil {
+		return err
+	}
+	fmt.Println(authored.Fee, authored.EstimatedSize)
+
+The returned AuthoredTx holds the unsigned transaction along with the coin
+spent by each input, which is everything needed to sign it.
+*/
+package txbuilder
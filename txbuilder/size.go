@@ -0,0 +1,101 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"fmt"
+
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/coinset"
+)
+
+const (
+	// inputBaseSize is the size of an input without its signature script:
+	// 36 bytes of previous outpoint and 4 bytes of sequence.
+	inputBaseSize = 32 + 4 + 4
+
+	// maxSigSize is the size of the largest signature push: 1 byte
+	// OP_DATA_72, a 71 byte DER encoded ECDSA signature and 1 byte of
+	// sighash type.  Schnorr signatures are smaller so this is an upper
+	// bound for both signature types.
+	maxSigSize = 1 + 71 + 1
+
+	// RedeemP2PKHSigScriptSize is the worst case size of a signature
+	// script spending a pay-to-pubkey-hash output with a compressed public
+	// key: a signature push followed by a 1 byte OP_DATA_33 and the 33
+	// byte public key.
+	RedeemP2PKHSigScriptSize = maxSigSize + 1 + 33
+
+	// RedeemP2PKHUncompressedSigScriptSize is the worst case size of a
+	// signature script spending a pay-to-pubkey-hash output with an
+	// uncompressed public key.
+	RedeemP2PKHUncompressedSigScriptSize = maxSigSize + 1 + 65
+
+	// RedeemP2PKSigScriptSize is the worst case size of a signature script
+	// spending a pay-to-pubkey output.
+	RedeemP2PKSigScriptSize = maxSigSize
+
+	// RedeemP2PKHInputSize is the worst case size of a transaction input
+	// spending a pay-to-pubkey-hash output with a compressed public key.
+	RedeemP2PKHInputSize = inputBaseSize + 1 + RedeemP2PKHSigScriptSize
+)
+
+// InputSize returns the serialized size of a transaction input with a
+// signature script of the given size.
+func InputSize(sigScriptSize int) int {
+	return inputBaseSize + wire.VarIntSerializeSize(uint64(sigScriptSize)) +
+		sigScriptSize
+}
+
+// EstimateInputSize returns the worst case serialized size of the signed
+// input spending the passed coin.  Pay-to-pubkey-hash coins are assumed to be
+// spent with a compressed public key.  Pay-to-script-hash and non-standard
+// coins cannot be sized from the output script alone and result in an error;
+// Builder.InputSize may be used to size those.
+func EstimateInputSize(coin coinset.Coin) (int, error) {
+	pkScript := coin.PkScript()
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		return InputSize(RedeemP2PKHSigScriptSize), nil
+
+	case txscript.PubKeyTy:
+		return InputSize(RedeemP2PKSigScriptSize), nil
+
+	case txscript.MultiSigTy:
+		_, numSigs, err := txscript.CalcMultiSigStats(pkScript)
+		if err != nil {
+			return 0, err
+		}
+
+		// OP_0 followed by the required signatures.
+		return InputSize(1 + numSigs*maxSigSize), nil
+	}
+
+	return 0, fmt.Errorf("unable to estimate the size of the input "+
+		"spending %v:%d", coin.Hash(), coin.Index())
+}
+
+// estimateTxSize returns the serialized size of a transaction with inputs of
+// the passed sizes and the passed outputs.
+func estimateTxSize(inputSizes []int, outputs []*wire.TxOut) int {
+	size := 4 + wire.VarIntSerializeSize(uint64(len(inputSizes))) +
+		wire.VarIntSerializeSize(uint64(len(outputs))) + 4
+	for _, inputSize := range inputSizes {
+		size += inputSize
+	}
+	for _, txOut := range outputs {
+		size += txOut.SerializeSize()
+	}
+	return size
+}
+
+// FeeForSize returns the fee for a transaction of the given serialized size
+// at the given fee rate in satoshi per 1000 bytes.  The fee is rounded up so
+// the resulting fee rate is never lower than feeRate.
+func FeeForSize(feeRate bchutil.Amount, size int) bchutil.Amount {
+	return (feeRate*bchutil.Amount(size) + 999) / 1000
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txjson renders transactions as the JSON returned by the
+decoderawtransaction RPC of full nodes and parses that JSON back into
+transactions.
+
+# Overview
+
+NewTx describes a bchutil.Tx with its txid, size, inputs with their signature
+script disassembly, and outputs with their value as a bchutil.Amount, the
+standard type of their script, the cashaddr addresses they pay and any
+CashTokens data.  Tx marshals to the JSON form used by full nodes, in which
+values are written in bitcoin cash with eight decimal places and token amounts
+as strings:
+
+	data, err := txjson.Marshal(tx, &chaincfg.MainNetParams)
+
+Unmarshal parses the JSON back into a Tx, decoding the addresses for the
+network of the passed parameters, and MsgTx rebuilds the transaction from the
+hex encoded scripts, checking it against the txid.  UnmarshalMsgTx combines
+the two:
+
+	msgTx, err := txjson.UnmarshalMsgTx(data)
+
+Values are parsed exactly, so amounts survive the round trip without floating
+point rounding.
+*/
+package txjson
@@ -0,0 +1,572 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txjson
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+)
+
+// NFT capability names used in the tokenData of outputs.
+const (
+	CapabilityNone    = "none"
+	CapabilityMutable = "mutable"
+	CapabilityMinting = "minting"
+)
+
+var (
+	// ErrTxidMismatch describes an error where the txid of a decoded
+	// transaction does not match the transaction built from its fields.
+	ErrTxidMismatch = errors.New("txid does not match transaction")
+
+	// ErrBadValue describes an error where an output value is not a
+	// valid amount of bitcoin cash.
+	ErrBadValue = errors.New("invalid output value")
+
+	// ErrBadCapability describes an error where the capability of an NFT
+	// is not one of the known capabilities.
+	ErrBadCapability = errors.New("invalid NFT capability")
+)
+
+// Tx is a transaction in the form returned by the decoderawtransaction RPC
+// of full nodes.
+type Tx struct {
+	Txid     chainhash.Hash
+	Hash     chainhash.Hash
+	Size     int
+	Version  int32
+	LockTime uint32
+	Vin      []Vin
+	Vout     []Vout
+}
+
+// Vin is a transaction input.  Coinbase inputs have a non-nil Coinbase holding
+// the signature script and no previous outpoint or ScriptSig.
+type Vin struct {
+	Coinbase  []byte
+	PrevOut   wire.OutPoint
+	ScriptSig Script
+	Sequence  uint32
+}
+
+// Script is a script along with its disassembly.
+type Script struct {
+	Asm string
+	Hex []byte
+}
+
+// Vout is a transaction output.  TokenData is nil for outputs which do not
+// carry CashTokens.
+type Vout struct {
+	Value        bchutil.Amount
+	N            uint32
+	ScriptPubKey ScriptPubKey
+	TokenData    *TokenData
+}
+
+// ScriptPubKey is an output script with its standard type, the number of
+// signatures required to spend it and the addresses it pays.
+type ScriptPubKey struct {
+	Script
+	ReqSigs   int
+	Type      string
+	Addresses []bchutil.Address
+}
+
+// TokenData is the CashTokens data of an output.  NFT is nil for outputs which
+// only carry fungible tokens.
+type TokenData struct {
+	Category chainhash.Hash
+	Amount   uint64
+	NFT      *NFT
+}
+
+// NFT is a non-fungible token carried by an output.
+type NFT struct {
+	Capability string
+	Commitment []byte
+}
+
+// NewTx returns the decoderawtransaction form of tx.  Addresses are those of
+// the network described by params.
+func NewTx(tx *bchutil.Tx, params *chaincfg.Params) *Tx {
+	msgTx := tx.MsgTx()
+	t := &Tx{
+		Txid:     *tx.Hash(),
+		Hash:     *tx.Hash(),
+		Size:     msgTx.SerializeSize(),
+		Version:  msgTx.Version,
+		LockTime: msgTx.LockTime,
+		Vin:      make([]Vin, len(msgTx.TxIn)),
+		Vout:     make([]Vout, len(msgTx.TxOut)),
+	}
+
+	isCoinbase := isCoinbaseTx(msgTx)
+	for i, txIn := range msgTx.TxIn {
+		vin := &t.Vin[i]
+		vin.Sequence = txIn.Sequence
+		if isCoinbase {
+			vin.Coinbase = txIn.SignatureScript
+			continue
+		}
+		vin.PrevOut = txIn.PreviousOutPoint
+		vin.ScriptSig = newScript(txIn.SignatureScript)
+	}
+
+	for i, txOut := range msgTx.TxOut {
+		vout := &t.Vout[i]
+		vout.Value = bchutil.Amount(txOut.Value)
+		vout.N = uint32(i)
+		vout.ScriptPubKey.Script = newScript(txOut.PkScript)
+		class, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(
+			txOut.PkScript, params)
+		vout.ScriptPubKey.Type = scriptType(class)
+		vout.ScriptPubKey.ReqSigs = reqSigs
+		for _, addr := range addrs {
+			// Full nodes report pay-to-pubkey outputs with the
+			// cashaddr of the public key hash.
+			if pk, ok := addr.(*bchutil.AddressPubKey); ok {
+				addr = pk.AddressPubKeyHash()
+			}
+			vout.ScriptPubKey.Addresses = append(
+				vout.ScriptPubKey.Addresses, addr)
+		}
+		if !txOut.TokenData.IsEmpty() {
+			vout.TokenData = newTokenData(&txOut.TokenData)
+		}
+	}
+	return t
+}
+
+// MsgTx returns the transaction described by t.  Disassembly, script types
+// and addresses are ignored, since they are derived from the scripts.  When
+// Txid is set, ErrTxidMismatch is returned if it differs from the hash of the
+// built transaction.
+func (t *Tx) MsgTx() (*wire.MsgTx, error) {
+	msgTx := &wire.MsgTx{
+		Version:  t.Version,
+		LockTime: t.LockTime,
+		TxIn:     make([]*wire.TxIn, len(t.Vin)),
+		TxOut:    make([]*wire.TxOut, len(t.Vout)),
+	}
+	for i := range t.Vin {
+		vin := &t.Vin[i]
+		txIn := &wire.TxIn{Sequence: vin.Sequence}
+		if vin.Coinbase != nil {
+			txIn.PreviousOutPoint.Index = wire.MaxPrevOutIndex
+			txIn.SignatureScript = vin.Coinbase
+		} else {
+			txIn.PreviousOutPoint = vin.PrevOut
+			txIn.SignatureScript = vin.ScriptSig.Hex
+		}
+		msgTx.TxIn[i] = txIn
+	}
+	for i := range t.Vout {
+		vout := &t.Vout[i]
+		txOut := wire.NewTxOut(int64(vout.Value), vout.ScriptPubKey.Hex,
+			wire.TokenData{})
+		if vout.TokenData != nil {
+			tokenData, err := vout.TokenData.wireTokenData()
+			if err != nil {
+				return nil, fmt.Errorf("output %d: %v", i, err)
+			}
+			txOut.TokenData = *tokenData
+		}
+		msgTx.TxOut[i] = txOut
+	}
+
+	if t.Txid != (chainhash.Hash{}) && msgTx.TxHash() != t.Txid {
+		return nil, fmt.Errorf("%v: got %v, want %v", ErrTxidMismatch,
+			msgTx.TxHash(), t.Txid)
+	}
+	return msgTx, nil
+}
+
+// Marshal returns the decoderawtransaction JSON encoding of tx.
+func Marshal(tx *bchutil.Tx, params *chaincfg.Params) ([]byte, error) {
+	return json.Marshal(NewTx(tx, params))
+}
+
+// Unmarshal parses the decoderawtransaction JSON encoding of a transaction.
+// Addresses are decoded for the network described by params.
+func Unmarshal(data []byte, params *chaincfg.Params) (*Tx, error) {
+	var raw jsonTx
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw.tx(params)
+}
+
+// UnmarshalMsgTx parses the decoderawtransaction JSON encoding of a
+// transaction and returns the transaction it describes.
+func UnmarshalMsgTx(data []byte) (*wire.MsgTx, error) {
+	// Addresses are not needed to build the transaction, so any network
+	// parameters do.
+	t, err := Unmarshal(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.MsgTx()
+}
+
+// MarshalJSON returns the decoderawtransaction JSON encoding of t.
+func (t *Tx) MarshalJSON() ([]byte, error) {
+	raw := jsonTx{
+		Txid:     t.Txid.String(),
+		Hash:     t.Hash.String(),
+		Size:     t.Size,
+		Version:  t.Version,
+		LockTime: t.LockTime,
+		Vin:      make([]jsonVin, len(t.Vin)),
+		Vout:     make([]jsonVout, len(t.Vout)),
+	}
+	for i := range t.Vin {
+		vin := &t.Vin[i]
+		rawVin := &raw.Vin[i]
+		rawVin.Sequence = vin.Sequence
+		if vin.Coinbase != nil {
+			rawVin.Coinbase = hex.EncodeToString(vin.Coinbase)
+			continue
+		}
+		txid := vin.PrevOut.Hash.String()
+		index := vin.PrevOut.Index
+		rawVin.Txid = &txid
+		rawVin.Vout = &index
+		rawVin.ScriptSig = &jsonScript{
+			Asm: vin.ScriptSig.Asm,
+			Hex: hex.EncodeToString(vin.ScriptSig.Hex),
+		}
+	}
+	for i := range t.Vout {
+		vout := &t.Vout[i]
+		spk := &vout.ScriptPubKey
+		rawVout := &raw.Vout[i]
+		rawVout.Value = formatValue(vout.Value)
+		rawVout.N = vout.N
+		rawVout.ScriptPubKey = jsonScriptPubKey{
+			Asm:     spk.Asm,
+			Hex:     hex.EncodeToString(spk.Hex),
+			ReqSigs: spk.ReqSigs,
+			Type:    spk.Type,
+		}
+		for _, addr := range spk.Addresses {
+			rawVout.ScriptPubKey.Addresses = append(
+				rawVout.ScriptPubKey.Addresses, addr.EncodeAddress())
+		}
+		if td := vout.TokenData; td != nil {
+			rawVout.TokenData = &jsonTokenData{
+				Category: td.Category.String(),
+				Amount:   strconv.FormatUint(td.Amount, 10),
+			}
+			if td.NFT != nil {
+				rawVout.TokenData.NFT = &jsonNFT{
+					Capability: td.NFT.Capability,
+					Commitment: hex.EncodeToString(
+						td.NFT.Commitment),
+				}
+			}
+		}
+	}
+	return json.Marshal(&raw)
+}
+
+// jsonTx is the JSON encoding of a Tx.
+type jsonTx struct {
+	Txid     string     `json:"txid"`
+	Hash     string     `json:"hash"`
+	Size     int        `json:"size"`
+	Version  int32      `json:"version"`
+	LockTime uint32     `json:"locktime"`
+	Vin      []jsonVin  `json:"vin"`
+	Vout     []jsonVout `json:"vout"`
+}
+
+// jsonVin is the JSON encoding of a Vin.
+type jsonVin struct {
+	Coinbase  string      `json:"coinbase,omitempty"`
+	Txid      *string     `json:"txid,omitempty"`
+	Vout      *uint32     `json:"vout,omitempty"`
+	ScriptSig *jsonScript `json:"scriptSig,omitempty"`
+	Sequence  uint32      `json:"sequence"`
+}
+
+// jsonScript is the JSON encoding of a Script.
+type jsonScript struct {
+	Asm string `json:"asm"`
+	Hex string `json:"hex"`
+}
+
+// jsonVout is the JSON encoding of a Vout.
+type jsonVout struct {
+	Value        json.Number      `json:"value"`
+	N            uint32           `json:"n"`
+	ScriptPubKey jsonScriptPubKey `json:"scriptPubKey"`
+	TokenData    *jsonTokenData   `json:"tokenData,omitempty"`
+}
+
+// jsonScriptPubKey is the JSON encoding of a ScriptPubKey.
+type jsonScriptPubKey struct {
+	Asm       string   `json:"asm"`
+	Hex       string   `json:"hex"`
+	ReqSigs   int      `json:"reqSigs,omitempty"`
+	Type      string   `json:"type"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// jsonTokenData is the JSON encoding of a TokenData.
+type jsonTokenData struct {
+	Category string   `json:"category"`
+	Amount   string   `json:"amount"`
+	NFT      *jsonNFT `json:"nft,omitempty"`
+}
+
+// jsonNFT is the JSON encoding of an NFT.
+type jsonNFT struct {
+	Capability string `json:"capability"`
+	Commitment string `json:"commitment"`
+}
+
+// tx converts the JSON encoding of a transaction to a Tx.  Addresses are only
+// decoded when params is not nil.
+func (raw *jsonTx) tx(params *chaincfg.Params) (*Tx, error) {
+	t := &Tx{
+		Size:     raw.Size,
+		Version:  raw.Version,
+		LockTime: raw.LockTime,
+		Vin:      make([]Vin, len(raw.Vin)),
+		Vout:     make([]Vout, len(raw.Vout)),
+	}
+	if err := decodeHash(&t.Txid, raw.Txid); err != nil {
+		return nil, fmt.Errorf("txid: %v", err)
+	}
+	if err := decodeHash(&t.Hash, raw.Hash); err != nil {
+		return nil, fmt.Errorf("hash: %v", err)
+	}
+
+	for i := range raw.Vin {
+		rawVin := &raw.Vin[i]
+		vin := &t.Vin[i]
+		vin.Sequence = rawVin.Sequence
+		if rawVin.Txid == nil {
+			coinbase, err := hex.DecodeString(rawVin.Coinbase)
+			if err != nil {
+				return nil, fmt.Errorf("input %d: coinbase: %v",
+					i, err)
+			}
+			vin.Coinbase = coinbase
+			continue
+		}
+		if err := decodeHash(&vin.PrevOut.Hash, *rawVin.Txid); err != nil {
+			return nil, fmt.Errorf("input %d: txid: %v", i, err)
+		}
+		if rawVin.Vout != nil {
+			vin.PrevOut.Index = *rawVin.Vout
+		}
+		if rawVin.ScriptSig != nil {
+			script, err := decodeScript(rawVin.ScriptSig)
+			if err != nil {
+				return nil, fmt.Errorf("input %d: scriptSig: %v",
+					i, err)
+			}
+			vin.ScriptSig = script
+		}
+	}
+
+	for i := range raw.Vout {
+		rawVout := &raw.Vout[i]
+		vout := &t.Vout[i]
+		value, err := parseValue(rawVout.Value)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %v", i, err)
+		}
+		vout.Value = value
+		vout.N = rawVout.N
+
+		rawSpk := &rawVout.ScriptPubKey
+		script, err := decodeScript(&jsonScript{
+			Asm: rawSpk.Asm,
+			Hex: rawSpk.Hex,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("output %d: scriptPubKey: %v", i,
+				err)
+		}
+		vout.ScriptPubKey = ScriptPubKey{
+			Script:  script,
+			ReqSigs: rawSpk.ReqSigs,
+			Type:    rawSpk.Type,
+		}
+		if params != nil {
+			for _, encoded := range rawSpk.Addresses {
+				addr, err := bchutil.DecodeAddress(encoded, params)
+				if err != nil {
+					return nil, fmt.Errorf("output %d: "+
+						"address %s: %v", i, encoded, err)
+				}
+				vout.ScriptPubKey.Addresses = append(
+					vout.ScriptPubKey.Addresses, addr)
+			}
+		}
+
+		if rawTd := rawVout.TokenData; rawTd != nil {
+			td := &TokenData{}
+			if err := decodeHash(&td.Category, rawTd.Category); err != nil {
+				return nil, fmt.Errorf("output %d: token "+
+					"category: %v", i, err)
+			}
+			td.Amount, err = strconv.ParseUint(rawTd.Amount, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("output %d: token "+
+					"amount: %v", i, err)
+			}
+			if rawTd.NFT != nil {
+				commitment, err := hex.DecodeString(
+					rawTd.NFT.Commitment)
+				if err != nil {
+					return nil, fmt.Errorf("output %d: NFT "+
+						"commitment: %v", i, err)
+				}
+				td.NFT = &NFT{
+					Capability: rawTd.NFT.Capability,
+					Commitment: commitment,
+				}
+			}
+			vout.TokenData = td
+		}
+	}
+	return t, nil
+}
+
+// newScript returns script along with its disassembly.  Scripts which fail
+// to parse are disassembled up to the failure, followed by "[error]".
+func newScript(script []byte) Script {
+	asm, _ := txscript.DisasmString(script)
+	return Script{Asm: asm, Hex: script}
+}
+
+// decodeScript decodes the hex encoding of a script.
+func decodeScript(raw *jsonScript) (Script, error) {
+	script, err := hex.DecodeString(raw.Hex)
+	if err != nil {
+		return Script{}, err
+	}
+	return Script{Asm: raw.Asm, Hex: script}, nil
+}
+
+// decodeHash decodes a byte-reversed hex hash into hash.  Empty strings leave
+// hash unchanged.
+func decodeHash(hash *chainhash.Hash, s string) error {
+	if s == "" {
+		return nil
+	}
+	return chainhash.Decode(hash, s)
+}
+
+// scriptType returns the name of a script class as reported by full nodes.
+func scriptType(class txscript.ScriptClass) string {
+	if class == txscript.ScriptHash32Ty {
+		return txscript.ScriptHashTy.String()
+	}
+	return class.String()
+}
+
+// newTokenData returns the TokenData of an output.
+func newTokenData(tokenData *wire.TokenData) *TokenData {
+	td := &TokenData{
+		Category: chainhash.Hash(tokenData.CategoryID),
+		Amount:   tokenData.Amount,
+	}
+	if tokenData.HasNFT() {
+		var capability string
+		switch tokenData.GetCapability() {
+		case wire.MUTABLE:
+			capability = CapabilityMutable
+		case wire.MINTING:
+			capability = CapabilityMinting
+		default:
+			capability = CapabilityNone
+		}
+		td.NFT = &NFT{
+			Capability: capability,
+			Commitment: tokenData.Commitment,
+		}
+	}
+	return td
+}
+
+// wireTokenData returns the wire encoding of td.
+func (td *TokenData) wireTokenData() (*wire.TokenData, error) {
+	tokenData := &wire.TokenData{
+		CategoryID: td.Category,
+		Amount:     td.Amount,
+	}
+	if td.Amount > 0 {
+		tokenData.BitField |= wire.HAS_AMOUNT
+	}
+	if td.NFT != nil {
+		tokenData.BitField |= wire.HAS_NFT
+		switch td.NFT.Capability {
+		case CapabilityNone:
+		case CapabilityMutable:
+			tokenData.BitField |= wire.MUTABLE
+		case CapabilityMinting:
+			tokenData.BitField |= wire.MINTING
+		default:
+			return nil, fmt.Errorf("%v: %q", ErrBadCapability,
+				td.NFT.Capability)
+		}
+		if len(td.NFT.Commitment) > 0 {
+			tokenData.BitField |= wire.HAS_COMMITMENT_LENGTH
+			tokenData.Commitment = td.NFT.Commitment
+		}
+	}
+	return tokenData, nil
+}
+
+// formatValue returns the decimal encoding of an amount in bitcoin cash with
+// eight decimal places, as full nodes write output values.
+func formatValue(a bchutil.Amount) json.Number {
+	sign := ""
+	n := int64(a)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	return json.Number(fmt.Sprintf("%s%d.%08d", sign,
+		n/bchutil.SatoshiPerBitcoin, n%bchutil.SatoshiPerBitcoin))
+}
+
+// parseValue parses a decimal amount in bitcoin cash exactly.
+func parseValue(n json.Number) (bchutil.Amount, error) {
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return 0, fmt.Errorf("%v: %q", ErrBadValue, n)
+	}
+	r.Mul(r, big.NewRat(bchutil.SatoshiPerBitcoin, 1))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("%v: %q", ErrBadValue, n)
+	}
+	return bchutil.Amount(r.Num().Int64()), nil
+}
+
+// isCoinbaseTx returns whether tx is a coinbase transaction.
+func isCoinbaseTx(tx *wire.MsgTx) bool {
+	if len(tx.TxIn) != 1 {
+		return false
+	}
+	prevOut := &tx.TxIn[0].PreviousOutPoint
+	return prevOut.Index == wire.MaxPrevOutIndex &&
+		prevOut.Hash == chainhash.Hash{}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txjson_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/txjson"
+)
+
+// tokenTx returns a transaction spending a previous output to a
+// pay-to-pubkey-hash output carrying fungible tokens and an NFT, and a null
+// data output.
+func tokenTx() *wire.MsgTx {
+	prevHash, _ := chainhash.NewHashFromStr("b5f2b4bd7c69ac42da68b3f09" +
+		"a1aa1e5bcf59de2bf3e5fa26b0f1d0cb7a3f4ae")
+	category, _ := chainhash.NewHashFromStr("0afd5f9ad130d043f627fad3b4" +
+		"22ab17cfb5ff0fc69e4782eea7bd0853948428")
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(prevHash, 1),
+		[]byte{0x02, 0xca, 0xfe, 0x51}))
+	tx.TxIn[0].Sequence = 0xfffffffe
+	pkScript := append([]byte{0x76, 0xa9, 0x14},
+		bytes.Repeat([]byte{0x11}, 20)...)
+	pkScript = append(pkScript, 0x88, 0xac)
+	tx.AddTxOut(wire.NewTxOut(1000, pkScript, wire.TokenData{
+		CategoryID: *category,
+		Amount:     12345,
+		Commitment: []byte{0x01, 0x02},
+		BitField: wire.HAS_AMOUNT | wire.HAS_NFT |
+			wire.HAS_COMMITMENT_LENGTH | wire.MUTABLE,
+	}))
+	tx.AddTxOut(wire.NewTxOut(0, []byte{0x6a, 0x02, 0xbe, 0xef},
+		wire.TokenData{}))
+	tx.LockTime = 800000
+	return tx
+}
+
+// TestMarshal ensures transactions are encoded in the decoderawtransaction
+// JSON form and decode back to the same transaction.
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *wire.MsgTx
+		json string
+	}{
+		{
+			name: "genesis coinbase",
+			tx:   chaincfg.MainNetParams.GenesisBlock.Transactions[0],
+			json: `{"txid":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",` +
+				`"hash":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",` +
+				`"size":204,"version":1,"locktime":0,"vin":[{"coinbase":"04ffff001d01044554` +
+				`68652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e2062` +
+				`72696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73",` +
+				`"sequence":4294967295}],"vout":[{"value":50.00000000,"n":0,"scriptPubKey":` +
+				`{"asm":"04678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb64` +
+				`9f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5f OP_CHECKSIG",` +
+				`"hex":"4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb6` +
+				`49f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac",` +
+				`"reqSigs":1,"type":"pubkey","addresses":` +
+				`["qp3wjpa3tjlj042z2wv7hahsldgwhwy0rq9sywjpyy"]}}]}`,
+		},
+		{
+			name: "tokens",
+			tx:   tokenTx(),
+			json: `{"txid":"9fe19c15c72f09d3efa2d6a98b160e4671fea028c4a43bea6d874b07a30e0709",` +
+				`"hash":"9fe19c15c72f09d3efa2d6a98b160e4671fea028c4a43bea6d874b07a30e0709",` +
+				`"size":142,"version":2,"locktime":800000,` +
+				`"vin":[{"txid":"b5f2b4bd7c69ac42da68b3f09a1aa1e5bcf59de2bf3e5fa26b0f1d0cb7a3f4ae",` +
+				`"vout":1,"scriptSig":{"asm":"cafe 1","hex":"02cafe51"},"sequence":4294967294}],` +
+				`"vout":[{"value":0.00001000,"n":0,"scriptPubKey":{"asm":"OP_DUP OP_HASH160 ` +
+				`1111111111111111111111111111111111111111 OP_EQUALVERIFY OP_CHECKSIG",` +
+				`"hex":"76a914111111111111111111111111111111111111111188ac","reqSigs":1,` +
+				`"type":"pubkeyhash","addresses":["qqg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zye3kwllue"]},` +
+				`"tokenData":{"category":"0afd5f9ad130d043f627fad3b422ab17cfb5ff0fc69e4782eea7bd0853948428",` +
+				`"amount":"12345","nft":{"capability":"mutable","commitment":"0102"}}},` +
+				`{"value":0.00000000,"n":1,"scriptPubKey":{"asm":"OP_RETURN beef",` +
+				`"hex":"6a02beef","type":"nulldata"}}]}`,
+		},
+	}
+
+	params := &chaincfg.MainNetParams
+	for _, test := range tests {
+		tx := bchutil.NewTx(test.tx)
+		got, err := txjson.Marshal(tx, params)
+		if err != nil {
+			t.Errorf("%s: Marshal: unexpected error: %v", test.name, err)
+			continue
+		}
+		if string(got) != test.json {
+			t.Errorf("%s: Marshal:\n got: %s\nwant: %s", test.name,
+				got, test.json)
+			continue
+		}
+
+		decoded, err := txjson.Unmarshal(got, params)
+		if err != nil {
+			t.Errorf("%s: Unmarshal: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, txjson.NewTx(tx, params)) {
+			t.Errorf("%s: Unmarshal: got %+v, want %+v", test.name,
+				decoded, txjson.NewTx(tx, params))
+		}
+		msgTx, err := txjson.UnmarshalMsgTx(got)
+		if err != nil {
+			t.Errorf("%s: UnmarshalMsgTx: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+		var wantBuf, gotBuf bytes.Buffer
+		test.tx.Serialize(&wantBuf)
+		msgTx.Serialize(&gotBuf)
+		if !bytes.Equal(gotBuf.Bytes(), wantBuf.Bytes()) {
+			t.Errorf("%s: UnmarshalMsgTx: got %x, want %x", test.name,
+				gotBuf.Bytes(), wantBuf.Bytes())
+		}
+	}
+}
+
+// TestUnmarshalErrors ensures malformed transactions are rejected.
+func TestUnmarshalErrors(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	valid, err := txjson.Marshal(bchutil.NewTx(tokenTx()), params)
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		err  error
+	}{
+		{
+			name: "txid mismatch",
+			old:  `"locktime":800000`,
+			new:  `"locktime":800001`,
+			err:  txjson.ErrTxidMismatch,
+		},
+		{
+			name: "fractional satoshi",
+			old:  `"value":0.00001000`,
+			new:  `"value":0.000010001`,
+			err:  txjson.ErrBadValue,
+		},
+		{
+			name: "bad capability",
+			old:  `"capability":"mutable"`,
+			new:  `"capability":"burnable"`,
+			err:  txjson.ErrBadCapability,
+		},
+	}
+
+	for _, test := range tests {
+		data := strings.Replace(string(valid), test.old, test.new, 1)
+		_, err := txjson.UnmarshalMsgTx([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), test.err.Error()) {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+
+	// Values may be written in any decimal form, but addresses must be
+	// for the network.
+	data := strings.Replace(string(valid), `"value":0.00001000`,
+		`"value":1e-5`, 1)
+	if _, err := txjson.UnmarshalMsgTx([]byte(data)); err != nil {
+		t.Errorf("exponent value: unexpected error: %v", err)
+	}
+	if _, err := txjson.Unmarshal(valid, &chaincfg.TestNet3Params); err == nil {
+		t.Errorf("Unmarshal: expected error for mainnet address on testnet")
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txsign provides signing of bitcoin cash transaction inputs with keys
+held as WIFs or hierarchical deterministic extended keys.
+
+# Overview
+
+A Signer holds a set of private keys and redeem scripts.  Given a transaction
+and the outputs spent by each of its inputs, it computes the BIP 143 style
+SIGHASH_FORKID digest of every input it holds keys for and produces Schnorr or
+ECDSA signatures for pay-to-pubkey-hash, pay-to-pubkey and multisig outputs,
+either bare or wrapped in pay-to-script-hash.
+
+	signer := txsign.NewSigner()
+	signer.AddWIF(wif)
+	if err := signer.AddExtendedKey(extKey); err != nil {
+		return err
+	}
+	complete, err := signer.Sign(tx, prevOuts)
+
+# Partial Signing
+
+Signatures already present in a multisig input are kept and new signatures are
+merged with them, so a transaction may be passed between parties who each sign
+with their own Signer.  The redeem script of a partially signed
+pay-to-script-hash input is recovered from its signature script, so only the
+first party needs to know it.  Sign reports whether every input is complete,
+and Verify executes the scripts to check the result.
+
+Schnorr and ECDSA signatures cannot be mixed within a single multisig input,
+so every party must sign with the same SignatureType.
+*/
+package txsign
@@ -0,0 +1,212 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txsign
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/gcash/bchd/bchec"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+)
+
+// schnorrSigSize is the size of a Schnorr signature with its hash type byte.
+const schnorrSigSize = 64 + 1
+
+// signMultiSig returns the signature script spending a multisig output script
+// with the signatures in existing merged with new signatures from the signer.
+// The returned script is nil if there are no signatures at all.
+//
+// In ECDSA mode the signature script is the OP_0 dummy followed by the
+// signatures in public key order.  In Schnorr mode the dummy is instead a
+// bitfield with a bit set for each public key which has a signature.
+func (s *Signer) signMultiSig(tx *wire.MsgTx, idx int, subScript []byte,
+	amt int64, sigHashes *txscript.TxSigHashes,
+	existing [][]byte) ([]byte, bool, error) {
+
+	pubKeys, err := txscript.PushedData(subScript)
+	if err != nil {
+		return nil, false, err
+	}
+	_, numRequired, err := txscript.CalcMultiSigStats(subScript)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sigs, existingType, err := existingMultiSigs(tx, idx, subScript, amt,
+		sigHashes, pubKeys, existing)
+	if err != nil {
+		return nil, false, err
+	}
+	numSigs := 0
+	for _, sig := range sigs {
+		if sig != nil {
+			numSigs++
+		}
+	}
+	if numSigs > 0 && existingType != s.SignatureType {
+		return nil, false, ErrMixedSignatureTypes
+	}
+
+	for i, pubKey := range pubKeys {
+		if numSigs >= numRequired {
+			break
+		}
+		if sigs[i] != nil {
+			continue
+		}
+		key := s.lookupPubKey(pubKey)
+		if key == nil {
+			continue
+		}
+		sigs[i], err = s.sign(tx, idx, subScript, amt, sigHashes, key)
+		if err != nil {
+			return nil, false, err
+		}
+		numSigs++
+	}
+	if numSigs == 0 {
+		return nil, false, nil
+	}
+
+	builder := txscript.NewScriptBuilder()
+	if s.SignatureType == Schnorr {
+		var checkBits uint32
+		for i, sig := range sigs {
+			if sig != nil {
+				checkBits |= 1 << uint(i)
+			}
+		}
+		var dummy [4]byte
+		binary.LittleEndian.PutUint32(dummy[:], checkBits)
+		builder.AddData(dummy[:(len(pubKeys)+7)/8])
+	} else {
+		builder.AddOp(txscript.OP_0)
+	}
+	for _, sig := range sigs {
+		if sig != nil {
+			builder.AddData(sig)
+		}
+	}
+	script, err := builder.Script()
+	return script, numSigs >= numRequired, err
+}
+
+// existingMultiSigs matches the signatures of an existing multisig signature
+// script to the public keys they belong to.  The returned slice holds the
+// signature for each public key, or nil.  ECDSA signatures which do not verify
+// against any public key are dropped.
+func existingMultiSigs(tx *wire.MsgTx, idx int, subScript []byte, amt int64,
+	sigHashes *txscript.TxSigHashes, pubKeys,
+	existing [][]byte) ([][]byte, SignatureType, error) {
+
+	sigs := make([][]byte, len(pubKeys))
+	if len(existing) < 2 {
+		return sigs, ECDSA, nil
+	}
+	dummy, existingSigs := existing[0], existing[1:]
+
+	// A non-empty dummy is the Schnorr multisig bitfield.
+	if len(dummy) > 0 {
+		var padded [4]byte
+		copy(padded[:], dummy)
+		checkBits := binary.LittleEndian.Uint32(padded[:])
+		for i := range pubKeys {
+			if checkBits&(1<<uint(i)) == 0 || len(existingSigs) == 0 {
+				continue
+			}
+			if len(existingSigs[0]) != schnorrSigSize {
+				return nil, Schnorr, errors.New("invalid Schnorr " +
+					"multisig signature")
+			}
+			sigs[i], existingSigs = existingSigs[0], existingSigs[1:]
+		}
+		return sigs, Schnorr, nil
+	}
+
+	// ECDSA signatures must appear in public key order, so each signature
+	// is matched against the keys following the previous match.
+	keyIdx := 0
+	for _, sig := range existingSigs {
+		if len(sig) == 0 {
+			continue
+		}
+		hashType := txscript.SigHashType(sig[len(sig)-1])
+		parsedSig, err := bchec.ParseDERSignature(sig[:len(sig)-1],
+			bchec.S256())
+		if err != nil {
+			continue
+		}
+		hash, _, err := txscript.CalcSignatureHash(subScript, sigHashes,
+			hashType, tx, idx, amt, true)
+		if err != nil {
+			return nil, ECDSA, err
+		}
+		for ; keyIdx < len(pubKeys); keyIdx++ {
+			pubKey, err := bchec.ParsePubKey(pubKeys[keyIdx], bchec.S256())
+			if err != nil {
+				continue
+			}
+			if parsedSig.Verify(hash, pubKey) {
+				sigs[keyIdx] = sig
+				keyIdx++
+				break
+			}
+		}
+	}
+	return sigs, ECDSA, nil
+}
+
+// parsePushes returns the data pushed by each opcode of a push only script.
+// Small integer opcodes are returned as their minimally encoded values so
+// that a Schnorr multisig bitfield pushed as OP_1 through OP_16 is preserved.
+func parsePushes(script []byte) ([][]byte, error) {
+	var pushes [][]byte
+	for len(script) > 0 {
+		op := script[0]
+		script = script[1:]
+
+		var size int
+		switch {
+		case op == txscript.OP_0:
+			pushes = append(pushes, nil)
+			continue
+
+		case op >= txscript.OP_DATA_1 && op <= txscript.OP_DATA_75:
+			size = int(op)
+
+		case op == txscript.OP_PUSHDATA1 && len(script) >= 1:
+			size = int(script[0])
+			script = script[1:]
+
+		case op == txscript.OP_PUSHDATA2 && len(script) >= 2:
+			size = int(binary.LittleEndian.Uint16(script))
+			script = script[2:]
+
+		case op == txscript.OP_PUSHDATA4 && len(script) >= 4:
+			size = int(binary.LittleEndian.Uint32(script))
+			script = script[4:]
+
+		case op == txscript.OP_1NEGATE:
+			pushes = append(pushes, []byte{0x81})
+			continue
+
+		case op >= txscript.OP_1 && op <= txscript.OP_16:
+			pushes = append(pushes, []byte{op - txscript.OP_1 + 1})
+			continue
+
+		default:
+			return nil, errors.New("signature script is not push only")
+		}
+
+		if size < 0 || size > len(script) {
+			return nil, errors.New("malformed push in signature script")
+		}
+		pushes = append(pushes, script[:size])
+		script = script[size:]
+	}
+	return pushes, nil
+}
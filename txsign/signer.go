@@ -0,0 +1,411 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txsign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gcash/bchd/bchec"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/hdkeychain"
+)
+
+var (
+	// ErrPrevOutCount describes an error where the number of previous
+	// outputs passed to the signer does not match the number of inputs of
+	// the transaction.
+	ErrPrevOutCount = errors.New("number of previous outputs does not " +
+		"match number of transaction inputs")
+
+	// ErrUnsupportedScript describes an error where an input spends an
+	// output script which the signer does not know how to sign.
+	ErrUnsupportedScript = errors.New("unsupported script type")
+
+	// ErrMixedSignatureTypes describes an error where a multisig input is
+	// already partially signed with a different signature type than the
+	// one requested.  Schnorr and ECDSA signatures cannot be mixed within
+	// a single OP_CHECKMULTISIG.
+	ErrMixedSignatureTypes = errors.New("multisig input is already signed " +
+		"with a different signature type")
+)
+
+// SignatureType selects the signature algorithm used by a Signer.
+type SignatureType uint8
+
+const (
+	// Schnorr produces 64 byte BCH Schnorr signatures.
+	Schnorr SignatureType = iota
+
+	// ECDSA produces DER encoded ECDSA signatures.
+	ECDSA
+)
+
+// String returns the SignatureType as a human-readable name.
+func (t SignatureType) String() string {
+	switch t {
+	case Schnorr:
+		return "Schnorr"
+	case ECDSA:
+		return "ECDSA"
+	}
+	return fmt.Sprintf("Unknown SignatureType (%d)", uint8(t))
+}
+
+// DefaultHashType is the signature hash type used when a Signer does not
+// specify one.
+const DefaultHashType = txscript.SigHashAll | txscript.SigHashForkID
+
+// signingKey is a private key along with the serialization of its public key
+// which appears in scripts.
+type signingKey struct {
+	privKey *bchec.PrivateKey
+	pubKey  []byte
+}
+
+// Signer signs transaction inputs with a set of private keys and redeem
+// scripts.  The zero value is not usable; create one with NewSigner.
+//
+// Inputs spending pay-to-pubkey-hash, pay-to-pubkey and bare multisig outputs
+// are supported, as are pay-to-script-hash outputs (both 20 and 32 byte
+// hashes) whose redeem script is one of those types.
+type Signer struct {
+	// SignatureType selects the signature algorithm.  The zero value
+	// produces Schnorr signatures.
+	SignatureType SignatureType
+
+	// HashType is the signature hash type to sign with.  SigHashForkID is
+	// always added.  When zero, DefaultHashType is used.
+	HashType txscript.SigHashType
+
+	keys    map[[20]byte]*signingKey
+	scripts map[string][]byte
+}
+
+// NewSigner returns a Signer with no keys which produces Schnorr signatures
+// with DefaultHashType.
+func NewSigner() *Signer {
+	return &Signer{
+		keys:    make(map[[20]byte]*signingKey),
+		scripts: make(map[string][]byte),
+	}
+}
+
+// AddPrivKey adds a private key to the signer.  The compressed flag selects
+// which serialization of the public key is expected in pay-to-pubkey-hash
+// output scripts.
+func (s *Signer) AddPrivKey(privKey *bchec.PrivateKey, compressed bool) {
+	pub := privKey.PubKey()
+	var pubKey []byte
+	if compressed {
+		pubKey = pub.SerializeCompressed()
+	} else {
+		pubKey = pub.SerializeUncompressed()
+	}
+
+	var hash [20]byte
+	copy(hash[:], bchutil.Hash160(pubKey))
+	s.keys[hash] = &signingKey{privKey: privKey, pubKey: pubKey}
+}
+
+// AddWIF adds the private key encoded by wif to the signer.
+func (s *Signer) AddWIF(wif *bchutil.WIF) {
+	s.AddPrivKey(wif.PrivKey, wif.CompressPubKey)
+}
+
+// AddExtendedKey adds the private key of an extended key to the signer.
+// hdkeychain.ErrNotPrivExtKey is returned if key is a public extended key.
+func (s *Signer) AddExtendedKey(key *hdkeychain.ExtendedKey) error {
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return err
+	}
+	s.AddPrivKey(privKey, true)
+	return nil
+}
+
+// AddRedeemScript adds a redeem script to the signer so that
+// pay-to-script-hash outputs paying to it can be signed.
+func (s *Signer) AddRedeemScript(script []byte) {
+	s.scripts[string(bchutil.Hash160(script))] = script
+	s.scripts[string(chainhash.DoubleHashB(script))] = script
+}
+
+// hashType returns the signature hash type to sign with.
+func (s *Signer) hashType() txscript.SigHashType {
+	if s.HashType == 0 {
+		return DefaultHashType
+	}
+	return s.HashType | txscript.SigHashForkID
+}
+
+// Sign signs every input of tx which the signer holds keys for.  prevOuts
+// must hold the output spent by each input, in input order; the value,
+// output script and any token data of each are committed to by the
+// signatures.
+//
+// Inputs which cannot be signed because the signer lacks the keys or redeem
+// script are left unchanged, and signatures already present in multisig
+// inputs are kept, which allows several parties to sign a transaction in
+// turn.  The returned bool reports whether every input is fully signed,
+// including inputs signed earlier by other parties, whose existing signature
+// scripts are verified.
+func (s *Signer) Sign(tx *wire.MsgTx, prevOuts []*wire.TxOut) (bool, error) {
+	if len(prevOuts) != len(tx.TxIn) {
+		return false, ErrPrevOutCount
+	}
+
+	sigHashes, utxoCache := newSigHashes(tx, prevOuts)
+	complete := true
+	for i, txIn := range tx.TxIn {
+		inputComplete, err := s.signInput(tx, i, prevOuts[i], sigHashes)
+		if err != nil {
+			return false, fmt.Errorf("input %d: %v", i, err)
+		}
+
+		// An input the signer could not complete may already have been
+		// signed by another party.
+		if !inputComplete && len(txIn.SignatureScript) != 0 {
+			inputComplete = verifyInput(tx, i, prevOuts[i], sigHashes,
+				utxoCache) == nil
+		}
+		complete = complete && inputComplete
+	}
+	return complete, nil
+}
+
+// Verify executes the signature script of every input of tx against the
+// output it spends with the standard script verification flags and returns
+// the first failure.
+func Verify(tx *wire.MsgTx, prevOuts []*wire.TxOut) error {
+	if len(prevOuts) != len(tx.TxIn) {
+		return ErrPrevOutCount
+	}
+
+	sigHashes, utxoCache := newSigHashes(tx, prevOuts)
+	for i, prevOut := range prevOuts {
+		err := verifyInput(tx, i, prevOut, sigHashes, utxoCache)
+		if err != nil {
+			return fmt.Errorf("input %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// verifyInput executes the signature script of input idx of tx against the
+// output it spends with the standard script verification flags.
+func verifyInput(tx *wire.MsgTx, idx int, prevOut *wire.TxOut,
+	sigHashes *txscript.TxSigHashes, utxoCache *txscript.UtxoCache) error {
+
+	vm, err := txscript.NewEngine(prevOut.PkScript, tx, idx,
+		txscript.StandardVerifyFlags, nil, sigHashes, utxoCache,
+		prevOut.Value)
+	if err != nil {
+		return err
+	}
+	return vm.Execute()
+}
+
+// newSigHashes returns the signature hash midstate for tx, including the
+// hash of the spent outputs and their token data, along with the cache of
+// spent outputs it was computed from.
+func newSigHashes(tx *wire.MsgTx,
+	prevOuts []*wire.TxOut) (*txscript.TxSigHashes, *txscript.UtxoCache) {
+
+	utxoCache := txscript.NewUtxoCache()
+	for i, prevOut := range prevOuts {
+		utxoCache.AddEntry(i, *prevOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(tx)
+	sigHashes.AddTxSigHashUtxoFromUtxoCache(tx, utxoCache)
+	return sigHashes, utxoCache
+}
+
+// signInput signs input idx of tx spending prevOut and reports whether the
+// input is fully signed afterwards.
+func (s *Signer) signInput(tx *wire.MsgTx, idx int, prevOut *wire.TxOut,
+	sigHashes *txscript.TxSigHashes) (bool, error) {
+
+	txIn := tx.TxIn[idx]
+	subScript := prevOut.PkScript
+	var redeemScript []byte
+
+	// Existing pushes are only needed to merge multisig signatures, so a
+	// malformed signature script is simply replaced.
+	existing, err := parsePushes(txIn.SignatureScript)
+	if err != nil {
+		existing = nil
+	}
+
+	class := txscript.GetScriptClass(subScript)
+	if class == txscript.ScriptHashTy || class == txscript.ScriptHash32Ty {
+		// The script hash is the only push in the output script.
+		pushes, err := txscript.PushedData(subScript)
+		if err != nil {
+			return false, err
+		}
+		scriptHash := pushes[0]
+		redeemScript = s.scripts[string(scriptHash)]
+
+		// The redeem script is the final push of a partially signed
+		// input, which allows signing without knowing it up front.
+		if len(existing) > 0 {
+			last := existing[len(existing)-1]
+			existing = existing[:len(existing)-1]
+			if redeemScript == nil && hashMatches(last, scriptHash) {
+				redeemScript = last
+			}
+		}
+		if redeemScript == nil {
+			return false, nil
+		}
+		subScript = redeemScript
+		class = txscript.GetScriptClass(subScript)
+	}
+
+	var (
+		sigScript []byte
+		complete  bool
+	)
+	switch class {
+	case txscript.PubKeyHashTy:
+		sigScript, complete, err = s.signPubKeyHash(tx, idx, subScript,
+			prevOut.Value, sigHashes)
+
+	case txscript.PubKeyTy:
+		sigScript, complete, err = s.signPubKey(tx, idx, subScript,
+			prevOut.Value, sigHashes)
+
+	case txscript.MultiSigTy:
+		sigScript, complete, err = s.signMultiSig(tx, idx, subScript,
+			prevOut.Value, sigHashes, existing)
+
+	default:
+		return false, ErrUnsupportedScript
+	}
+	if err != nil || sigScript == nil {
+		return false, err
+	}
+
+	if redeemScript != nil {
+		sigScript, err = txscript.NewScriptBuilder().AddOps(sigScript).
+			AddData(redeemScript).Script()
+		if err != nil {
+			return false, err
+		}
+	}
+	txIn.SignatureScript = sigScript
+	return complete, nil
+}
+
+// signPubKeyHash returns the signature script spending a pay-to-pubkey-hash
+// output script, or nil if the signer does not hold the key.
+func (s *Signer) signPubKeyHash(tx *wire.MsgTx, idx int, subScript []byte,
+	amt int64, sigHashes *txscript.TxSigHashes) ([]byte, bool, error) {
+
+	var hash [20]byte
+	copy(hash[:], subScript[3:23])
+	key := s.keys[hash]
+	if key == nil {
+		return nil, false, nil
+	}
+
+	sig, err := s.sign(tx, idx, subScript, amt, sigHashes, key.privKey)
+	if err != nil {
+		return nil, false, err
+	}
+	script, err := txscript.NewScriptBuilder().AddData(sig).
+		AddData(key.pubKey).Script()
+	return script, true, err
+}
+
+// signPubKey returns the signature script spending a pay-to-pubkey output
+// script, or nil if the signer does not hold the key.
+func (s *Signer) signPubKey(tx *wire.MsgTx, idx int, subScript []byte,
+	amt int64, sigHashes *txscript.TxSigHashes) ([]byte, bool, error) {
+
+	pushes, err := txscript.PushedData(subScript)
+	if err != nil {
+		return nil, false, err
+	}
+	key := s.lookupPubKey(pushes[0])
+	if key == nil {
+		return nil, false, nil
+	}
+
+	sig, err := s.sign(tx, idx, subScript, amt, sigHashes, key)
+	if err != nil {
+		return nil, false, err
+	}
+	script, err := txscript.NewScriptBuilder().AddData(sig).Script()
+	return script, true, err
+}
+
+// lookupPubKey returns the private key for a serialized public key, in either
+// compressed or uncompressed form, or nil if the signer does not hold it.
+func (s *Signer) lookupPubKey(pubKey []byte) *bchec.PrivateKey {
+	var hash [20]byte
+	copy(hash[:], bchutil.Hash160(pubKey))
+	if key := s.keys[hash]; key != nil {
+		return key.privKey
+	}
+
+	// The key may have been added with the other serialization.
+	pub, err := bchec.ParsePubKey(pubKey, bchec.S256())
+	if err != nil {
+		return nil
+	}
+	other := pub.SerializeCompressed()
+	if bchec.IsCompressedPubKey(pubKey) {
+		other = pub.SerializeUncompressed()
+	}
+	copy(hash[:], bchutil.Hash160(other))
+	if key := s.keys[hash]; key != nil {
+		return key.privKey
+	}
+	return nil
+}
+
+// sign returns the signature of input idx with the signer's signature type
+// and hash type, with the hash type byte appended.
+func (s *Signer) sign(tx *wire.MsgTx, idx int, subScript []byte, amt int64,
+	sigHashes *txscript.TxSigHashes, key *bchec.PrivateKey) ([]byte, error) {
+
+	hashType := s.hashType()
+	hash, _, err := txscript.CalcSignatureHash(subScript, sigHashes,
+		hashType, tx, idx, amt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig *bchec.Signature
+	switch s.SignatureType {
+	case Schnorr:
+		sig, err = key.SignSchnorr(hash)
+	case ECDSA:
+		sig, err = key.SignECDSA(hash)
+	default:
+		return nil, fmt.Errorf("unknown signature type %v",
+			s.SignatureType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(sig.Serialize(), byte(hashType)), nil
+}
+
+// hashMatches returns whether script hashes to the 20 or 32 byte script hash.
+func hashMatches(script, hash []byte) bool {
+	switch len(hash) {
+	case 20:
+		return string(bchutil.Hash160(script)) == string(hash)
+	case 32:
+		return string(chainhash.DoubleHashB(script)) == string(hash)
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2026 The gcash developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txsign_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gcash/bchd/bchec"
+	"github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchd/chaincfg/chainhash"
+	"github.com/gcash/bchd/txscript"
+	"github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil"
+	"github.com/gcash/bchutil/hdkeychain"
+	"github.com/gcash/bchutil/txsign"
+)
+
+// newKey returns a deterministic private key for tests.
+func newKey(b byte) *bchec.PrivateKey {
+	key, _ := bchec.PrivKeyFromBytes(bchec.S256(), bytes.Repeat([]byte{b}, 32))
+	return key
+}
+
+// newWIF returns a deterministic WIF for tests.
+func newWIF(t *testing.T, b byte, compress bool) *bchutil.WIF {
+	wif, err := bchutil.NewWIF(newKey(b), &chaincfg.MainNetParams, compress)
+	if err != nil {
+		t.Fatalf("NewWIF: unexpected error: %v", err)
+	}
+	return wif
+}
+
+// payToScript returns the output script paying to addr.
+func payToScript(t *testing.T, addr bchutil.Address) []byte {
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	return script
+}
+
+// multiSigScript returns a multisig script requiring nRequired signatures
+// from the compressed public keys of keys.
+func multiSigScript(t *testing.T, nRequired int, keys ...*bchec.PrivateKey) []byte {
+	addrs := make([]*bchutil.AddressPubKey, len(keys))
+	for i, key := range keys {
+		var err error
+		addrs[i], err = bchutil.NewAddressPubKey(
+			key.PubKey().SerializeCompressed(), &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("NewAddressPubKey: unexpected error: %v", err)
+		}
+	}
+	script, err := txscript.MultiSigScript(addrs, nRequired)
+	if err != nil {
+		t.Fatalf("MultiSigScript: unexpected error: %v", err)
+	}
+	return script
+}
+
+// p2shScript returns a pay-to-script-hash output script for redeemScript.
+func p2shScript(t *testing.T, redeemScript []byte) []byte {
+	addr, err := bchutil.NewAddressScriptHash(redeemScript,
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: unexpected error: %v", err)
+	}
+	return payToScript(t, addr)
+}
+
+// p2sh32Script returns a 32 byte pay-to-script-hash output script for
+// redeemScript.
+func p2sh32Script(redeemScript []byte) []byte {
+	script := []byte{txscript.OP_HASH256, txscript.OP_DATA_32}
+	script = append(script, chainhash.DoubleHashB(redeemScript)...)
+	return append(script, txscript.OP_EQUAL)
+}
+
+// newSpendingTx returns a transaction spending one output per prevOut.
+func newSpendingTx(prevOuts []*wire.TxOut) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	var total int64
+	for i, prevOut := range prevOuts {
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(
+			&chainhash.Hash{byte(i + 1)}, uint32(i)), nil))
+		total += prevOut.Value
+	}
+	tx.AddTxOut(wire.NewTxOut(total-1000, []byte{txscript.OP_TRUE},
+		wire.TokenData{}))
+	return tx
+}
+
+// TestSign ensures the signer produces valid signature scripts for each of
+// the supported output scripts with both signature types.
+func TestSign(t *testing.T) {
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{0x01}, 32),
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: unexpected error: %v", err)
+	}
+	hdAddr, err := master.Address(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Address: unexpected error: %v", err)
+	}
+
+	compressed := newWIF(t, 0x01, true)
+	uncompressed := newWIF(t, 0x02, false)
+	compressedAddr, _ := bchutil.NewAddressPubKeyHash(bchutil.Hash160(
+		compressed.SerializePubKey()), &chaincfg.MainNetParams)
+	uncompressedAddr, _ := bchutil.NewAddressPubKeyHash(bchutil.Hash160(
+		uncompressed.SerializePubKey()), &chaincfg.MainNetParams)
+	p2pk := []byte{txscript.OP_DATA_33}
+	p2pk = append(p2pk, newKey(0x03).PubKey().SerializeCompressed()...)
+	p2pk = append(p2pk, txscript.OP_CHECKSIG)
+	multiSig := multiSigScript(t, 2, newKey(0x03), newKey(0x04), newKey(0x05))
+	unknownAddr, _ := bchutil.NewAddressPubKeyHash(bytes.Repeat(
+		[]byte{0xff}, 20), &chaincfg.MainNetParams)
+
+	tests := []struct {
+		name     string
+		pkScript []byte
+		redeem   []byte
+		complete bool
+	}{
+		{"p2pkh compressed", payToScript(t, compressedAddr), nil, true},
+		{"p2pkh uncompressed", payToScript(t, uncompressedAddr), nil, true},
+		{"p2pkh extended key", payToScript(t, hdAddr), nil, true},
+		{"p2pk", p2pk, nil, true},
+		{"bare multisig", multiSig, nil, true},
+		{"p2sh multisig", p2shScript(t, multiSig), multiSig, true},
+		{"p2sh32 multisig", p2sh32Script(multiSig), multiSig, true},
+		{"p2sh p2pkh", p2shScript(t, payToScript(t, compressedAddr)),
+			payToScript(t, compressedAddr), true},
+		{"unknown key", payToScript(t, unknownAddr), nil, false},
+		{"unknown redeem script", p2shScript(t, multiSig), nil, false},
+	}
+
+	for _, sigType := range []txsign.SignatureType{txsign.Schnorr, txsign.ECDSA} {
+		for _, test := range tests {
+			signer := txsign.NewSigner()
+			signer.SignatureType = sigType
+			signer.AddWIF(compressed)
+			signer.AddWIF(uncompressed)
+			signer.AddPrivKey(newKey(0x03), true)
+			signer.AddPrivKey(newKey(0x05), true)
+			if err := signer.AddExtendedKey(master); err != nil {
+				t.Fatalf("AddExtendedKey: unexpected error: %v", err)
+			}
+			if test.redeem != nil {
+				signer.AddRedeemScript(test.redeem)
+			}
+
+			prevOuts := []*wire.TxOut{wire.NewTxOut(100000,
+				test.pkScript, wire.TokenData{})}
+			tx := newSpendingTx(prevOuts)
+			complete, err := signer.Sign(tx, prevOuts)
+			if err != nil {
+				t.Errorf("%s (%v): unexpected error: %v", test.name,
+					sigType, err)
+				continue
+			}
+			if complete != test.complete {
+				t.Errorf("%s (%v): unexpected completion - got %v, "+
+					"want %v", test.name, sigType, complete,
+					test.complete)
+				continue
+			}
+
+			err = txsign.Verify(tx, prevOuts)
+			if test.complete && err != nil {
+				t.Errorf("%s (%v): signed transaction does not "+
+					"verify: %v", test.name, sigType, err)
+			}
+			if !test.complete && err == nil {
+				t.Errorf("%s (%v): unsigned transaction verifies",
+					test.name, sigType)
+			}
+		}
+	}
+}
+
+// TestPartialSign ensures multisig inputs can be signed by several parties in
+// turn, each adding signatures to those already present.
+func TestPartialSign(t *testing.T) {
+	keys := []*bchec.PrivateKey{newKey(0x01), newKey(0x02), newKey(0x03)}
+	redeemScript := multiSigScript(t, 2, keys...)
+
+	for _, sigType := range []txsign.SignatureType{txsign.Schnorr, txsign.ECDSA} {
+		prevOuts := []*wire.TxOut{
+			wire.NewTxOut(100000, p2shScript(t, redeemScript),
+				wire.TokenData{}),
+			wire.NewTxOut(200000, redeemScript, wire.TokenData{}),
+		}
+		tx := newSpendingTx(prevOuts)
+
+		// The first party knows the redeem script and signs with the
+		// last key.
+		first := txsign.NewSigner()
+		first.SignatureType = sigType
+		first.AddPrivKey(keys[2], true)
+		first.AddRedeemScript(redeemScript)
+		complete, err := first.Sign(tx, prevOuts)
+		if err != nil {
+			t.Fatalf("%v: first Sign: unexpected error: %v", sigType, err)
+		}
+		if complete {
+			t.Errorf("%v: transaction complete after one signature",
+				sigType)
+		}
+		if txsign.Verify(tx, prevOuts) == nil {
+			t.Errorf("%v: partially signed transaction verifies",
+				sigType)
+		}
+
+		// The second party learns the redeem script from the partially
+		// signed input and adds a signature for an earlier key.
+		second := txsign.NewSigner()
+		second.SignatureType = sigType
+		second.AddPrivKey(keys[0], true)
+		complete, err = second.Sign(tx, prevOuts)
+		if err != nil {
+			t.Fatalf("%v: second Sign: unexpected error: %v", sigType, err)
+		}
+		if !complete {
+			t.Errorf("%v: transaction incomplete after two signatures",
+				sigType)
+		}
+		if err := txsign.Verify(tx, prevOuts); err != nil {
+			t.Errorf("%v: signed transaction does not verify: %v",
+				sigType, err)
+		}
+	}
+}
+
+// TestSignOtherParty ensures inputs already signed by another party count
+// towards completion even when the signer does not hold their keys.
+func TestSignOtherParty(t *testing.T) {
+	keys := []*bchec.PrivateKey{newKey(0x01), newKey(0x02)}
+	prevOuts := make([]*wire.TxOut, len(keys))
+	for i, key := range keys {
+		addr, _ := bchutil.NewAddressPubKeyHash(bchutil.Hash160(
+			key.PubKey().SerializeCompressed()), &chaincfg.MainNetParams)
+		prevOuts[i] = wire.NewTxOut(100000, payToScript(t, addr),
+			wire.TokenData{})
+	}
+
+	for _, sigType := range []txsign.SignatureType{txsign.Schnorr, txsign.ECDSA} {
+		tx := newSpendingTx(prevOuts)
+		for i, key := range keys {
+			signer := txsign.NewSigner()
+			signer.SignatureType = sigType
+			signer.AddPrivKey(key, true)
+			complete, err := signer.Sign(tx, prevOuts)
+			if err != nil {
+				t.Fatalf("%v: Sign %d: unexpected error: %v", sigType,
+					i, err)
+			}
+			if want := i == len(keys)-1; complete != want {
+				t.Errorf("%v: Sign %d: unexpected completion - got "+
+					"%v, want %v", sigType, i, complete, want)
+			}
+		}
+		if err := txsign.Verify(tx, prevOuts); err != nil {
+			t.Errorf("%v: signed transaction does not verify: %v",
+				sigType, err)
+		}
+	}
+}
+
+// TestSignErrors ensures the signer reports the expected errors.
+func TestSignErrors(t *testing.T) {
+	keys := []*bchec.PrivateKey{newKey(0x01), newKey(0x02)}
+	multiSig := multiSigScript(t, 2, keys...)
+	prevOuts := []*wire.TxOut{wire.NewTxOut(100000, multiSig, wire.TokenData{})}
+	tx := newSpendingTx(prevOuts)
+
+	signer := txsign.NewSigner()
+	if _, err := signer.Sign(tx, nil); err != txsign.ErrPrevOutCount {
+		t.Errorf("unexpected error for missing previous outputs - got "+
+			"%v, want %v", err, txsign.ErrPrevOutCount)
+	}
+
+	// Schnorr signatures cannot be added to ECDSA multisig signatures.
+	ecdsaSigner := txsign.NewSigner()
+	ecdsaSigner.SignatureType = txsign.ECDSA
+	ecdsaSigner.AddPrivKey(keys[0], true)
+	if _, err := ecdsaSigner.Sign(tx, prevOuts); err != nil {
+		t.Fatalf("Sign: unexpected error: %v", err)
+	}
+	schnorrSigner := txsign.NewSigner()
+	schnorrSigner.AddPrivKey(keys[1], true)
+	_, err := schnorrSigner.Sign(tx, prevOuts)
+	if err == nil {
+		t.Errorf("expected error mixing signature types")
+	}
+
+	nonStandard := []*wire.TxOut{wire.NewTxOut(100000,
+		[]byte{txscript.OP_TRUE}, wire.TokenData{})}
+	if _, err := signer.Sign(newSpendingTx(nonStandard), nonStandard); err == nil {
+		t.Errorf("expected error signing non-standard script")
+	}
+}